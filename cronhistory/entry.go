@@ -0,0 +1,19 @@
+package cronhistory
+
+import (
+	"time"
+)
+
+// Execution is a cron job execution record of the [History].
+// The finish time and duration are not set while the execution is running, or if it was interrupted.
+type Execution struct {
+	Id          int64      `db:"id"`
+	JobName     string     `db:"job_name"`
+	ExecutionId string     `db:"execution_id"`
+	Status      string     `db:"status"`
+	StartedAt   time.Time  `db:"started_at"`
+	FinishedAt  *time.Time `db:"finished_at"`
+	DurationMs  *int64     `db:"duration_ms"`
+	Error       string     `db:"error"`
+	Hostname    string     `db:"hostname"`
+}
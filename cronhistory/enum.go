@@ -0,0 +1,36 @@
+package cronhistory
+
+import "strings"
+
+// Status is an enum for the cron job execution statuses recorded in the [History].
+type Status int
+
+const (
+	Running Status = iota
+	Succeeded
+	Failed
+)
+
+// String returns a string representation of a [Status].
+func (s Status) String() string {
+	switch s {
+	case Succeeded:
+		return "succeeded"
+	case Failed:
+		return "failed"
+	default:
+		return "running"
+	}
+}
+
+// FetchStatus returns a [Status] for a given value.
+func FetchStatus(s string) Status {
+	switch strings.ToLower(s) {
+	case "succeeded":
+		return Succeeded
+	case "failed":
+		return Failed
+	default:
+		return Running
+	}
+}
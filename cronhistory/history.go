@@ -0,0 +1,145 @@
+package cronhistory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/templatedop/ftptemplate/db"
+	"github.com/templatedop/ftptemplate/repo"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS %[1]s (
+	id           BIGSERIAL PRIMARY KEY,
+	job_name     TEXT NOT NULL,
+	execution_id TEXT NOT NULL UNIQUE,
+	status       TEXT NOT NULL,
+	started_at   TIMESTAMPTZ NOT NULL,
+	finished_at  TIMESTAMPTZ,
+	duration_ms  BIGINT,
+	error        TEXT NOT NULL DEFAULT '',
+	hostname     TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS %[1]s_job_name_idx ON %[1]s (job_name, started_at DESC);
+CREATE INDEX IF NOT EXISTS %[1]s_started_at_idx ON %[1]s (started_at);
+`
+
+var columns = []string{
+	"id", "job_name", "execution_id", "status", "started_at", "finished_at", "duration_ms", "error", "hostname",
+}
+
+// History records the cron jobs executions in a Postgres table, from their start to their outcome.
+//
+// Executions are recorded as running when starting, then as succeeded or failed when finishing:
+// executions interrupted by a crash are left as running. Executions older than the retention are pruned.
+type History struct {
+	db      *db.DB
+	options Options
+}
+
+// NewHistory returns a new [History], for a [db.DB] and a list of [HistoryOption].
+func NewHistory(db *db.DB, options ...HistoryOption) *History {
+	appliedOpts := DefaultHistoryOptions()
+	for _, opt := range options {
+		opt(&appliedOpts)
+	}
+
+	return &History{
+		db:      db,
+		options: appliedOpts,
+	}
+}
+
+// Table returns the history table name.
+func (h *History) Table() string {
+	return h.options.Table
+}
+
+// Retention returns the duration the executions are kept for.
+func (h *History) Retention() time.Duration {
+	return h.options.Retention
+}
+
+// Migrate creates the history table and its indexes if they do not exist.
+func (h *History) Migrate(ctx context.Context) error {
+	if _, err := h.db.Exec(ctx, fmt.Sprintf(schema, h.options.Table)); err != nil {
+		return fmt.Errorf("unable to migrate history table %s: %w", h.options.Table, err)
+	}
+
+	return nil
+}
+
+// Start records a running execution of a job.
+func (h *History) Start(ctx context.Context, jobName string, executionId string, startedAt time.Time) error {
+	query := repo.Psql.
+		Insert(h.options.Table).
+		Columns("job_name", "execution_id", "status", "started_at", "hostname").
+		Values(jobName, executionId, Running.String(), startedAt, h.options.Hostname)
+
+	if _, err := repo.Insert(ctx, h.db, query); err != nil {
+		return fmt.Errorf("unable to record start of execution %s of job %s: %w", executionId, jobName, err)
+	}
+
+	return nil
+}
+
+// Finish records the outcome of a started execution: succeeded without error, failed with its error message otherwise.
+func (h *History) Finish(ctx context.Context, executionId string, finishedAt time.Time, cause error) error {
+	status, message := Succeeded, ""
+	if cause != nil {
+		status, message = Failed, cause.Error()
+	}
+
+	query := repo.Psql.
+		Update(h.options.Table).
+		Set("status", status.String()).
+		Set("finished_at", finishedAt).
+		Set("duration_ms", sq.Expr("(EXTRACT(EPOCH FROM (?::timestamptz - started_at)) * 1000)::bigint", finishedAt)).
+		Set("error", message).
+		Where(sq.Eq{"execution_id": executionId})
+
+	tag, err := repo.Update(ctx, h.db, query)
+	if err != nil {
+		return fmt.Errorf("unable to record finish of execution %s: %w", executionId, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("execution %s is not recorded as started", executionId)
+	}
+
+	return nil
+}
+
+// Executions returns the executions of a job started since a given time, most recent first.
+func (h *History) Executions(ctx context.Context, jobName string, since time.Time) ([]Execution, error) {
+	query := repo.Psql.
+		Select(columns...).
+		From(h.options.Table).
+		Where(sq.Eq{"job_name": jobName}).
+		Where(sq.GtOrEq{"started_at": since}).
+		OrderBy("started_at DESC")
+
+	executions, _, err := repo.SelectRowsOK(ctx, h.db, query, pgx.RowToStructByName[Execution])
+	if err != nil {
+		return nil, fmt.Errorf("unable to select executions of job %s: %w", jobName, err)
+	}
+
+	return executions, nil
+}
+
+// Prune deletes the executions started before the retention, and returns the number of deleted executions.
+func (h *History) Prune(ctx context.Context) (int64, error) {
+	query := repo.Psql.
+		Delete(h.options.Table).
+		Where(sq.Lt{"started_at": time.Now().Add(-h.options.Retention)})
+
+	tag, err := repo.Delete(ctx, h.db, query)
+	if err != nil {
+		return 0, fmt.Errorf("unable to prune history table %s: %w", h.options.Table, err)
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,56 @@
+package cronhistory
+
+import (
+	"os"
+	"time"
+)
+
+const (
+	DefaultTable     = "cron_job_executions"
+	DefaultRetention = 30 * 24 * time.Hour
+)
+
+// Options are options for the [History].
+type Options struct {
+	Table     string
+	Hostname  string
+	Retention time.Duration
+}
+
+// DefaultHistoryOptions are the default options used in the [History].
+func DefaultHistoryOptions() Options {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	return Options{
+		Table:     DefaultTable,
+		Hostname:  hostname,
+		Retention: DefaultRetention,
+	}
+}
+
+// HistoryOption are functional options for the [History].
+type HistoryOption func(o *Options)
+
+// WithTable is used to specify the history table name.
+func WithTable(t string) HistoryOption {
+	return func(o *Options) {
+		o.Table = t
+	}
+}
+
+// WithHostname is used to specify the hostname recorded with the executions, the system one by default.
+func WithHostname(hostname string) HistoryOption {
+	return func(o *Options) {
+		o.Hostname = hostname
+	}
+}
+
+// WithRetention is used to specify the duration the executions are kept for, before being pruned.
+func WithRetention(d time.Duration) HistoryOption {
+	return func(o *Options) {
+		o.Retention = d
+	}
+}
@@ -0,0 +1,50 @@
+package cronlock
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-co-op/gocron/v2"
+)
+
+// LeaderLease is the name of the lease held by the leader instance.
+const LeaderLease = "leader"
+
+// ErrNotLeader is returned when the instance is not the leader, the jobs being run by another instance.
+var ErrNotLeader = errors.New("instance is not the cron leader")
+
+var _ gocron.Elector = (*Elector)(nil)
+
+// Elector is a [gocron.Elector] electing the instance holding the leader lease, to run all the jobs executions.
+//
+// The leader renews its lease at each job execution: another instance takes over once the lease expired,
+// when the leader stopped or did not run any job for the lease TTL.
+type Elector struct {
+	leases *Leases
+}
+
+// NewElector returns a new [Elector], for [Leases].
+func NewElector(leases *Leases) *Elector {
+	return &Elector{
+		leases: leases,
+	}
+}
+
+// IsLeader returns nil if the instance holds the leader lease, acquiring or renewing it, and [ErrNotLeader] otherwise.
+func (e *Elector) IsLeader(ctx context.Context) error {
+	acquired, err := e.leases.Acquire(ctx, LeaderLease)
+	if err != nil {
+		return err
+	}
+
+	if !acquired {
+		return ErrNotLeader
+	}
+
+	return nil
+}
+
+// Resign releases the leader lease, if held, to let another instance take over, like on shutdown.
+func (e *Elector) Resign(ctx context.Context) error {
+	return e.leases.Delete(ctx, LeaderLease)
+}
@@ -0,0 +1,139 @@
+package cronlock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/templatedop/ftptemplate/db"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS %[1]s (
+	name        TEXT PRIMARY KEY,
+	owner       TEXT NOT NULL,
+	acquired_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	expires_at  TIMESTAMPTZ NOT NULL
+);
+`
+
+// Leases records named leases in a Postgres table, each held by a single instance until released or expired.
+//
+// The lease times are computed by the database, so that the instances clocks do not need to be synchronized.
+type Leases struct {
+	db      *db.DB
+	options Options
+}
+
+// NewLeases returns a new [Leases], for a [db.DB] and a list of [LeasesOption].
+func NewLeases(db *db.DB, options ...LeasesOption) *Leases {
+	appliedOpts := DefaultLeasesOptions()
+	for _, opt := range options {
+		opt(&appliedOpts)
+	}
+
+	return &Leases{
+		db:      db,
+		options: appliedOpts,
+	}
+}
+
+// Table returns the leases table name.
+func (l *Leases) Table() string {
+	return l.options.Table
+}
+
+// Owner returns the instance identifier the leases are held by.
+func (l *Leases) Owner() string {
+	return l.options.Owner
+}
+
+// Migrate creates the leases table if it does not exist.
+func (l *Leases) Migrate(ctx context.Context) error {
+	if _, err := l.db.Exec(ctx, fmt.Sprintf(schema, l.options.Table)); err != nil {
+		return fmt.Errorf("unable to migrate leases table %s: %w", l.options.Table, err)
+	}
+
+	return nil
+}
+
+// Acquire acquires, or renews if already held by this instance, a lease for the TTL, and returns true if acquired.
+// It returns false if the lease is held by another instance and not expired.
+func (l *Leases) Acquire(ctx context.Context, name string) (bool, error) {
+	query := fmt.Sprintf(
+		`INSERT INTO %[1]s AS l (name, owner, acquired_at, expires_at) VALUES ($1, $2, now(), now() + make_interval(secs => $3))
+		ON CONFLICT (name) DO UPDATE
+		SET owner = EXCLUDED.owner, acquired_at = EXCLUDED.acquired_at, expires_at = EXCLUDED.expires_at
+		WHERE l.expires_at < now() OR l.owner = EXCLUDED.owner
+		RETURNING l.name`,
+		l.options.Table,
+	)
+
+	var acquired string
+	if err := l.db.QueryRow(ctx, query, name, l.options.Owner, l.options.TTL.Seconds()).Scan(&acquired); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("unable to acquire lease %s: %w", name, err)
+	}
+
+	return true, nil
+}
+
+// Renew extends a lease held by this instance for the TTL, and returns false if the lease was lost.
+func (l *Leases) Renew(ctx context.Context, name string) (bool, error) {
+	query := fmt.Sprintf(
+		`UPDATE %s SET expires_at = now() + make_interval(secs => $3) WHERE name = $1 AND owner = $2`,
+		l.options.Table,
+	)
+
+	tag, err := l.db.Exec(ctx, query, name, l.options.Owner, l.options.TTL.Seconds())
+	if err != nil {
+		return false, fmt.Errorf("unable to renew lease %s: %w", name, err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// Release releases a lease held by this instance, keeping it at least for the minimum hold duration since its acquisition.
+func (l *Leases) Release(ctx context.Context, name string) error {
+	query := fmt.Sprintf(
+		`UPDATE %s SET expires_at = GREATEST(now(), acquired_at + make_interval(secs => $3)) WHERE name = $1 AND owner = $2`,
+		l.options.Table,
+	)
+
+	if _, err := l.db.Exec(ctx, query, name, l.options.Owner, l.options.MinHold.Seconds()); err != nil {
+		return fmt.Errorf("unable to release lease %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// Delete deletes a lease held by this instance, to be acquired immediately by another instance.
+func (l *Leases) Delete(ctx context.Context, name string) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE name = $1 AND owner = $2`, l.options.Table)
+
+	if _, err := l.db.Exec(ctx, query, name, l.options.Owner); err != nil {
+		return fmt.Errorf("unable to delete lease %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// DefaultOwner returns a unique instance identifier, made of the hostname, the process id and a random suffix.
+func DefaultOwner() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+
+	return fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), hex.EncodeToString(suffix))
+}
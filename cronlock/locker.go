@@ -0,0 +1,92 @@
+package cronlock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-co-op/gocron/v2"
+)
+
+// JobLeasePrefix is the prefix of the job leases names, followed by the job name.
+const JobLeasePrefix = "job:"
+
+// ErrLocked is returned when a job is locked by another instance, which runs the execution.
+var ErrLocked = errors.New("cron job is locked by another instance")
+
+var _ gocron.Locker = (*Locker)(nil)
+
+// Locker is a [gocron.Locker] locking each job execution with a lease, so that it runs on a single instance.
+//
+// The lease is renewed in the background while the job runs, and kept for the minimum hold duration once released,
+// so that the instances triggering the same execution slightly later skip it.
+type Locker struct {
+	leases *Leases
+}
+
+// NewLocker returns a new [Locker], for [Leases].
+func NewLocker(leases *Leases) *Locker {
+	return &Locker{
+		leases: leases,
+	}
+}
+
+// Lock acquires the lease of a job, and returns [ErrLocked] if held by another instance.
+func (l *Locker) Lock(ctx context.Context, key string) (gocron.Lock, error) {
+	name := JobLeasePrefix + key
+
+	acquired, err := l.leases.Acquire(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if !acquired {
+		return nil, ErrLocked
+	}
+
+	lock := &lease{
+		leases: l.leases,
+		name:   name,
+		done:   make(chan struct{}),
+	}
+
+	go lock.renew(max(l.leases.options.TTL/3, time.Second))
+
+	return lock, nil
+}
+
+// lease is a [gocron.Lock] of an acquired job lease.
+type lease struct {
+	leases *Leases
+	name   string
+	done   chan struct{}
+	once   sync.Once
+}
+
+// Unlock stops the lease renewal and releases it.
+func (l *lease) Unlock(ctx context.Context) error {
+	l.once.Do(func() {
+		close(l.done)
+	})
+
+	// the job context may be already cancelled on shutdown
+	return l.leases.Release(context.WithoutCancel(ctx), l.name)
+}
+
+func (l *lease) renew(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.done:
+			return
+		case <-ticker.C:
+			// a lost lease cannot stop the running job, and is retried at the next tick
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			_, _ = l.leases.Renew(ctx, l.name)
+			cancel()
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package cronlock
+
+import "time"
+
+const (
+	DefaultTable   = "cron_leases"
+	DefaultTTL     = 30 * time.Second
+	DefaultMinHold = 10 * time.Second
+)
+
+// Options are options for the [Leases].
+type Options struct {
+	Table   string
+	Owner   string
+	TTL     time.Duration
+	MinHold time.Duration
+}
+
+// DefaultLeasesOptions are the default options used in the [Leases].
+func DefaultLeasesOptions() Options {
+	return Options{
+		Table:   DefaultTable,
+		Owner:   DefaultOwner(),
+		TTL:     DefaultTTL,
+		MinHold: DefaultMinHold,
+	}
+}
+
+// LeasesOption are functional options for the [Leases].
+type LeasesOption func(o *Options)
+
+// WithTable is used to specify the leases table name.
+func WithTable(t string) LeasesOption {
+	return func(o *Options) {
+		o.Table = t
+	}
+}
+
+// WithOwner is used to specify the instance identifier the leases are held by, unique per instance.
+func WithOwner(owner string) LeasesOption {
+	return func(o *Options) {
+		o.Owner = owner
+	}
+}
+
+// WithTTL is used to specify the duration of the leases, after which the leases of a crashed instance can be acquired again.
+// Held locks are renewed in the background, so the TTL does not limit the executions duration.
+func WithTTL(d time.Duration) LeasesOption {
+	return func(o *Options) {
+		o.TTL = d
+	}
+}
+
+// WithMinHold is used to specify the minimum duration a lock is held once released, covering the clock splay
+// between the instances schedulers, so that an instance triggering a job slightly later does not run it again.
+// It must be shorter than the jobs interval.
+func WithMinHold(d time.Duration) LeasesOption {
+	return func(o *Options) {
+		o.MinHold = d
+	}
+}
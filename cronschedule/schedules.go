@@ -0,0 +1,142 @@
+package cronschedule
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/templatedop/ftptemplate/db"
+	"github.com/templatedop/ftptemplate/repo"
+)
+
+const DefaultTable = "cron_job_schedules"
+
+const schema = `
+CREATE TABLE IF NOT EXISTS %[1]s (
+	name       TEXT PRIMARY KEY,
+	expression TEXT NOT NULL,
+	paused     BOOLEAN NOT NULL DEFAULT false,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+ALTER TABLE %[1]s ADD COLUMN IF NOT EXISTS paused BOOLEAN NOT NULL DEFAULT false;
+`
+
+// Schedule is a cron job expression, empty if only paused, and paused state persisted in the [Schedules].
+type Schedule struct {
+	Name       string `db:"name"`
+	Expression string `db:"expression"`
+	Paused     bool   `db:"paused"`
+}
+
+// Options are options for the [Schedules].
+type Options struct {
+	Table string
+}
+
+// DefaultSchedulesOptions are the default options used in the [Schedules].
+func DefaultSchedulesOptions() Options {
+	return Options{
+		Table: DefaultTable,
+	}
+}
+
+// SchedulesOption are functional options for the [Schedules].
+type SchedulesOption func(o *Options)
+
+// WithTable is used to specify the schedules table name.
+func WithTable(t string) SchedulesOption {
+	return func(o *Options) {
+		o.Table = t
+	}
+}
+
+// Schedules persists the cron jobs expressions and paused states changed at runtime in a Postgres table, by job name.
+type Schedules struct {
+	db      *db.DB
+	options Options
+}
+
+// NewSchedules returns new [Schedules], for a [db.DB] and a list of [SchedulesOption].
+func NewSchedules(db *db.DB, options ...SchedulesOption) *Schedules {
+	appliedOpts := DefaultSchedulesOptions()
+	for _, opt := range options {
+		opt(&appliedOpts)
+	}
+
+	return &Schedules{
+		db:      db,
+		options: appliedOpts,
+	}
+}
+
+// Table returns the schedules table name.
+func (s *Schedules) Table() string {
+	return s.options.Table
+}
+
+// Migrate creates the schedules table if it does not exist.
+func (s *Schedules) Migrate(ctx context.Context) error {
+	if _, err := s.db.Exec(ctx, fmt.Sprintf(schema, s.options.Table)); err != nil {
+		return fmt.Errorf("unable to migrate schedules table %s: %w", s.options.Table, err)
+	}
+
+	return nil
+}
+
+// All returns the persisted schedules.
+func (s *Schedules) All(ctx context.Context) ([]Schedule, error) {
+	query := repo.Psql.
+		Select("name", "expression", "paused").
+		From(s.options.Table)
+
+	schedules, _, err := repo.SelectRowsOK(ctx, s.db, query, pgx.RowToStructByName[Schedule])
+	if err != nil {
+		return nil, fmt.Errorf("unable to select schedules: %w", err)
+	}
+
+	return schedules, nil
+}
+
+// Save persists the expression of a job, replacing the previous one.
+func (s *Schedules) Save(ctx context.Context, name string, expression string) error {
+	query := repo.Psql.
+		Insert(s.options.Table).
+		Columns("name", "expression").
+		Values(name, expression).
+		Suffix("ON CONFLICT (name) DO UPDATE SET expression = EXCLUDED.expression, updated_at = now()")
+
+	if _, err := repo.Insert(ctx, s.db, query); err != nil {
+		return fmt.Errorf("unable to save schedule of job %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// SavePaused persists the paused state of a job, keeping its expression.
+func (s *Schedules) SavePaused(ctx context.Context, name string, paused bool) error {
+	query := repo.Psql.
+		Insert(s.options.Table).
+		Columns("name", "expression", "paused").
+		Values(name, "", paused).
+		Suffix("ON CONFLICT (name) DO UPDATE SET paused = EXCLUDED.paused, updated_at = now()")
+
+	if _, err := repo.Insert(ctx, s.db, query); err != nil {
+		return fmt.Errorf("unable to save paused state of job %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// Delete deletes the persisted expression of a job, the registered one applying again on the next start.
+func (s *Schedules) Delete(ctx context.Context, name string) error {
+	query := repo.Psql.
+		Delete(s.options.Table).
+		Where(sq.Eq{"name": name})
+
+	if _, err := repo.Delete(ctx, s.db, query); err != nil {
+		return fmt.Errorf("unable to delete schedule of job %s: %w", name, err)
+	}
+
+	return nil
+}
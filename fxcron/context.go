@@ -13,6 +13,9 @@ type CtxCronJobNameKey struct{}
 // CtxCronJobExecutionIdKey is a contextual struct key.
 type CtxCronJobExecutionIdKey struct{}
 
+// CtxCronJobAttemptKey is a contextual struct key.
+type CtxCronJobAttemptKey struct{}
+
 // CtxCronJobName returns the contextual cron job name.
 func CtxCronJobName(ctx context.Context) string {
 	if name, ok := ctx.Value(CtxCronJobNameKey{}).(string); ok {
@@ -31,6 +34,15 @@ func CtxCronJobExecutionId(ctx context.Context) string {
 	}
 }
 
+// CtxCronJobAttempt returns the contextual cron job execution attempt, starting at 1.
+func CtxCronJobAttempt(ctx context.Context) int {
+	if attempt, ok := ctx.Value(CtxCronJobAttemptKey{}).(int); ok {
+		return attempt
+	} else {
+		return 0
+	}
+}
+
 // CtxLogger returns the contextual logger.
 func CtxLogger(ctx context.Context) *log.Logger {
 	return log.CtxLogger(ctx)
@@ -9,20 +9,27 @@ type CronJobDefinition interface {
 	ReturnType() string
 	Expression() string
 	Options() []gocron.JobOption
+	ExecutionOptions() []CronJobOption
 }
 
 type cronJobDefinition struct {
-	returnType string
-	expression string
-	options    []gocron.JobOption
+	returnType       string
+	expression       string
+	options          []gocron.JobOption
+	executionOptions []CronJobOption
 }
 
 // NewCronJobDefinition returns a new [CronJobDefinition].
 func NewCronJobDefinition(returnType string, expression string, options ...gocron.JobOption) CronJobDefinition {
+	return newCronJobDefinition(returnType, expression, options, nil)
+}
+
+func newCronJobDefinition(returnType string, expression string, options []gocron.JobOption, executionOptions []CronJobOption) CronJobDefinition {
 	return &cronJobDefinition{
-		returnType: returnType,
-		expression: expression,
-		options:    options,
+		returnType:       returnType,
+		expression:       expression,
+		options:          options,
+		executionOptions: executionOptions,
 	}
 }
 
@@ -40,3 +47,8 @@ func (c *cronJobDefinition) Expression() string {
 func (c *cronJobDefinition) Options() []gocron.JobOption {
 	return c.options
 }
+
+// ExecutionOptions returns the definition cron job execution options.
+func (c *cronJobDefinition) ExecutionOptions() []CronJobOption {
+	return c.executionOptions
+}
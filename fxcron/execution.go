@@ -0,0 +1,23 @@
+package fxcron
+
+import (
+	"context"
+	"time"
+)
+
+// CronJobExecution is an execution of a [CronJob], as recorded by a [CronJobExecutionRecorder].
+// The finish time and error are only set once the execution finished.
+type CronJobExecution struct {
+	JobName     string
+	ExecutionId string
+	StartedAt   time.Time
+	FinishedAt  time.Time
+	Err         error
+}
+
+// CronJobExecutionRecorder records the executions of the [CronJob] when they start and finish, like in a database.
+// Recording errors are logged without failing the executions.
+type CronJobExecutionRecorder interface {
+	Start(ctx context.Context, execution CronJobExecution) error
+	Finish(ctx context.Context, execution CronJobExecution) error
+}
@@ -4,6 +4,7 @@ go 1.22.1
 
 require (
 	github.com/go-co-op/gocron/v2 v2.11.0
+	github.com/robfig/cron/v3 v3.0.1
 	github.com/templatedop/ftptemplate/config v0.0.1
 	github.com/templatedop/ftptemplate/generate v0.0.1
 	github.com/templatedop/ftptemplate/log v0.0.1
@@ -20,7 +21,6 @@ require (
 	github.com/mattn/go-isatty v0.0.19 // indirect
 	github.com/mitchellh/mapstructure v1.5.0 // indirect
 	github.com/pelletier/go-toml/v2 v2.2.2 // indirect
-	github.com/robfig/cron/v3 v3.0.1 // indirect
 	github.com/rs/zerolog v1.33.0 // indirect
 	github.com/sagikazarmark/locafero v0.4.0 // indirect
 	github.com/sagikazarmark/slog-shim v0.1.0 // indirect
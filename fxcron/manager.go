@@ -0,0 +1,372 @@
+package fxcron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-co-op/gocron/v2"
+	"github.com/robfig/cron/v3"
+)
+
+var (
+	// ErrCronJobNotFound is returned when no scheduled cron job has the requested name.
+	ErrCronJobNotFound = errors.New("cron job not found")
+	// ErrCronJobPaused is returned when triggering a paused cron job.
+	ErrCronJobPaused = errors.New("cron job is paused")
+	// ErrCronJobScheduleStoreMissing is returned when persisting a schedule without [CronJobScheduleStore].
+	ErrCronJobScheduleStoreMissing = errors.New("cron job schedules persistence is not enabled")
+)
+
+// CronJobSchedule is the persisted state of a cron job: its expression, empty if not rescheduled, and if it is paused.
+type CronJobSchedule struct {
+	Expression string
+	Paused     bool
+}
+
+// CronJobScheduleStore persists the cron jobs expressions and paused states changed at runtime, shared by all the
+// application instances: they are restored over the registered ones on start, and synchronized while running.
+type CronJobScheduleStore interface {
+	Schedules(ctx context.Context) (map[string]CronJobSchedule, error)
+	SaveSchedule(ctx context.Context, name string, expression string) error
+	SavePaused(ctx context.Context, name string, paused bool) error
+}
+
+// CronJobStatus is the status of a scheduled cron job, as returned by the [CronJobManager].
+type CronJobStatus struct {
+	Name       string     `json:"name"`
+	Type       string     `json:"type"`
+	Expression string     `json:"expression"`
+	Registered string     `json:"registered_expression"`
+	Paused     bool       `json:"paused"`
+	LastRun    *time.Time `json:"last_run"`
+	NextRun    *time.Time `json:"next_run"`
+}
+
+// CronJobManager manages the cron jobs scheduled by [NewFxCron] at runtime: triggering, pausing, resuming and rescheduling them.
+// Without [CronJobScheduleStore], the paused states and the non persisted expressions only apply to the current
+// application instance.
+type CronJobManager struct {
+	scheduler gocron.Scheduler
+	store     CronJobScheduleStore
+	seconds   bool
+	mutex     sync.RWMutex
+	jobs      map[string]*managedCronJob
+}
+
+// managedCronJob is a cron job scheduled by [NewFxCron], with what is needed to reschedule it.
+type managedCronJob struct {
+	implementation CronJob
+	job            gocron.Job
+	task           gocron.Task
+	options        []gocron.JobOption
+	registered     string
+	expression     string
+	persisted      CronJobSchedule
+	paused         atomic.Bool
+}
+
+// NewFxCronJobManager returns a new [CronJobManager], populated by [NewFxCron].
+func NewFxCronJobManager() *CronJobManager {
+	return &CronJobManager{
+		jobs: map[string]*managedCronJob{},
+	}
+}
+
+// Jobs returns the status of the scheduled cron jobs, sorted by name.
+func (m *CronJobManager) Jobs() []CronJobStatus {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	statuses := make([]CronJobStatus, 0, len(m.jobs))
+	for name := range m.jobs {
+		statuses = append(statuses, m.status(name))
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+
+	return statuses
+}
+
+// Job returns the status of a scheduled cron job by name.
+func (m *CronJobManager) Job(name string) (CronJobStatus, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if _, ok := m.jobs[name]; !ok {
+		return CronJobStatus{}, fmt.Errorf("%w: %s", ErrCronJobNotFound, name)
+	}
+
+	return m.status(name), nil
+}
+
+// RunNow triggers an execution of a cron job immediately, in addition to its scheduled ones.
+func (m *CronJobManager) RunNow(name string) error {
+	managed, err := m.lookup(name)
+	if err != nil {
+		return err
+	}
+
+	if managed.paused.Load() {
+		return fmt.Errorf("%w: %s", ErrCronJobPaused, name)
+	}
+
+	m.mutex.RLock()
+	job := managed.job
+	m.mutex.RUnlock()
+
+	return job.RunNow()
+}
+
+// Pause pauses a cron job: its executions are skipped until resumed, while it stays scheduled.
+// The paused state is persisted with the [CronJobScheduleStore] if any, to pause the job on all the instances.
+func (m *CronJobManager) Pause(ctx context.Context, name string) error {
+	return m.setPaused(ctx, name, true)
+}
+
+// Resume resumes a paused cron job, on all the instances if there is a [CronJobScheduleStore].
+func (m *CronJobManager) Resume(ctx context.Context, name string) error {
+	return m.setPaused(ctx, name, false)
+}
+
+// Paused returns true if a cron job is paused.
+func (m *CronJobManager) Paused(name string) bool {
+	managed, err := m.lookup(name)
+
+	return err == nil && managed.paused.Load()
+}
+
+// Reschedule changes the expression of a cron job, and persists it with the [CronJobScheduleStore] if required,
+// before rescheduling the job: the persisted expression is restored if the job cannot be rescheduled.
+// The job is scheduled again as a new job, the global jobs options applying: like an immediate execution when
+// modules.cron.jobs.execution.start.immediately is enabled.
+func (m *CronJobManager) Reschedule(ctx context.Context, name string, expression string, persist bool) error {
+	if persist && m.store == nil {
+		return ErrCronJobScheduleStoreMissing
+	}
+
+	managed, err := m.lookup(name)
+	if err != nil {
+		return err
+	}
+
+	if err = m.validate(expression); err != nil {
+		return fmt.Errorf("invalid expression %s for cron job %s: %w", expression, name, err)
+	}
+
+	if !persist {
+		return m.update(name, expression)
+	}
+
+	m.mutex.RLock()
+	previous := managed.persisted.Expression
+	m.mutex.RUnlock()
+
+	if err = m.store.SaveSchedule(ctx, name, expression); err != nil {
+		return fmt.Errorf("unable to persist schedule of cron job %s: %w", name, err)
+	}
+
+	if err = m.update(name, expression); err != nil {
+		if restoreErr := m.store.SaveSchedule(context.WithoutCancel(ctx), name, previous); restoreErr != nil {
+			err = errors.Join(err, fmt.Errorf("unable to restore persisted schedule of cron job %s: %w", name, restoreErr))
+		}
+
+		return err
+	}
+
+	m.mutex.Lock()
+	managed.persisted.Expression = expression
+	m.mutex.Unlock()
+
+	return nil
+}
+
+// sync applies the expressions and paused states changed in the [CronJobScheduleStore] since the last synchronization,
+// on start before the scheduler starts, and then periodically to follow the changes made on the other instances.
+// It returns the names of the jobs it changed.
+func (m *CronJobManager) sync(ctx context.Context) ([]string, error) {
+	if m.store == nil {
+		return nil, nil
+	}
+
+	schedules, err := m.store.Schedules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load cron jobs schedules: %w", err)
+	}
+
+	var synced []string
+	for name, schedule := range schedules {
+		managed, err := m.lookup(name)
+		if err != nil {
+			// jobs no longer registered are ignored
+			continue
+		}
+
+		m.mutex.RLock()
+		persisted, expression := managed.persisted, managed.expression
+		m.mutex.RUnlock()
+
+		if schedule == persisted {
+			continue
+		}
+
+		if schedule.Expression != "" && schedule.Expression != expression {
+			if err = m.update(name, schedule.Expression); err != nil {
+				return synced, err
+			}
+		}
+
+		managed.paused.Store(schedule.Paused)
+
+		m.mutex.Lock()
+		managed.persisted = schedule
+		m.mutex.Unlock()
+
+		synced = append(synced, name)
+	}
+
+	sort.Strings(synced)
+
+	return synced, nil
+}
+
+// setPaused pauses or resumes a cron job, persisting its paused state first if there is a [CronJobScheduleStore].
+func (m *CronJobManager) setPaused(ctx context.Context, name string, paused bool) error {
+	managed, err := m.lookup(name)
+	if err != nil {
+		return err
+	}
+
+	if m.store != nil {
+		if err = m.store.SavePaused(ctx, name, paused); err != nil {
+			return fmt.Errorf("unable to persist paused state of cron job %s: %w", name, err)
+		}
+
+		m.mutex.Lock()
+		managed.persisted.Paused = paused
+		m.mutex.Unlock()
+	}
+
+	managed.paused.Store(paused)
+
+	return nil
+}
+
+// register adds a scheduled cron job to the [CronJobManager].
+func (m *CronJobManager) register(implementation CronJob, job gocron.Job, task gocron.Task, options []gocron.JobOption, expression string) *managedCronJob {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	managed := &managedCronJob{
+		implementation: implementation,
+		job:            job,
+		task:           task,
+		options:        options,
+		registered:     expression,
+		expression:     expression,
+	}
+
+	m.jobs[implementation.Name()] = managed
+
+	return managed
+}
+
+func (m *CronJobManager) update(name string, expression string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	managed, ok := m.jobs[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrCronJobNotFound, name)
+	}
+
+	// the scheduler removes the job before parsing the new expression, which must be valid
+	if err := m.validate(expression); err != nil {
+		return fmt.Errorf("invalid expression %s for cron job %s: %w", expression, name, err)
+	}
+
+	job, err := m.scheduler.Update(managed.job.ID(), gocron.CronJob(expression, m.seconds), managed.task, managed.options...)
+	if err != nil {
+		return fmt.Errorf("unable to reschedule cron job %s with %s: %w", name, expression, err)
+	}
+
+	managed.job = job
+	managed.expression = expression
+
+	return nil
+}
+
+// validate parses an expression as the scheduler does, with or without seconds.
+func (m *CronJobManager) validate(expression string) error {
+	var err error
+	if m.seconds {
+		_, err = cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor).Parse(expression)
+	} else {
+		_, err = cron.ParseStandard(expression)
+	}
+
+	if err != nil {
+		return errors.Join(gocron.ErrCronJobParse, err)
+	}
+
+	return nil
+}
+
+// nextRun returns the next scheduled run of a cron job, zero if unknown.
+func (m *CronJobManager) nextRun(name string) time.Time {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	managed, ok := m.jobs[name]
+	if !ok {
+		return time.Time{}
+	}
+
+	run, err := managed.job.NextRun()
+	if err != nil {
+		return time.Time{}
+	}
+
+	return run
+}
+
+func (m *CronJobManager) lookup(name string) (*managedCronJob, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	managed, ok := m.jobs[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrCronJobNotFound, name)
+	}
+
+	return managed, nil
+}
+
+func (m *CronJobManager) status(name string) CronJobStatus {
+	managed := m.jobs[name]
+
+	status := CronJobStatus{
+		Name:       name,
+		Type:       reflect.ValueOf(managed.implementation).Type().String(),
+		Expression: managed.expression,
+		Registered: managed.registered,
+		Paused:     managed.paused.Load(),
+	}
+
+	if run, err := managed.job.LastRun(); err == nil && !run.IsZero() {
+		status.LastRun = &run
+	}
+
+	if run, err := managed.job.NextRun(); err == nil && !run.IsZero() {
+		status.NextRun = &run
+	}
+
+	return status
+}
@@ -2,6 +2,8 @@ package fxcron
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	
 	"time"
 
@@ -16,6 +18,8 @@ const (
 	ModuleName                           = "cron"
 	LogRecordFieldCronJobName            = "cronJob"
 	LogRecordFieldCronJobExecutionId     = "cronJobExecutionID"
+	LogRecordFieldCronJobAttempt         = "cronJobAttempt"
+	DefaultRetryBackoff                  = time.Second
 	TraceSpanAttributeCronJobName        = "CronJob"
 	TraceSpanAttributeCronJobExecutionId = "CronJobExecutionID"
 )
@@ -26,6 +30,7 @@ var FxCronModule = fx.Module(
 	fx.Provide(
 		NewDefaultCronSchedulerFactory,
 		NewFxCronJobRegistry,
+		NewFxCronJobManager,
 		NewFxCron,
 		fx.Annotate(
 			NewFxCronModuleInfo,
@@ -44,8 +49,12 @@ type FxCronParam struct {
 	Factory         CronSchedulerFactory
 	Config          *config.Config
 	Registry        *CronJobRegistry
+	Manager         *CronJobManager
 	Logger          *log.Logger
-	
+	Elector         gocron.Elector `optional:"true"`
+	Locker          gocron.Locker  `optional:"true"`
+	Recorder        CronJobExecutionRecorder `optional:"true"`
+	Store           CronJobScheduleStore     `optional:"true"`
 }
 
 // NewFxCron returns a new [gocron.Scheduler].
@@ -67,6 +76,18 @@ func NewFxCron(p FxCronParam) (gocron.Scheduler, error) {
 		return nil, err
 	}
 
+	// distributed executions
+	if p.Config.GetBool("modules.cron.scheduler.distributed.enabled") {
+		distributedOption, err := buildDistributedOption(p.Config, p.Elector, p.Locker)
+		if err != nil {
+			p.Logger.Error().Err(err).Msg("cron scheduler distributed option creation error")
+
+			return nil, err
+		}
+
+		cronSchedulerOptions = append(cronSchedulerOptions, distributedOption)
+	}
+
 	cronScheduler, err := p.Factory.Create(cronSchedulerOptions...)
 	if err != nil {
 		p.Logger.Error().Err(err).Msg("cron scheduler creation error")
@@ -74,10 +95,78 @@ func NewFxCron(p FxCronParam) (gocron.Scheduler, error) {
 		return nil, err
 	}
 
+	// jobs manager, with the schedules changed at runtime persisted if enabled, and synchronized every 30s by default
+	p.Manager.scheduler = cronScheduler
+	p.Manager.seconds = p.Config.GetBool("modules.cron.scheduler.seconds")
+
+	var cronJobsSyncInterval time.Duration
+	if p.Config.GetBool("modules.cron.admin.persist.enabled") {
+		if p.Store == nil {
+			err = fmt.Errorf("missing cron jobs schedule store, provided by the fxcronadmin module")
+			p.Logger.Error().Err(err).Msg("cron jobs manager creation error")
+
+			return nil, err
+		}
+
+		p.Manager.store = p.Store
+
+		cronJobsSyncInterval = 30 * time.Second
+		if cfgSyncInterval := p.Config.GetString("modules.cron.admin.persist.sync_interval"); cfgSyncInterval != "" {
+			cronJobsSyncInterval, err = time.ParseDuration(cfgSyncInterval)
+			if err != nil {
+				p.Logger.Error().Err(err).Msg("cron jobs schedules sync interval parsing error")
+
+				return nil, err
+			}
+		}
+	}
+
 	// jobs logs
 	cronJobLogExecution := p.Config.GetBool("modules.cron.log.enabled") || appDebug
 	cronJobLogExclusions := p.Config.GetStringSlice("modules.cron.log.exclude")
 
+	// jobs executions history
+	var cronJobRecorder CronJobExecutionRecorder
+	if p.Config.GetBool("modules.cron.history.enabled") {
+		if p.Recorder == nil {
+			err = fmt.Errorf("missing cron jobs executions recorder, provided by the fxcronhistory module")
+			p.Logger.Error().Err(err).Msg("cron jobs history creation error")
+
+			return nil, err
+		}
+
+		cronJobRecorder = p.Recorder
+	}
+
+	// jobs executions timeout, none by default
+	var cronJobTimeout time.Duration
+	if cfgTimeout := p.Config.GetString("modules.cron.jobs.execution.timeout"); cfgTimeout != "" {
+		cronJobTimeout, err = time.ParseDuration(cfgTimeout)
+		if err != nil || cronJobTimeout < 0 {
+			err = fmt.Errorf("invalid modules.cron.jobs.execution.timeout: %s", cfgTimeout)
+			p.Logger.Error().Err(err).Msg("cron jobs timeout creation error")
+
+			return nil, err
+		}
+	}
+
+	// jobs executions retries, none by default
+	var cronJobRetryMax int
+	cronJobRetryBackoff := DefaultRetryBackoff
+	if p.Config.GetBool("modules.cron.jobs.execution.retry.enabled") {
+		cronJobRetryMax = p.Config.GetInt("modules.cron.jobs.execution.retry.max")
+
+		if cfgBackoff := p.Config.GetString("modules.cron.jobs.execution.retry.backoff"); cfgBackoff != "" {
+			cronJobRetryBackoff, err = time.ParseDuration(cfgBackoff)
+			if err != nil || cronJobRetryBackoff < 0 {
+				err = fmt.Errorf("invalid modules.cron.jobs.execution.retry.backoff: %s", cfgBackoff)
+				p.Logger.Error().Err(err).Msg("cron jobs retry creation error")
+
+				return nil, err
+			}
+		}
+	}
+
 	
 	// jobs registration
 	cronJobs, err := p.Registry.ResolveCronJobs()
@@ -87,6 +176,9 @@ func NewFxCron(p FxCronParam) (gocron.Scheduler, error) {
 		return nil, err
 	}
 
+	// jobs executions context, cancelled on stop for the running executions to abort
+	cronJobsCtx, cronJobsCancel := context.WithCancel(context.Background())
+
 	for _, cronJob := range cronJobs {
 		// var scoping
 		currentCronJob := cronJob
@@ -94,78 +186,190 @@ func NewFxCron(p FxCronParam) (gocron.Scheduler, error) {
 		currentCronJobName := currentCronJob.Implementation().Name()
 		currentJobOptions := append(currentCronJob.Options(), gocron.WithName(currentCronJobName))
 		currentCronJobLogExecution := !Contains(cronJobLogExclusions, currentCronJobName)
-		
 
-		_, err = cronScheduler.NewJob(
-			gocron.CronJob(
-				currentCronJob.Expression(),
-				p.Config.GetBool("modules.cron.scheduler.seconds"),
-			),
-			gocron.NewTask(
-				func() {
-					currentCronJobExecutionId := p.Generator.Generate()
+		currentCronJobOptions := CronJobOptions{
+			Timeout:      cronJobTimeout,
+			RetryMax:     cronJobRetryMax,
+			RetryBackoff: cronJobRetryBackoff,
+		}
+		for _, opt := range currentCronJob.ExecutionOptions() {
+			opt(&currentCronJobOptions)
+		}
+
+		currentCronJobTask := gocron.NewTask(
+			func() {
+				if p.Manager.Paused(currentCronJobName) {
+					cronLogger.Debug().Str(LogRecordFieldCronJobName, currentCronJobName).Msg("job execution skipped, job is paused")
+
+					return
+				}
+
+				currentCronJobExecutionId := p.Generator.Generate()
+
+				currentCronJobCtx := context.WithValue(cronJobsCtx, CtxCronJobNameKey{}, currentCronJobName)
+				currentCronJobCtx = context.WithValue(currentCronJobCtx, CtxCronJobExecutionIdKey{}, currentCronJobExecutionId)
+				
 
-					currentCronJobCtx := context.WithValue(context.Background(), CtxCronJobNameKey{}, currentCronJobName)
-					currentCronJobCtx = context.WithValue(currentCronJobCtx, CtxCronJobExecutionIdKey{}, currentCronJobExecutionId)
-					
+				
 
-					
+				currentCronJobLogger := log.FromZerolog(
+					cronLogger.
+						ToZerolog().
+						With().
+						Str(LogRecordFieldCronJobName, currentCronJobName).
+						Str(LogRecordFieldCronJobExecutionId, currentCronJobExecutionId).
+						Logger(),
+				)
 
-					currentCronJobLogger := log.FromZerolog(
-						cronLogger.
+				currentCronJobCtx = currentCronJobLogger.WithContext(currentCronJobCtx)
+
+				
+
+				currentCronJobExecution := CronJobExecution{
+					JobName:     currentCronJobName,
+					ExecutionId: currentCronJobExecutionId,
+					StartedAt:   time.Now(),
+				}
+
+				if cronJobRecorder != nil {
+					if recordErr := cronJobRecorder.Start(currentCronJobCtx, currentCronJobExecution); recordErr != nil {
+						currentCronJobLogger.Error().Err(recordErr).Msg("job execution start recording error")
+					}
+				}
+
+				// failed attempts are retried within the scheduled slot, sharing the execution id
+				var runErr error
+				for currentCronJobAttempt := 1; ; currentCronJobAttempt++ {
+					currentCronJobAttemptLogger := log.FromZerolog(
+						currentCronJobLogger.
 							ToZerolog().
 							With().
-							Str(LogRecordFieldCronJobName, currentCronJobName).
-							Str(LogRecordFieldCronJobExecutionId, currentCronJobExecutionId).
+							Int(LogRecordFieldCronJobAttempt, currentCronJobAttempt).
 							Logger(),
 					)
 
-					currentCronJobCtx = currentCronJobLogger.WithContext(currentCronJobCtx)
-
-					
+					currentCronJobAttemptCtx := context.WithValue(currentCronJobCtx, CtxCronJobAttemptKey{}, currentCronJobAttempt)
+					currentCronJobAttemptCtx = currentCronJobAttemptLogger.WithContext(currentCronJobAttemptCtx)
 
 					if cronJobLogExecution && currentCronJobLogExecution {
-						currentCronJobLogger.Info().Msg("job execution start")
+						currentCronJobAttemptLogger.Info().Msg("job execution start")
 					}
 
-					runErr := currentCronJob.Implementation().Run(currentCronJobCtx)
+					// the timeout applies to each attempt
+					currentCronJobRunCtx, currentCronJobRunCancel := currentCronJobAttemptCtx, context.CancelFunc(func() {})
+					if currentCronJobOptions.Timeout > 0 {
+						currentCronJobRunCtx, currentCronJobRunCancel = context.WithTimeout(currentCronJobAttemptCtx, currentCronJobOptions.Timeout)
+					}
+
+					runErr = currentCronJob.Implementation().Run(currentCronJobRunCtx)
+					currentCronJobRunCtxErr := currentCronJobRunCtx.Err()
+					currentCronJobRunCancel()
+
+					if runErr == nil {
+						break
+					}
 
-					if runErr != nil {
-						
-						currentCronJobLogger.Error().Err(runErr).Msg("job execution error")
-					} else {
-						
+					switch {
+					case errors.Is(currentCronJobRunCtxErr, context.DeadlineExceeded):
+						currentCronJobAttemptLogger.Error().Err(runErr).Msgf("job execution timeout after %s", currentCronJobOptions.Timeout)
+					case errors.Is(currentCronJobRunCtxErr, context.Canceled):
+						currentCronJobAttemptLogger.Warn().Err(runErr).Msg("job execution cancelled on stop")
+					default:
+						currentCronJobAttemptLogger.Error().Err(runErr).Msg("job execution error")
+					}
 
-						if cronJobLogExecution && currentCronJobLogExecution {
-							currentCronJobLogger.Info().Msg("job execution success")
-						}
+					delay, retry := currentCronJobOptions.retryDelay(currentCronJobAttempt)
+					if !retry || cronJobsCtx.Err() != nil || p.Manager.Paused(currentCronJobName) {
+						break
 					}
-				},
+
+					if nextRun := p.Manager.nextRun(currentCronJobName); !nextRun.IsZero() && !time.Now().Add(delay).Before(nextRun) {
+						currentCronJobAttemptLogger.Warn().Msgf("job execution not retried, next run scheduled at %s", nextRun.Format(time.RFC3339))
+
+						break
+					}
+
+					currentCronJobAttemptLogger.Warn().Msgf("job execution retry in %s", delay)
+
+					retryTimer := time.NewTimer(delay)
+					select {
+					case <-cronJobsCtx.Done():
+						retryTimer.Stop()
+					case <-retryTimer.C:
+					}
+
+					if cronJobsCtx.Err() != nil {
+						break
+					}
+				}
+
+				if cronJobRecorder != nil {
+					currentCronJobExecution.FinishedAt = time.Now()
+					currentCronJobExecution.Err = runErr
+
+					// recorded even if the execution timed out or was cancelled on stop
+					if recordErr := cronJobRecorder.Finish(context.WithoutCancel(currentCronJobCtx), currentCronJobExecution); recordErr != nil {
+						currentCronJobLogger.Error().Err(recordErr).Msg("job execution finish recording error")
+					}
+				}
+
+				if runErr == nil && cronJobLogExecution && currentCronJobLogExecution {
+					currentCronJobLogger.Info().Msg("job execution success")
+				}
+			},
+		)
+
+		currentScheduledJob, err := cronScheduler.NewJob(
+			gocron.CronJob(
+				currentCronJob.Expression(),
+				p.Config.GetBool("modules.cron.scheduler.seconds"),
 			),
+			currentCronJobTask,
 			currentJobOptions...,
 		)
 
 		if err != nil {
 			cronLogger.Error().Err(err).Msgf("job registration error for job %s with %s", currentCronJobName, currentCronJob.Expression())
+			cronJobsCancel()
 
 			return nil, err
 		} else {
 			cronLogger.Debug().Msgf("job registration success for job %s with %s", currentCronJobName, currentCronJob.Expression())
 		}
+
+		p.Manager.register(currentCronJob.Implementation(), currentScheduledJob, currentCronJobTask, currentJobOptions, currentCronJob.Expression())
 	}
 
 	// lifecycles
 	p.LifeCycle.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
+			restored, err := p.Manager.sync(ctx)
+			if err != nil {
+				cronLogger.Error().Err(err).Msg("cron jobs schedules restoration error")
+
+				return err
+			}
+
+			for _, name := range restored {
+				cronLogger.Info().Msgf("restored persisted schedule for job %s", name)
+			}
+
 			cronLogger.Debug().Msg("starting cron scheduler")
 
 			cronScheduler.Start()
 
+			if cronJobsSyncInterval > 0 {
+				go syncCronJobs(cronJobsCtx, p.Manager, cronJobsSyncInterval, cronLogger)
+			}
+
 			return nil
 		},
 		OnStop: func(ctx context.Context) error {
 			cronLogger.Debug().Msg("stopping cron scheduler")
 
+			// running executions are cancelled, the scheduler waiting for them up to modules.cron.scheduler.stop.timeout
+			cronJobsCancel()
+
 			return cronScheduler.Shutdown()
 		},
 	})
@@ -246,3 +450,47 @@ func buildSchedulerOptions(cfg *config.Config) ([]gocron.SchedulerOption, error)
 
 	return options, nil
 }
+
+// buildDistributedOption returns the [gocron.SchedulerOption] running each job execution on a single instance:
+// in "locker" mode (default) each execution is locked by the instance running it, in "elector" mode the leader
+// instance runs all the executions.
+func buildDistributedOption(cfg *config.Config, elector gocron.Elector, locker gocron.Locker) (gocron.SchedulerOption, error) {
+	switch mode := cfg.GetString("modules.cron.scheduler.distributed.mode"); mode {
+	case "elector":
+		if elector == nil {
+			return nil, fmt.Errorf("missing distributed elector for cron scheduler, provided by the fxcronlock module")
+		}
+
+		return gocron.WithDistributedElector(elector), nil
+	case "", "locker":
+		if locker == nil {
+			return nil, fmt.Errorf("missing distributed locker for cron scheduler, provided by the fxcronlock module")
+		}
+
+		return gocron.WithDistributedLocker(locker), nil
+	default:
+		return nil, fmt.Errorf("invalid modules.cron.scheduler.distributed.mode: %s", mode)
+	}
+}
+
+// syncCronJobs periodically applies to the [CronJobManager] the schedules persisted by the other instances, until cancelled.
+func syncCronJobs(ctx context.Context, manager *CronJobManager, interval time.Duration, logger *log.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			synced, err := manager.sync(ctx)
+			if err != nil {
+				logger.Error().Err(err).Msg("cron jobs schedules synchronization error")
+			}
+
+			for _, name := range synced {
+				logger.Info().Msgf("synchronized persisted schedule for job %s", name)
+			}
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package fxcron
+
+import (
+	"math"
+	"time"
+)
+
+// CronJobOptions are the execution options of a cron job, applied by [NewFxCron] around each of its runs.
+type CronJobOptions struct {
+	Timeout      time.Duration
+	RetryMax     int
+	RetryBackoff time.Duration
+}
+
+// CronJobOption are functional options for the cron jobs executions, registered with [AsCronJobWithOptions], unlike
+// the [gocron.JobOption] applied by the scheduler.
+type CronJobOption func(o *CronJobOptions)
+
+// WithTimeout is used to specify the cron job executions timeout, overriding modules.cron.jobs.execution.timeout (0 for none).
+func WithTimeout(t time.Duration) CronJobOption {
+	return func(o *CronJobOptions) {
+		o.Timeout = t
+	}
+}
+
+// WithRetry is used to re-run the failed cron job executions up to max times within their scheduled slot, waiting for
+// an exponential backoff (doubled after each attempt) before each retry, overriding modules.cron.jobs.execution.retry (0 for none).
+func WithRetry(max int, backoff time.Duration) CronJobOption {
+	return func(o *CronJobOptions) {
+		o.RetryMax = max
+		o.RetryBackoff = backoff
+	}
+}
+
+// retryDelay returns the delay before retrying a failed attempt, and false if no retry is left.
+func (o CronJobOptions) retryDelay(attempt int) (time.Duration, bool) {
+	if attempt > o.RetryMax {
+		return 0, false
+	}
+
+	delay := o.RetryBackoff
+	for i := 1; i < attempt && delay < math.MaxInt64/2; i++ {
+		delay *= 2
+	}
+
+	return delay, true
+}
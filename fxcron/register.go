@@ -7,6 +7,12 @@ import (
 
 // AsCronJob registers a cron job into Fx, with an optional list of [gocron.JobOption].
 func AsCronJob(j any, expression string, options ...gocron.JobOption) fx.Option {
+	return AsCronJobWithOptions(j, expression, nil, options...)
+}
+
+// AsCronJobWithOptions registers a cron job into Fx, with a list of [CronJobOption] applied around its executions,
+// and an optional list of [gocron.JobOption].
+func AsCronJobWithOptions(j any, expression string, executionOptions []CronJobOption, options ...gocron.JobOption) fx.Option {
 	return fx.Options(
 		fx.Provide(
 			fx.Annotate(
@@ -17,10 +23,21 @@ func AsCronJob(j any, expression string, options ...gocron.JobOption) fx.Option
 		),
 		fx.Supply(
 			fx.Annotate(
-				NewCronJobDefinition(GetReturnType(j), expression, options...),
+				newCronJobDefinition(GetReturnType(j), expression, options, executionOptions),
 				fx.As(new(CronJobDefinition)),
 				fx.ResultTags(`group:"cron-jobs-definitions"`),
 			),
 		),
 	)
 }
+
+// AsCronJobProvider registers a [CronJobProvider] into Fx, to resolve cron jobs dynamically.
+func AsCronJobProvider(p any) fx.Option {
+	return fx.Provide(
+		fx.Annotate(
+			p,
+			fx.As(new(CronJobProvider)),
+			fx.ResultTags(`group:"cron-jobs-providers"`),
+		),
+	)
+}
@@ -13,10 +13,16 @@ type CronJob interface {
 	Run(ctx context.Context) error
 }
 
-// CronJobRegistry is the registry collecting cron jobs and their definitions.
+// CronJobProvider is the interface for cron jobs providers, resolving cron jobs dynamically (e.g. from config).
+type CronJobProvider interface {
+	CronJobs() ([]*ResolvedCronJob, error)
+}
+
+// CronJobRegistry is the registry collecting cron jobs, their definitions, and cron jobs providers.
 type CronJobRegistry struct {
 	cronJobs           []CronJob
 	cronJobDefinitions []CronJobDefinition
+	cronJobProviders   []CronJobProvider
 }
 
 // FxCronJobRegistryParam allows injection of the required dependencies in [NewFxCronJobRegistry].
@@ -24,6 +30,7 @@ type FxCronJobRegistryParam struct {
 	fx.In
 	CronJobs            []CronJob           `group:"cron-jobs"`
 	CronJobsDefinitions []CronJobDefinition `group:"cron-jobs-definitions"`
+	CronJobsProviders   []CronJobProvider   `group:"cron-jobs-providers"`
 }
 
 // NewFxCronJobRegistry returns as new [CronJobRegistry].
@@ -31,10 +38,11 @@ func NewFxCronJobRegistry(p FxCronJobRegistryParam) *CronJobRegistry {
 	return &CronJobRegistry{
 		cronJobs:           p.CronJobs,
 		cronJobDefinitions: p.CronJobsDefinitions,
+		cronJobProviders:   p.CronJobsProviders,
 	}
 }
 
-// ResolveCronJobs resolves a list of [ResolvedCronJob] from their definitions.
+// ResolveCronJobs resolves a list of [ResolvedCronJob] from their definitions, and from the registered [CronJobProvider].
 func (r *CronJobRegistry) ResolveCronJobs() ([]*ResolvedCronJob, error) {
 	resolvedCronJobs := []*ResolvedCronJob{}
 
@@ -46,10 +54,20 @@ func (r *CronJobRegistry) ResolveCronJobs() ([]*ResolvedCronJob, error) {
 
 		resolvedCronJobs = append(
 			resolvedCronJobs,
-			NewResolvedCronJob(implementation, definition.Expression(), definition.Options()...),
+			NewResolvedCronJob(implementation, definition.Expression(), definition.Options()...).
+				WithExecutionOptions(definition.ExecutionOptions()...),
 		)
 	}
 
+	for _, provider := range r.cronJobProviders {
+		providedCronJobs, err := provider.CronJobs()
+		if err != nil {
+			return nil, err
+		}
+
+		resolvedCronJobs = append(resolvedCronJobs, providedCronJobs...)
+	}
+
 	return resolvedCronJobs, nil
 }
 
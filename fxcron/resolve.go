@@ -4,9 +4,10 @@ import "github.com/go-co-op/gocron/v2"
 
 // ResolvedCronJob represents a resolved cron job, with its expression and execution options.
 type ResolvedCronJob struct {
-	implementation CronJob
-	expression     string
-	options        []gocron.JobOption
+	implementation   CronJob
+	expression       string
+	options          []gocron.JobOption
+	executionOptions []CronJobOption
 }
 
 // NewResolvedCronJob returns a new [ResolvedCronJob] instance.
@@ -18,6 +19,13 @@ func NewResolvedCronJob(implementation CronJob, expression string, options ...go
 	}
 }
 
+// WithExecutionOptions adds a list of [CronJobOption] to the [ResolvedCronJob], and returns it.
+func (r *ResolvedCronJob) WithExecutionOptions(options ...CronJobOption) *ResolvedCronJob {
+	r.executionOptions = append(r.executionOptions, options...)
+
+	return r
+}
+
 // Implementation returns the [ResolvedCronJob] cron job implementation.
 func (r *ResolvedCronJob) Implementation() CronJob {
 	return r.implementation
@@ -32,3 +40,8 @@ func (r *ResolvedCronJob) Expression() string {
 func (r *ResolvedCronJob) Options() []gocron.JobOption {
 	return r.options
 }
+
+// ExecutionOptions returns the [ResolvedCronJob] cron job execution options.
+func (r *ResolvedCronJob) ExecutionOptions() []CronJobOption {
+	return r.executionOptions
+}
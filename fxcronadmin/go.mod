@@ -0,0 +1,69 @@
+module github.com/templatedop/ftptemplate/fxcronadmin
+
+go 1.22.1
+
+require (
+	github.com/go-co-op/gocron/v2 v2.11.0
+	github.com/labstack/echo/v4 v4.12.0
+	github.com/templatedop/ftptemplate/config v0.0.1
+	github.com/templatedop/ftptemplate/cronschedule v0.0.1
+	github.com/templatedop/ftptemplate/db v0.0.1
+	github.com/templatedop/ftptemplate/fxcore v0.0.1
+	github.com/templatedop/ftptemplate/fxcron v0.0.1
+	github.com/templatedop/ftptemplate/log v0.0.1
+	go.uber.org/fx v1.22.2
+)
+
+require (
+	github.com/Masterminds/squirrel v1.5.4 // indirect
+	github.com/fsnotify/fsnotify v1.7.0 // indirect
+	github.com/go-errors/errors v1.5.1 // indirect
+	github.com/golang-jwt/jwt v3.2.2+incompatible // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/hashicorp/hcl v1.0.0 // indirect
+	github.com/jackc/pgpassfile v1.0.0 // indirect
+	github.com/jackc/pgservicefile v0.0.0-20221227161230-091c0ba34f0a // indirect
+	github.com/jackc/pgx/v5 v5.6.0 // indirect
+	github.com/jackc/puddle/v2 v2.2.1 // indirect
+	github.com/jonboulle/clockwork v0.4.0 // indirect
+	github.com/labstack/gommon v0.4.2 // indirect
+	github.com/lann/builder v0.0.0-20180802200727-47ae307949d0 // indirect
+	github.com/lann/ps v0.0.0-20150810152359-62de8c46ede0 // indirect
+	github.com/magiconair/properties v1.8.7 // indirect
+	github.com/mattn/go-colorable v0.1.13 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/mitchellh/mapstructure v1.5.0 // indirect
+	github.com/pelletier/go-toml/v2 v2.2.2 // indirect
+	github.com/robfig/cron/v3 v3.0.1 // indirect
+	github.com/rs/zerolog v1.33.0 // indirect
+	github.com/sagikazarmark/locafero v0.4.0 // indirect
+	github.com/sagikazarmark/slog-shim v0.1.0 // indirect
+	github.com/sourcegraph/conc v0.3.0 // indirect
+	github.com/spf13/afero v1.11.0 // indirect
+	github.com/spf13/cast v1.6.0 // indirect
+	github.com/spf13/pflag v1.0.5 // indirect
+	github.com/spf13/viper v1.19.0 // indirect
+	github.com/subosito/gotenv v1.6.0 // indirect
+	github.com/templatedop/ftptemplate/fxconfig v0.0.1 // indirect
+	github.com/templatedop/ftptemplate/fxgenerate v0.0.1 // indirect
+	github.com/templatedop/ftptemplate/fxhealthcheck v0.0.3 // indirect
+	github.com/templatedop/ftptemplate/fxlog v0.0.2 // indirect
+	github.com/templatedop/ftptemplate/generate v0.0.1 // indirect
+	github.com/templatedop/ftptemplate/healthcheck v0.0.1 // indirect
+	github.com/templatedop/ftptemplate/httpserver v0.0.1 // indirect
+	github.com/templatedop/ftptemplate/repo v0.0.1 // indirect
+	github.com/valyala/bytebufferpool v1.0.0 // indirect
+	github.com/valyala/fasttemplate v1.2.2 // indirect
+	go.uber.org/dig v1.18.0 // indirect
+	go.uber.org/multierr v1.10.0 // indirect
+	go.uber.org/zap v1.26.0 // indirect
+	golang.org/x/crypto v0.22.0 // indirect
+	golang.org/x/exp v0.0.0-20240613232115-7f521ea00fb8 // indirect
+	golang.org/x/net v0.24.0 // indirect
+	golang.org/x/sync v0.7.0 // indirect
+	golang.org/x/sys v0.19.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
+	golang.org/x/time v0.5.0 // indirect
+	gopkg.in/ini.v1 v1.67.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
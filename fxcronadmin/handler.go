@@ -0,0 +1,102 @@
+package fxcronadmin
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-co-op/gocron/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/templatedop/ftptemplate/fxcron"
+)
+
+// RescheduleRequest is the body of the [RescheduleJobHandler] requests.
+type RescheduleRequest struct {
+	Expression string `json:"expression"`
+	Persist    bool   `json:"persist"`
+}
+
+// ListJobsHandler is an [echo.HandlerFunc] that returns the status of the scheduled cron jobs.
+func ListJobsHandler(manager *fxcron.CronJobManager) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return c.JSON(http.StatusOK, manager.Jobs())
+	}
+}
+
+// GetJobHandler is an [echo.HandlerFunc] that returns the status of a scheduled cron job.
+func GetJobHandler(manager *fxcron.CronJobManager) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		status, err := manager.Job(c.Param("name"))
+		if err != nil {
+			return httpError(err)
+		}
+
+		return c.JSON(http.StatusOK, status)
+	}
+}
+
+// RunJobHandler is an [echo.HandlerFunc] that triggers an immediate execution of a cron job.
+func RunJobHandler(manager *fxcron.CronJobManager) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return jobActionResponse(c, manager, http.StatusAccepted, manager.RunNow(c.Param("name")))
+	}
+}
+
+// PauseJobHandler is an [echo.HandlerFunc] that pauses a cron job.
+func PauseJobHandler(manager *fxcron.CronJobManager) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return jobActionResponse(c, manager, http.StatusOK, manager.Pause(c.Request().Context(), c.Param("name")))
+	}
+}
+
+// ResumeJobHandler is an [echo.HandlerFunc] that resumes a paused cron job.
+func ResumeJobHandler(manager *fxcron.CronJobManager) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return jobActionResponse(c, manager, http.StatusOK, manager.Resume(c.Request().Context(), c.Param("name")))
+	}
+}
+
+// RescheduleJobHandler is an [echo.HandlerFunc] that changes the expression of a cron job, persisted if requested.
+func RescheduleJobHandler(manager *fxcron.CronJobManager) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		request := new(RescheduleRequest)
+		if err := c.Bind(request); err != nil {
+			return err
+		}
+
+		if request.Expression == "" {
+			return echo.NewHTTPError(http.StatusBadRequest, "missing cron expression")
+		}
+
+		err := manager.Reschedule(c.Request().Context(), c.Param("name"), request.Expression, request.Persist)
+
+		return jobActionResponse(c, manager, http.StatusOK, err)
+	}
+}
+
+// jobActionResponse returns the status of the cron job an action was applied to, or the action error.
+func jobActionResponse(c echo.Context, manager *fxcron.CronJobManager, code int, err error) error {
+	if err != nil {
+		return httpError(err)
+	}
+
+	status, err := manager.Job(c.Param("name"))
+	if err != nil {
+		return httpError(err)
+	}
+
+	return c.JSON(code, status)
+}
+
+// httpError maps the [fxcron.CronJobManager] errors to http errors.
+func httpError(err error) error {
+	switch {
+	case errors.Is(err, fxcron.ErrCronJobNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error()).SetInternal(err)
+	case errors.Is(err, fxcron.ErrCronJobPaused):
+		return echo.NewHTTPError(http.StatusConflict, err.Error()).SetInternal(err)
+	case errors.Is(err, fxcron.ErrCronJobScheduleStoreMissing), errors.Is(err, gocron.ErrCronJobParse):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,171 @@
+package fxcronadmin
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/templatedop/ftptemplate/config"
+	"github.com/templatedop/ftptemplate/cronschedule"
+	"github.com/templatedop/ftptemplate/db"
+	"github.com/templatedop/ftptemplate/fxcore"
+	"github.com/templatedop/ftptemplate/fxcron"
+	"github.com/templatedop/ftptemplate/log"
+	"go.uber.org/fx"
+)
+
+const (
+	ModuleName  = "cronadmin"
+	ConfigKey   = "modules.cron.admin"
+	DefaultPath = "/cron"
+)
+
+// FxCronAdminModule is the [Fx] cron admin module, routing on the core http server the endpoints to list, trigger,
+// pause, resume and reschedule the cron jobs when modules.cron.admin is enabled, and providing the
+// [fxcron.CronJobScheduleStore] the rescheduled expressions are persisted in Postgres with.
+//
+// [Fx]: https://github.com/uber-go/fx
+var FxCronAdminModule = fx.Module(
+	ModuleName,
+	fx.Provide(
+		NewFxCronSchedules,
+		fx.Annotate(
+			NewCronJobScheduleStore,
+			fx.As(new(fxcron.CronJobScheduleStore)),
+		),
+	),
+	fx.Invoke(RegisterFxCronAdminHandlers),
+)
+
+// FxCronSchedulesParam allows injection of the required dependencies in [NewFxCronSchedules].
+type FxCronSchedulesParam struct {
+	fx.In
+	LifeCycle fx.Lifecycle
+	Config    *config.Config
+	Logger    *log.Logger
+	DB        *db.DB
+}
+
+// NewFxCronSchedules returns new [cronschedule.Schedules], and creates their table on start
+// if modules.cron.admin.persist.enabled and modules.cron.admin.persist.migrate are enabled.
+func NewFxCronSchedules(p FxCronSchedulesParam) *cronschedule.Schedules {
+	options := []cronschedule.SchedulesOption{}
+
+	if table := p.Config.GetString(ConfigKey + ".persist.table"); table != "" {
+		options = append(options, cronschedule.WithTable(table))
+	}
+
+	schedules := cronschedule.NewSchedules(p.DB, options...)
+
+	if p.Config.GetBool(ConfigKey+".persist.enabled") && p.Config.GetBool(ConfigKey+".persist.migrate") {
+		p.LifeCycle.Append(fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				p.Logger.Debug().Str("module", ModuleName).Msgf("migrating cron schedules table %s", schedules.Table())
+
+				return schedules.Migrate(ctx)
+			},
+		})
+	}
+
+	return schedules
+}
+
+// CronJobScheduleStore is a [fxcron.CronJobScheduleStore] persisting the expressions in [cronschedule.Schedules].
+type CronJobScheduleStore struct {
+	schedules *cronschedule.Schedules
+}
+
+// NewCronJobScheduleStore returns a new [CronJobScheduleStore].
+func NewCronJobScheduleStore(schedules *cronschedule.Schedules) *CronJobScheduleStore {
+	return &CronJobScheduleStore{
+		schedules: schedules,
+	}
+}
+
+// Schedules returns the persisted expressions and paused states, by job name.
+func (s *CronJobScheduleStore) Schedules(ctx context.Context) (map[string]fxcron.CronJobSchedule, error) {
+	schedules, err := s.schedules.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make(map[string]fxcron.CronJobSchedule, len(schedules))
+	for _, schedule := range schedules {
+		states[schedule.Name] = fxcron.CronJobSchedule{
+			Expression: schedule.Expression,
+			Paused:     schedule.Paused,
+		}
+	}
+
+	return states, nil
+}
+
+// SaveSchedule persists the expression of a job.
+func (s *CronJobScheduleStore) SaveSchedule(ctx context.Context, name string, expression string) error {
+	return s.schedules.Save(ctx, name, expression)
+}
+
+// SavePaused persists the paused state of a job.
+func (s *CronJobScheduleStore) SavePaused(ctx context.Context, name string, paused bool) error {
+	return s.schedules.SavePaused(ctx, name, paused)
+}
+
+// FxCronAdminParam allows injection of the required dependencies in [RegisterFxCronAdminHandlers].
+type FxCronAdminParam struct {
+	fx.In
+	Core    *fxcore.Core
+	Config  *config.Config
+	Logger  *log.Logger
+	Manager *fxcron.CronJobManager
+}
+
+// RegisterFxCronAdminHandlers routes the cron admin endpoints on the core http server, under modules.cron.admin.path,
+// when modules.cron.admin.enabled. The requests must present modules.cron.admin.token as a bearer token, required.
+func RegisterFxCronAdminHandlers(p FxCronAdminParam) error {
+	if !p.Config.GetBool(ConfigKey + ".enabled") {
+		return nil
+	}
+
+	token := p.Config.GetString(ConfigKey + ".token")
+	if token == "" {
+		err := errors.New("missing " + ConfigKey + ".token, required when the cron admin endpoints are enabled")
+		p.Logger.Error().Err(err).Str("module", ModuleName).Msg("cron admin endpoints registration error")
+
+		return err
+	}
+
+	path := p.Config.GetString(ConfigKey + ".path")
+	if path == "" {
+		path = DefaultPath
+	}
+
+	group := p.Core.HttpServer().Group(path, tokenMiddleware(token))
+
+	group.GET("/jobs", ListJobsHandler(p.Manager))
+	group.GET("/jobs/:name", GetJobHandler(p.Manager))
+	group.POST("/jobs/:name/run", RunJobHandler(p.Manager))
+	group.POST("/jobs/:name/pause", PauseJobHandler(p.Manager))
+	group.POST("/jobs/:name/resume", ResumeJobHandler(p.Manager))
+	group.PUT("/jobs/:name/schedule", RescheduleJobHandler(p.Manager))
+
+	p.Logger.Debug().Str("module", ModuleName).Msgf("cron admin endpoints registered under %s", path)
+
+	return nil
+}
+
+// tokenMiddleware rejects the requests not presenting the token as a bearer token.
+func tokenMiddleware(token string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			bearer, ok := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+				return echo.NewHTTPError(http.StatusUnauthorized, "invalid or missing cron admin token")
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package fxcronhistory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/templatedop/ftptemplate/config"
+	"github.com/templatedop/ftptemplate/cronhistory"
+	"github.com/templatedop/ftptemplate/db"
+	"github.com/templatedop/ftptemplate/fxcron"
+	"github.com/templatedop/ftptemplate/log"
+	"go.uber.org/fx"
+)
+
+const (
+	ModuleName           = "cronhistory"
+	ConfigKey            = "modules.cron.history"
+	DefaultPruneInterval = time.Hour
+)
+
+// FxCronHistoryModule is the [Fx] cron history module, providing the [fxcron.CronJobExecutionRecorder]
+// the cron jobs executions are recorded in Postgres with, when modules.cron.history is enabled.
+//
+// [Fx]: https://github.com/uber-go/fx
+var FxCronHistoryModule = fx.Module(
+	ModuleName,
+	fx.Provide(
+		NewFxCronHistory,
+		fx.Annotate(
+			NewCronJobExecutionRecorder,
+			fx.As(new(fxcron.CronJobExecutionRecorder)),
+		),
+	),
+)
+
+// FxCronHistoryParam allows injection of the required dependencies in [NewFxCronHistory].
+type FxCronHistoryParam struct {
+	fx.In
+	LifeCycle fx.Lifecycle
+	Config    *config.Config
+	Logger    *log.Logger
+	DB        *db.DB
+}
+
+// NewFxCronHistory returns a new [cronhistory.History]. When modules.cron.history is enabled, it creates its table on start
+// if modules.cron.history.migrate is enabled, and prunes the executions older than the retention periodically.
+func NewFxCronHistory(p FxCronHistoryParam) (*cronhistory.History, error) {
+	options := []cronhistory.HistoryOption{}
+
+	if table := p.Config.GetString(ConfigKey + ".table"); table != "" {
+		options = append(options, cronhistory.WithTable(table))
+	}
+
+	if hostname := p.Config.GetString(ConfigKey + ".hostname"); hostname != "" {
+		options = append(options, cronhistory.WithHostname(hostname))
+	}
+
+	if cfgRetention := p.Config.GetString(ConfigKey + ".retention"); cfgRetention != "" {
+		retention, err := time.ParseDuration(cfgRetention)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s.retention: %w", ConfigKey, err)
+		}
+
+		options = append(options, cronhistory.WithRetention(retention))
+	}
+
+	pruneInterval := DefaultPruneInterval
+	if cfgPruneInterval := p.Config.GetString(ConfigKey + ".prune_interval"); cfgPruneInterval != "" {
+		interval, err := time.ParseDuration(cfgPruneInterval)
+		if err != nil || interval <= 0 {
+			return nil, fmt.Errorf("invalid %s.prune_interval: %s", ConfigKey, cfgPruneInterval)
+		}
+
+		pruneInterval = interval
+	}
+
+	history := cronhistory.NewHistory(p.DB, options...)
+
+	if !p.Config.GetBool(ConfigKey + ".enabled") {
+		return history, nil
+	}
+
+	if p.Config.GetBool(ConfigKey + ".migrate") {
+		p.LifeCycle.Append(fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				p.Logger.Debug().Str("module", ModuleName).Msgf("migrating cron history table %s", history.Table())
+
+				return history.Migrate(ctx)
+			},
+		})
+	}
+
+	// a zero retention keeps the executions forever
+	if history.Retention() > 0 {
+		pruneCtx, pruneCancel := context.WithCancel(context.Background())
+		pruneDone := make(chan struct{})
+
+		p.LifeCycle.Append(fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				go func() {
+					defer close(pruneDone)
+
+					prune(pruneCtx, p.Logger, history, pruneInterval)
+				}()
+
+				return nil
+			},
+			OnStop: func(ctx context.Context) error {
+				pruneCancel()
+
+				select {
+				case <-pruneDone:
+				case <-ctx.Done():
+				}
+
+				return nil
+			},
+		})
+	}
+
+	return history, nil
+}
+
+// prune prunes the history on start then at each interval, until the context is cancelled.
+func prune(ctx context.Context, logger *log.Logger, history *cronhistory.History, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		pruned, err := history.Prune(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.Error().Err(err).Str("module", ModuleName).Msg("cron history pruning error")
+		}
+
+		if pruned > 0 {
+			logger.Debug().Str("module", ModuleName).Msgf("pruned %d cron jobs executions older than %s", pruned, history.Retention())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CronJobExecutionRecorder is a [fxcron.CronJobExecutionRecorder] recording the executions in a [cronhistory.History].
+type CronJobExecutionRecorder struct {
+	history *cronhistory.History
+}
+
+// NewCronJobExecutionRecorder returns a new [CronJobExecutionRecorder].
+func NewCronJobExecutionRecorder(history *cronhistory.History) *CronJobExecutionRecorder {
+	return &CronJobExecutionRecorder{
+		history: history,
+	}
+}
+
+// Start records a running execution.
+func (r *CronJobExecutionRecorder) Start(ctx context.Context, execution fxcron.CronJobExecution) error {
+	return r.history.Start(ctx, execution.JobName, execution.ExecutionId, execution.StartedAt)
+}
+
+// Finish records the outcome of an execution.
+func (r *CronJobExecutionRecorder) Finish(ctx context.Context, execution fxcron.CronJobExecution) error {
+	return r.history.Finish(ctx, execution.ExecutionId, execution.FinishedAt, execution.Err)
+}
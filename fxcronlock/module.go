@@ -0,0 +1,123 @@
+package fxcronlock
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-co-op/gocron/v2"
+	"github.com/templatedop/ftptemplate/config"
+	"github.com/templatedop/ftptemplate/cronlock"
+	"github.com/templatedop/ftptemplate/db"
+	"github.com/templatedop/ftptemplate/log"
+	"go.uber.org/fx"
+)
+
+const (
+	ModuleName = "cronlock"
+	ConfigKey  = "modules.cron.scheduler.distributed"
+)
+
+// FxCronLockModule is the [Fx] cron lock module, providing the [gocron.Elector] and [gocron.Locker]
+// backed by Postgres leases the cron scheduler runs the jobs executions on a single instance with,
+// when modules.cron.scheduler.distributed is enabled.
+//
+// [Fx]: https://github.com/uber-go/fx
+var FxCronLockModule = fx.Module(
+	ModuleName,
+	fx.Provide(
+		NewFxCronLeases,
+		fx.Annotate(
+			NewFxCronElector,
+			fx.As(new(gocron.Elector)),
+		),
+		fx.Annotate(
+			cronlock.NewLocker,
+			fx.As(new(gocron.Locker)),
+		),
+	),
+)
+
+// FxCronLeasesParam allows injection of the required dependencies in [NewFxCronLeases].
+type FxCronLeasesParam struct {
+	fx.In
+	LifeCycle fx.Lifecycle
+	Config    *config.Config
+	Logger    *log.Logger
+	DB        *db.DB
+}
+
+// NewFxCronLeases returns new [cronlock.Leases], and creates their table on start if modules.cron.scheduler.distributed.migrate is enabled.
+func NewFxCronLeases(p FxCronLeasesParam) (*cronlock.Leases, error) {
+	options := []cronlock.LeasesOption{}
+
+	if table := p.Config.GetString(ConfigKey + ".table"); table != "" {
+		options = append(options, cronlock.WithTable(table))
+	}
+
+	if owner := p.Config.GetString(ConfigKey + ".owner"); owner != "" {
+		options = append(options, cronlock.WithOwner(owner))
+	}
+
+	if cfgTTL := p.Config.GetString(ConfigKey + ".ttl"); cfgTTL != "" {
+		ttl, err := time.ParseDuration(cfgTTL)
+		if err != nil || ttl <= 0 {
+			return nil, fmt.Errorf("invalid %s.ttl: %s", ConfigKey, cfgTTL)
+		}
+
+		options = append(options, cronlock.WithTTL(ttl))
+	}
+
+	if cfgMinHold := p.Config.GetString(ConfigKey + ".min_hold"); cfgMinHold != "" {
+		minHold, err := time.ParseDuration(cfgMinHold)
+		if err != nil || minHold < 0 {
+			return nil, fmt.Errorf("invalid %s.min_hold: %s", ConfigKey, cfgMinHold)
+		}
+
+		options = append(options, cronlock.WithMinHold(minHold))
+	}
+
+	leases := cronlock.NewLeases(p.DB, options...)
+
+	if p.Config.GetBool(ConfigKey+".enabled") && p.Config.GetBool(ConfigKey+".migrate") {
+		p.LifeCycle.Append(fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				p.Logger.Debug().Str("module", ModuleName).Msgf("migrating cron leases table %s", leases.Table())
+
+				return leases.Migrate(ctx)
+			},
+		})
+	}
+
+	return leases, nil
+}
+
+// FxCronElectorParam allows injection of the required dependencies in [NewFxCronElector].
+type FxCronElectorParam struct {
+	fx.In
+	LifeCycle fx.Lifecycle
+	Config    *config.Config
+	Logger    *log.Logger
+	Leases    *cronlock.Leases
+}
+
+// NewFxCronElector returns a new [cronlock.Elector], resigning the leadership on stop for another instance to take over.
+func NewFxCronElector(p FxCronElectorParam) *cronlock.Elector {
+	elector := cronlock.NewElector(p.Leases)
+
+	if !p.Config.GetBool(ConfigKey+".enabled") || p.Config.GetString(ConfigKey+".mode") != "elector" {
+		return elector
+	}
+
+	p.LifeCycle.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			if err := elector.Resign(ctx); err != nil {
+				p.Logger.Error().Err(err).Str("module", ModuleName).Msg("cron leadership resignation error")
+			}
+
+			return nil
+		},
+	})
+
+	return elector
+}
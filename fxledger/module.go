@@ -0,0 +1,68 @@
+package fxledger
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/templatedop/ftptemplate/config"
+	"github.com/templatedop/ftptemplate/db"
+	"github.com/templatedop/ftptemplate/ledger"
+	"github.com/templatedop/ftptemplate/log"
+	"go.uber.org/fx"
+)
+
+const (
+	ModuleName = "ledger"
+)
+
+// FxLedgerModule is the [Fx] ledger module.
+//
+// [Fx]: https://github.com/uber-go/fx
+var FxLedgerModule = fx.Module(
+	ModuleName,
+	fx.Provide(
+		NewFxLedger,
+	),
+)
+
+// FxLedgerParam allows injection of the required dependencies in [NewFxLedger].
+type FxLedgerParam struct {
+	fx.In
+	LifeCycle fx.Lifecycle
+	Config    *config.Config
+	Logger    *log.Logger
+	DB        *db.DB
+}
+
+// NewFxLedger returns a new [ledger.Ledger], and creates its table on start if modules.ledger.migrate is enabled.
+func NewFxLedger(p FxLedgerParam) (*ledger.Ledger, error) {
+	options := []ledger.LedgerOption{}
+
+	if table := p.Config.GetString("modules.ledger.table"); table != "" {
+		options = append(options, ledger.WithTable(table))
+	}
+
+	if cfgStaleAfter := p.Config.GetString("modules.ledger.stale_after"); cfgStaleAfter != "" {
+		staleAfter, err := time.ParseDuration(cfgStaleAfter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modules.ledger.stale_after: %w", err)
+		}
+
+		options = append(options, ledger.WithStaleAfter(staleAfter))
+	}
+
+	l := ledger.NewLedger(p.DB, options...)
+
+	if p.Config.GetBool("modules.ledger.migrate") {
+		p.LifeCycle.Append(fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				p.Logger.Debug().Str("module", ModuleName).Msgf("migrating ledger table %s", l.Table())
+
+				return l.Migrate(ctx)
+			},
+		})
+	}
+
+	return l, nil
+}
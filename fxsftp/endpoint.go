@@ -0,0 +1,293 @@
+package fxsftp
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/templatedop/ftptemplate/config"
+	"github.com/templatedop/ftptemplate/transfer"
+	"go.uber.org/fx"
+)
+
+const EndpointsConfigKey = "modules.sftp.endpoints"
+
+// FxSftpEndpointRegistryParam allows injection of the required dependencies in [NewFxSftpEndpointRegistry].
+type FxSftpEndpointRegistryParam struct {
+	fx.In
+	Config *config.Config
+}
+
+// NewFxSftpEndpointRegistry returns a new [transfer.EndpointRegistry], built from the modules.sftp.endpoints configuration.
+func NewFxSftpEndpointRegistry(p FxSftpEndpointRegistryParam) (*transfer.EndpointRegistry, error) {
+	var endpoints []*transfer.Endpoint
+
+	for _, name := range subKeys(p.Config, EndpointsConfigKey) {
+		endpoint, err := buildEndpoint(p.Config, name)
+		if err != nil {
+			return nil, err
+		}
+
+		endpoints = append(endpoints, endpoint)
+	}
+
+	return transfer.NewEndpointRegistry(endpoints...), nil
+}
+
+func buildEndpoint(cfg *config.Config, name string) (*transfer.Endpoint, error) {
+	prefix := fmt.Sprintf("%s.%s", EndpointsConfigKey, name)
+
+	endpoint := &transfer.Endpoint{
+		Name:                  name,
+		Host:                  cfg.GetString(prefix + ".host"),
+		Port:                  cfg.GetInt(prefix + ".port"),
+		User:                  cfg.GetString(prefix + ".user"),
+		Password:              cfg.GetString(prefix + ".password"),
+		KeyPath:               cfg.GetString(prefix + ".auth.key_path"),
+		KeyPassphrase:         cfg.GetString(prefix + ".auth.key_passphrase"),
+		CertificatePath:       cfg.GetString(prefix + ".auth.certificate_path"),
+		KnownHostsFile:        cfg.GetString(prefix + ".host_key.known_hosts"),
+		HostKeyMode:           transfer.FetchHostKeyMode(cfg.GetString(prefix + ".host_key.mode")),
+		Fingerprints:          cfg.GetStringSlice(prefix + ".host_key.fingerprints"),
+		FtpMode:               transfer.FetchFtpMode(cfg.GetString(prefix + ".ftp.mode")),
+		ActiveAddress:         cfg.GetString(prefix + ".ftp.active_address"),
+		TLSCertificatePath:    cfg.GetString(prefix + ".ftp.tls.certificate_path"),
+		TLSKeyPath:            cfg.GetString(prefix + ".ftp.tls.key_path"),
+		TLSCAPath:             cfg.GetString(prefix + ".ftp.tls.ca_path"),
+		TLSServerName:         cfg.GetString(prefix + ".ftp.tls.server_name"),
+		TLSInsecureSkipVerify: cfg.GetBool(prefix + ".ftp.tls.insecure_skip_verify"),
+		Root:                  cfg.GetString(prefix + ".root"),
+		Bucket:                cfg.GetString(prefix + ".s3.bucket"),
+		Region:                cfg.GetString(prefix + ".s3.region"),
+		S3Secure:              true,
+		S3PathStyle:           cfg.GetBool(prefix + ".s3.path_style"),
+		ChunkSize:             cfg.GetInt(prefix + ".chunk_size"),
+		ConcurrentRequests:    cfg.GetInt(prefix + ".concurrent_requests"),
+		Workers:               cfg.GetInt(prefix + ".concurrency.workers"),
+		Connections:           cfg.GetInt(prefix + ".concurrency.connections"),
+		Resume:                cfg.GetBool(prefix + ".resume.enabled"),
+		ResumeVerify:          transfer.FetchResumeVerifyMode(cfg.GetString(prefix + ".resume.verify")),
+		Atomic:                cfg.GetBool(prefix + ".atomic.enabled"),
+		TemporaryPattern:      cfg.GetString(prefix + ".atomic.temp_pattern"),
+		Checksum:              transfer.Sha256ChecksumAlgorithm,
+		ChecksumSidecar:       cfg.GetBool(prefix + ".checksum.sidecar"),
+		Validate:              true,
+		Warmup:                cfg.GetBool(prefix + ".pool.warmup"),
+		Dirs:                  make(map[string]string),
+	}
+
+	// url, selecting the protocol by its scheme, and taking precedence over host, port, user and password
+	if cfgUrl := cfg.GetString(prefix + ".url"); cfgUrl != "" {
+		if err := applyEndpointUrl(endpoint, cfgUrl); err != nil {
+			return nil, fmt.Errorf("invalid url for sftp endpoint %s: %w", name, err)
+		}
+	}
+
+	switch {
+	case endpoint.Protocol == transfer.LocalProtocol && endpoint.Root == "":
+		return nil, fmt.Errorf("missing root for sftp endpoint %s", name)
+	case endpoint.Protocol != transfer.LocalProtocol && endpoint.Host == "":
+		return nil, fmt.Errorf("missing host for sftp endpoint %s", name)
+	case endpoint.Protocol == transfer.S3Protocol && endpoint.Bucket == "":
+		return nil, fmt.Errorf("missing s3.bucket for sftp endpoint %s", name)
+	}
+
+	// s3 over tls, enabled by default
+	if endpoint.Protocol == transfer.S3Protocol {
+		if cfg.IsSet(prefix + ".s3.secure") {
+			endpoint.S3Secure = cfg.GetBool(prefix + ".s3.secure")
+		}
+
+		if cfg.IsSet(prefix + ".s3.tls.ca_path") {
+			endpoint.TLSCAPath = cfg.GetString(prefix + ".s3.tls.ca_path")
+		}
+
+		if cfg.IsSet(prefix + ".s3.tls.insecure_skip_verify") {
+			endpoint.TLSInsecureSkipVerify = cfg.GetBool(prefix + ".s3.tls.insecure_skip_verify")
+		}
+	}
+
+	if endpoint.Port == 0 {
+		endpoint.Port = endpoint.Protocol.DefaultPort()
+	}
+
+	// timeout, default 30s
+	if cfgTimeout := cfg.GetString(prefix + ".timeout"); cfgTimeout != "" {
+		timeout, err := time.ParseDuration(cfgTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout for sftp endpoint %s: %w", name, err)
+		}
+
+		endpoint.Timeout = timeout
+	}
+
+	// atomic uploads stale temporary files, default 24h
+	endpoint.StaleAfter = 24 * time.Hour
+	if cfgStaleAfter := cfg.GetString(prefix + ".atomic.stale_after"); cfgStaleAfter != "" {
+		staleAfter, err := time.ParseDuration(cfgStaleAfter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid atomic.stale_after for sftp endpoint %s: %w", name, err)
+		}
+
+		endpoint.StaleAfter = staleAfter
+	}
+
+	// checksum verification, default none
+	checksumVerify, err := transfer.ParseChecksumVerifyMode(cfg.GetString(prefix + ".checksum.verify"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid checksum.verify for sftp endpoint %s: %w", name, err)
+	}
+
+	endpoint.ChecksumVerify = checksumVerify
+
+	// checksum algorithm, default sha256
+	if cfg.IsSet(prefix + ".checksum.algorithm") {
+		checksum, err := transfer.ParseChecksumAlgorithm(cfg.GetString(prefix + ".checksum.algorithm"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid checksum.algorithm for sftp endpoint %s: %w", name, err)
+		}
+
+		endpoint.Checksum = checksum
+	}
+
+	// pool maintenance, default keepalive 30s, idle timeout 5m, max lifetime 1h, validation enabled
+	// retry, default base delay 1s, max delay 30s
+	durations := []struct {
+		key   string
+		value *time.Duration
+		def   time.Duration
+	}{
+		{key: "pool.keepalive", value: &endpoint.KeepAlive, def: 30 * time.Second},
+		{key: "pool.idle_timeout", value: &endpoint.IdleTimeout, def: 5 * time.Minute},
+		{key: "pool.max_lifetime", value: &endpoint.MaxLifetime, def: time.Hour},
+		{key: "retry.base_delay", value: &endpoint.RetryBaseDelay, def: time.Second},
+		{key: "retry.max_delay", value: &endpoint.RetryMaxDelay, def: 30 * time.Second},
+	}
+
+	for _, d := range durations {
+		*d.value = d.def
+		if cfgDuration := cfg.GetString(prefix + "." + d.key); cfgDuration != "" {
+			duration, err := time.ParseDuration(cfgDuration)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s for sftp endpoint %s: %w", d.key, name, err)
+			}
+
+			*d.value = duration
+		}
+	}
+
+	if cfg.IsSet(prefix + ".pool.validate") {
+		endpoint.Validate = cfg.GetBool(prefix + ".pool.validate")
+	}
+
+	// retry, default 3 attempts with a 0.2 jitter
+	endpoint.RetryMaxAttempts = 3
+	if cfg.IsSet(prefix + ".retry.max_attempts") {
+		endpoint.RetryMaxAttempts = cfg.GetInt(prefix + ".retry.max_attempts")
+	}
+
+	endpoint.RetryJitter = 0.2
+	if cfg.IsSet(prefix + ".retry.jitter") {
+		endpoint.RetryJitter = cfg.GetFloat64(prefix + ".retry.jitter")
+	}
+
+	for _, cfgKind := range cfg.GetStringSlice(prefix + ".auth.order") {
+		kind, err := transfer.ParseAuthKind(cfgKind)
+		if err != nil {
+			return nil, fmt.Errorf("invalid auth.order for sftp endpoint %s: %w", name, err)
+		}
+
+		endpoint.AuthOrder = append(endpoint.AuthOrder, kind)
+	}
+
+	// pgp encryption and signature of uploads, decryption and signature verification of downloads
+	uploadStages, downloadStages, err := buildPgpStages(cfg, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pgp for sftp endpoint %s: %w", name, err)
+	}
+
+	endpoint.UploadStages = append(endpoint.UploadStages, uploadStages...)
+	endpoint.DownloadStages = append(endpoint.DownloadStages, downloadStages...)
+
+	for _, dir := range subKeys(cfg, prefix+".dirs") {
+		endpoint.Dirs[dir] = cfg.GetString(prefix + ".dirs." + dir)
+	}
+
+	return endpoint, nil
+}
+
+// applyEndpointUrl applies an endpoint url, in the scheme://[user[:password]@]host[:port] form.
+// The supported schemes are sftp, ftp, ftps (FTP over implicit TLS) and ftpes (FTP over explicit TLS),
+// local, in the local:///root/dir form, and s3, in the s3://[access_key[:secret_key]@]host[:port]/bucket[/root] form.
+func applyEndpointUrl(endpoint *transfer.Endpoint, rawUrl string) error {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return err
+	}
+
+	endpoint.Protocol = transfer.FetchProtocol(u.Scheme)
+	if endpoint.Protocol.String() != strings.ToLower(u.Scheme) {
+		return fmt.Errorf("unsupported scheme %s", u.Scheme)
+	}
+
+	switch endpoint.Protocol {
+	case transfer.LocalProtocol:
+		endpoint.Root = u.Path
+
+		return nil
+	case transfer.S3Protocol:
+		bucket, root, _ := strings.Cut(strings.TrimPrefix(u.Path, "/"), "/")
+		if bucket != "" {
+			endpoint.Bucket = bucket
+		}
+
+		if root != "" {
+			endpoint.Root = root
+		}
+	}
+
+	endpoint.Host = u.Hostname()
+
+	if port := u.Port(); port != "" {
+		if endpoint.Port, err = strconv.Atoi(port); err != nil {
+			return fmt.Errorf("invalid port %s: %w", port, err)
+		}
+	}
+
+	if u.User != nil {
+		endpoint.User = u.User.Username()
+
+		if password, ok := u.User.Password(); ok {
+			endpoint.Password = password
+		}
+	}
+
+	return nil
+}
+
+// subKeys returns the sorted direct child keys of a configuration key.
+// The config keys are scanned instead of using GetStringMap, since env expanded values would shadow their siblings.
+func subKeys(cfg *config.Config, key string) []string {
+	prefix := key + "."
+	seen := make(map[string]bool)
+
+	var keys []string
+	for _, k := range cfg.AllKeys() {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+
+		child := strings.SplitN(strings.TrimPrefix(k, prefix), ".", 2)[0]
+		if !seen[child] {
+			seen[child] = true
+			keys = append(keys, child)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
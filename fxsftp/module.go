@@ -0,0 +1,18 @@
+package fxsftp
+
+import (
+	"github.com/templatedop/ftptemplate/transfer"
+	"go.uber.org/fx"
+)
+
+// ModuleName is the module name.
+const ModuleName = "sftp"
+
+var FxSftpModule = fx.Module(
+	ModuleName,
+	fx.Provide(
+		transfer.NewDefaultClientFactory,
+		NewFxSftpEndpointRegistry,
+		NewFxSftpPoolRegistry,
+	),
+)
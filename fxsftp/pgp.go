@@ -0,0 +1,83 @@
+package fxsftp
+
+import (
+	"fmt"
+
+	"github.com/templatedop/ftptemplate/config"
+	"github.com/templatedop/ftptemplate/transfer"
+)
+
+// buildPgpStages returns the OpenPGP upload and download stages of an endpoint pgp configuration:
+// uploads are encrypted to the pgp.encrypt.recipients keyrings and signed with the pgp.sign key,
+// downloads are decrypted with the pgp.decrypt key and their signature verified with the pgp.verify.keyrings.
+func buildPgpStages(cfg *config.Config, prefix string) ([]transfer.Stage, []transfer.Stage, error) {
+	var uploadStages, downloadStages []transfer.Stage
+
+	// upload, encryption and signature
+	recipients, err := transfer.LoadPgpKeyring(cfg.GetStringSlice(prefix + ".pgp.encrypt.recipients")...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	options := []transfer.PgpOption{transfer.WithPgpArmor(cfg.GetBool(prefix + ".pgp.armor"))}
+
+	signKeyPath := cfg.GetString(prefix + ".pgp.sign.key_path")
+	if signKeyPath != "" {
+		signers, err := loadPgpPrivateKeys(signKeyPath, cfg.GetString(prefix+".pgp.sign.passphrase"))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		options = append(options, transfer.WithPgpSigner(signers[0]))
+	}
+
+	if len(recipients) > 0 || signKeyPath != "" {
+		stage, err := transfer.NewPgpEncryptStage(recipients, options...)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		uploadStages = append(uploadStages, stage)
+	}
+
+	// download, decryption and signature verification
+	verifyKeys, err := transfer.LoadPgpKeyring(cfg.GetStringSlice(prefix + ".pgp.verify.keyrings")...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var keys transfer.PgpKeyring
+	if keyPath := cfg.GetString(prefix + ".pgp.decrypt.key_path"); keyPath != "" {
+		if keys, err = loadPgpPrivateKeys(keyPath, cfg.GetString(prefix+".pgp.decrypt.passphrase")); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if len(keys) > 0 || len(verifyKeys) > 0 {
+		stage, err := transfer.NewPgpDecryptStage(keys, transfer.WithPgpVerifyKeys(verifyKeys))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		downloadStages = append(downloadStages, stage)
+	}
+
+	return uploadStages, downloadStages, nil
+}
+
+func loadPgpPrivateKeys(path string, passphrase string) (transfer.PgpKeyring, error) {
+	keys, err := transfer.LoadPgpKeyring(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(keys) == 0 || keys[0].PrivateKey == nil {
+		return nil, fmt.Errorf("missing pgp private key in [%s]", path)
+	}
+
+	if err = transfer.UnlockPgpKeys(keys, passphrase); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,44 @@
+package fxsftp
+
+import (
+	"context"
+
+	"github.com/templatedop/ftptemplate/log"
+	"github.com/templatedop/ftptemplate/transfer"
+	"go.uber.org/fx"
+)
+
+// FxSftpPoolRegistryParam allows injection of the required dependencies in [NewFxSftpPoolRegistry].
+type FxSftpPoolRegistryParam struct {
+	fx.In
+	LifeCycle fx.Lifecycle
+	Logger    *log.Logger
+	Factory   transfer.SftpClientFactory
+	Endpoints *transfer.EndpointRegistry
+}
+
+// NewFxSftpPoolRegistry returns a new [transfer.PoolRegistry], starting its pools on start and closing them on stop.
+func NewFxSftpPoolRegistry(p FxSftpPoolRegistryParam) *transfer.PoolRegistry {
+	registry := transfer.NewPoolRegistry(p.Factory, p.Endpoints)
+
+	p.LifeCycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			p.Logger.Debug().Str("module", ModuleName).Msg("starting sftp pools")
+
+			if err := registry.Start(ctx); err != nil {
+				return err
+			}
+
+			p.Logger.Info().Strs("endpoints", p.Endpoints.Names()).Msg("sftp pools started")
+
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			p.Logger.Debug().Str("module", ModuleName).Msg("closing sftp pools")
+
+			return registry.Close()
+		},
+	})
+
+	return registry
+}
@@ -0,0 +1,179 @@
+package fxsftpserver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/templatedop/ftptemplate/config"
+	"github.com/templatedop/ftptemplate/db"
+	"github.com/templatedop/ftptemplate/log"
+	"github.com/templatedop/ftptemplate/sftpserver"
+	"go.uber.org/fx"
+)
+
+const (
+	ModuleName = "sftpserver"
+	ConfigKey  = "modules.sftpserver"
+)
+
+// FxSftpServerModule is the [Fx] embedded sftp server module.
+//
+// [Fx]: https://github.com/uber-go/fx
+var FxSftpServerModule = fx.Module(
+	ModuleName,
+	fx.Provide(
+		NewFxSftpServer,
+	),
+	fx.Invoke(func(*sftpserver.Server) {}),
+)
+
+// FxSftpServerParam allows injection of the required dependencies in [NewFxSftpServer].
+type FxSftpServerParam struct {
+	fx.In
+	LifeCycle fx.Lifecycle
+	Config    *config.Config
+	Logger    *log.Logger
+	DB        *db.DB                           `optional:"true"`
+	Handlers  []sftpserver.FileReceivedHandler `group:"sftpserver-file-received-handlers"`
+}
+
+// NewFxSftpServer returns a new [sftpserver.Server], built from the modules.sftpserver configuration,
+// with the registered [sftpserver.FileReceivedHandler] subscribed.
+// The server is started on start if modules.sftpserver.enabled is true, and shut down on stop.
+func NewFxSftpServer(p FxSftpServerParam) (*sftpserver.Server, error) {
+	logger := log.FromZerolog(p.Logger.ToZerolog().With().Str("system", ModuleName).Logger())
+
+	if !p.Config.GetBool(ConfigKey + ".enabled") {
+		server := sftpserver.NewServer(sftpserver.WithLogger(logger))
+		subscribe(server, p.Handlers)
+
+		return server, nil
+	}
+
+	options, err := buildServerOptions(p.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	// users, from the configuration then from the users table
+	users, err := buildUsers(p.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	stores := []sftpserver.UserStore{users}
+
+	var dbUsers *sftpserver.DBUserStore
+	if p.Config.GetBool(ConfigKey + ".users_table.enabled") {
+		if p.DB == nil {
+			return nil, fmt.Errorf("missing database for %s.users_table", ConfigKey)
+		}
+
+		dbUsers = sftpserver.NewDBUserStore(p.DB, p.Config.GetString(ConfigKey+".users_table.table"))
+		stores = append(stores, dbUsers)
+	}
+
+	options = append(
+		options,
+		sftpserver.WithUsers(sftpserver.ChainUserStores(stores...)),
+		sftpserver.WithLogger(logger),
+	)
+
+	server := sftpserver.NewServer(options...)
+	subscribe(server, p.Handlers)
+
+	p.LifeCycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			if dbUsers != nil && p.Config.GetBool(ConfigKey+".users_table.migrate") {
+				logger.Debug().Str("module", ModuleName).Msgf("migrating sftp users table %s", dbUsers.Table())
+
+				if err := dbUsers.Migrate(ctx); err != nil {
+					return err
+				}
+			}
+
+			logger.Debug().Str("module", ModuleName).Msg("starting sftp server")
+
+			if err := server.Start(ctx); err != nil {
+				return err
+			}
+
+			logger.Info().Str("address", server.Addr().String()).Int("users", users.Len()).Msg("sftp server started")
+
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			logger.Debug().Str("module", ModuleName).Msg("stopping sftp server")
+
+			return server.Shutdown(ctx)
+		},
+	})
+
+	return server, nil
+}
+
+func subscribe(server *sftpserver.Server, handlers []sftpserver.FileReceivedHandler) {
+	for _, handler := range handlers {
+		server.Subscribe(handler)
+	}
+}
+
+func buildServerOptions(cfg *config.Config) ([]sftpserver.ServerOption, error) {
+	var options []sftpserver.ServerOption
+
+	if address := cfg.GetString(ConfigKey + ".address"); address != "" {
+		options = append(options, sftpserver.WithAddress(address))
+	}
+
+	if root := cfg.GetString(ConfigKey + ".root"); root != "" {
+		options = append(options, sftpserver.WithRoot(root))
+	}
+
+	// host keys, generated if missing when host_keys.generate is enabled
+	paths := cfg.GetStringSlice(ConfigKey + ".host_keys.paths")
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("missing %s.host_keys.paths", ConfigKey)
+	}
+
+	for _, path := range paths {
+		key, err := sftpserver.LoadHostKey(path, cfg.GetBool(ConfigKey+".host_keys.generate"))
+		if err != nil {
+			return nil, err
+		}
+
+		options = append(options, sftpserver.WithHostKeys(key))
+	}
+
+	// timeouts, default 30s for handshakes and 15m for idle connections
+	for _, timeout := range []struct {
+		key   string
+		apply func(time.Duration) sftpserver.ServerOption
+	}{
+		{key: ".timeout", apply: sftpserver.WithTimeout},
+		{key: ".idle_timeout", apply: sftpserver.WithIdleTimeout},
+	} {
+		if cfgTimeout := cfg.GetString(ConfigKey + timeout.key); cfgTimeout != "" {
+			d, err := time.ParseDuration(cfgTimeout)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s%s: %w", ConfigKey, timeout.key, err)
+			}
+
+			options = append(options, timeout.apply(d))
+		}
+	}
+
+	if cfg.IsSet(ConfigKey + ".max_auth_tries") {
+		options = append(options, sftpserver.WithMaxAuthTries(cfg.GetInt(ConfigKey+".max_auth_tries")))
+	}
+
+	if cfg.IsSet(ConfigKey + ".events.temporary_patterns") {
+		options = append(options, sftpserver.WithTemporaryPatterns(cfg.GetStringSlice(ConfigKey+".events.temporary_patterns")...))
+	}
+
+	if cfg.IsSet(ConfigKey + ".events.buffer") {
+		options = append(options, sftpserver.WithEventsBuffer(cfg.GetInt(ConfigKey+".events.buffer")))
+	}
+
+	return options, nil
+}
@@ -0,0 +1,17 @@
+package fxsftpserver
+
+import (
+	"github.com/templatedop/ftptemplate/sftpserver"
+	"go.uber.org/fx"
+)
+
+// AsFileReceivedHandler registers a [sftpserver.FileReceivedHandler] into Fx, subscribed to the sftp server received files.
+func AsFileReceivedHandler(h any) fx.Option {
+	return fx.Provide(
+		fx.Annotate(
+			h,
+			fx.As(new(sftpserver.FileReceivedHandler)),
+			fx.ResultTags(`group:"sftpserver-file-received-handlers"`),
+		),
+	)
+}
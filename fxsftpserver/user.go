@@ -0,0 +1,87 @@
+package fxsftpserver
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/templatedop/ftptemplate/config"
+	"github.com/templatedop/ftptemplate/sftpserver"
+)
+
+const UsersConfigKey = ConfigKey + ".users"
+
+// buildUsers returns the [sftpserver.StaticUserStore] of the modules.sftpserver.users configuration.
+func buildUsers(cfg *config.Config) (*sftpserver.StaticUserStore, error) {
+	var users []*sftpserver.User
+
+	for _, name := range subKeys(cfg, UsersConfigKey) {
+		user, err := buildUser(cfg, name)
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, user)
+	}
+
+	return sftpserver.NewStaticUserStore(users...), nil
+}
+
+func buildUser(cfg *config.Config, name string) (*sftpserver.User, error) {
+	prefix := fmt.Sprintf("%s.%s", UsersConfigKey, name)
+
+	user := &sftpserver.User{
+		Name:         name,
+		Password:     cfg.GetString(prefix + ".password"),
+		PasswordHash: cfg.GetString(prefix + ".password_hash"),
+		Root:         cfg.GetString(prefix + ".root"),
+	}
+
+	// authorized keys, inline and from an authorized_keys file
+	data := []byte(strings.Join(cfg.GetStringSlice(prefix+".authorized_keys"), "\n"))
+
+	if path := cfg.GetString(prefix + ".authorized_keys_path"); path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read authorized keys for sftp server user %s: %w", name, err)
+		}
+
+		data = append(append(data, '\n'), content...)
+	}
+
+	keys, err := sftpserver.ParseAuthorizedKeys(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid authorized keys for sftp server user %s: %w", name, err)
+	}
+
+	user.AuthorizedKeys = keys
+
+	if user.Password == "" && user.PasswordHash == "" && len(user.AuthorizedKeys) == 0 {
+		return nil, fmt.Errorf("missing password or authorized keys for sftp server user %s", name)
+	}
+
+	return user, nil
+}
+
+func subKeys(cfg *config.Config, key string) []string {
+	prefix := key + "."
+	seen := make(map[string]bool)
+
+	var keys []string
+	for _, k := range cfg.AllKeys() {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+
+		child := strings.SplitN(strings.TrimPrefix(k, prefix), ".", 2)[0]
+		if !seen[child] {
+			seen[child] = true
+			keys = append(keys, child)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
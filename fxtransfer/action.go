@@ -0,0 +1,33 @@
+package fxtransfer
+
+import (
+	"path"
+
+	"github.com/templatedop/ftptemplate/transfer"
+)
+
+// FileAction is a [PostAction] applied to the source files of a [TransferJob], once transferred or on failure.
+// The directory and rename templates accept the [transfer.RenderName] placeholders, for example
+// "./archive/{date:2006/01/02}" for date partitioned directories, or "{name}.{date:20060102}.{execId}".
+type FileAction struct {
+	Type   PostAction
+	Dir    string
+	Rename string
+}
+
+// path returns the slash separated path a source file is moved or renamed to, from its path relative to the source
+// directory and the directory it is in: the archive directory keeps the source tree, and renames stay in place.
+func (a FileAction) path(vars transfer.NameVars, entryPath string, currentDir string) string {
+	vars.Name = path.Base(entryPath)
+
+	name := vars.Name
+	if a.Rename != "" {
+		name = transfer.RenderName(a.Rename, vars)
+	}
+
+	if a.Type == RenamePostAction {
+		return path.Join(currentDir, name)
+	}
+
+	return path.Join(transfer.RenderName(a.Dir, vars), path.Dir(entryPath), name)
+}
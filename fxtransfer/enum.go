@@ -0,0 +1,53 @@
+package fxtransfer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PostAction is an enum for the actions applied to source files once transferred, or on failure.
+type PostAction int
+
+const (
+	NoPostAction PostAction = iota
+	ArchivePostAction
+	DeletePostAction
+	RenamePostAction
+)
+
+// String returns a string representation of a [PostAction].
+func (a PostAction) String() string {
+	switch a {
+	case ArchivePostAction:
+		return "archive"
+	case DeletePostAction:
+		return "delete"
+	case RenamePostAction:
+		return "rename"
+	default:
+		return "none"
+	}
+}
+
+// FetchPostAction returns a [PostAction] for a given value, [NoPostAction] if unknown.
+func FetchPostAction(a string) PostAction {
+	action, _ := ParsePostAction(a)
+
+	return action
+}
+
+// ParsePostAction returns a [PostAction] for a given value, [NoPostAction] if empty, or an error if unknown.
+func ParsePostAction(a string) (PostAction, error) {
+	switch strings.ToLower(a) {
+	case "", "none":
+		return NoPostAction, nil
+	case "archive", "move", "quarantine":
+		return ArchivePostAction, nil
+	case "delete", "remove":
+		return DeletePostAction, nil
+	case "rename":
+		return RenamePostAction, nil
+	default:
+		return NoPostAction, fmt.Errorf("unknown post action %q", a)
+	}
+}
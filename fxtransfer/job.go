@@ -0,0 +1,612 @@
+package fxtransfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/templatedop/ftptemplate/fxcron"
+	"github.com/templatedop/ftptemplate/ledger"
+	"github.com/templatedop/ftptemplate/transfer"
+)
+
+// TransferJob is a [fxcron.CronJob] transferring the files of a source directory (or tree) to a destination directory,
+// uploading local files to an endpoint, downloading endpoint files locally, or copying endpoint files to a target endpoint.
+// In sync mode, the destination is mirrored from the source with [transfer.Sync] instead.
+type TransferJob struct {
+	name              string
+	direction         transfer.Direction
+	endpoint          *transfer.Endpoint
+	pool              *transfer.Pool
+	target            *transfer.Endpoint
+	targetPool        *transfer.Pool
+	workers           int
+	ledger            *ledger.Ledger
+	source            string
+	destination       string
+	recursive         bool
+	filter            *transfer.Filter
+	stability         *transfer.StabilityTracker
+	stabilityInterval time.Duration
+	sync              bool
+	syncOptions       []transfer.SyncOption
+	postAction        FileAction
+	failureAction     FileAction
+	compression       transfer.CompressionAlgorithm
+	bundleFormat      transfer.ArchiveFormat
+	bundleName        string
+	extract           bool
+	extractOptions    []transfer.ExtractOption
+	keepArchives      bool
+	stagingDir        string
+	transferOptions   []transfer.TransferOption
+}
+
+// Name returns the [TransferJob] name.
+func (j *TransferJob) Name() string {
+	return j.name
+}
+
+// Run executes the [TransferJob], fanning the files out to the workers sharing the endpoint [transfer.Pool],
+// and returns the joined errors of the failed files.
+// The listing (or the mirroring in sync mode) and each file transfer are retried with the endpoint retry policy.
+func (j *TransferJob) Run(ctx context.Context) error {
+	logger := fxcron.CtxLogger(ctx)
+
+	var tasks []transfer.Task
+	err := j.pool.Retrier().Do(ctx, fmt.Sprintf("listing of %s", j.source), func(ctx context.Context) error {
+		var err error
+		if tasks, err = j.poll(ctx, true); err != nil || j.sync || j.stability == nil || j.stabilityInterval <= 0 {
+			return err
+		}
+
+		// poll again within the execution after the stability interval, without holding the listing session
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(j.stabilityInterval):
+		}
+
+		tasks, err = j.poll(ctx, false)
+
+		return err
+	})
+
+	if err != nil || j.sync {
+		return err
+	}
+
+	executor := transfer.NewExecutor(
+		j.pool,
+		transfer.WithWorkers(j.workers),
+		transfer.WithProgress(func(p transfer.Progress) {
+			logger.Debug().
+				Int("done", p.Done).
+				Int("total", p.Total).
+				Int("failed", p.Failed).
+				Int64("bytes", p.Bytes).
+				Msgf("%s progress %d/%d", j.operation(), p.Done, p.Total)
+		}),
+	)
+
+	report := executor.Execute(ctx, tasks)
+
+	logger.Info().
+		Int("total", report.Total).
+		Int("succeeded", report.Succeeded).
+		Int("failed", report.Failed).
+		Int("skipped", report.Skipped).
+		Int("retries", report.Retries).
+		Int64("bytes", report.Bytes).
+		Dur("duration", report.Duration).
+		Msgf("%s of %d files from %s to %s", j.operation(), report.Total, j.source, j.destination)
+
+	return report.Err()
+}
+
+// poll lists the source directory with a pooled session, and returns the tasks of the selected files, or mirrors it in
+// sync mode. The stale temporary files of the atomic uploads are removed first if required.
+func (j *TransferJob) poll(ctx context.Context, cleanup bool) ([]transfer.Task, error) {
+	logger := fxcron.CtxLogger(ctx)
+
+	client, err := j.pool.Acquire(ctx)
+	if err != nil {
+		var hostKeyErr *transfer.HostKeyError
+		if errors.As(err, &hostKeyErr) {
+			logger.Error().
+				Err(err).
+				Str("host", hostKeyErr.Host).
+				Str("mode", hostKeyErr.Mode.String()).
+				Str("fingerprint", hostKeyErr.Fingerprint).
+				Strs("expected", hostKeyErr.Expected).
+				Msg("sftp host key verification failure")
+		}
+
+		// connection failures are already retried by the pool
+		return nil, transfer.Permanent(err)
+	}
+
+	if cleanup && j.direction == transfer.UploadDirection && j.endpoint.Atomic {
+		removed, err := transfer.CleanupTemporaryFiles(ctx, client, j.destination, j.endpoint.TemporaryPattern, j.endpoint.StaleAfter)
+		if err != nil {
+			logger.Error().Err(err).Msg("error cleaning up stale temporary files")
+		}
+
+		for _, p := range removed {
+			logger.Info().Msgf("removed stale temporary file %s", p)
+		}
+	}
+
+	var tasks []transfer.Task
+	switch {
+	case j.sync:
+		err = j.mirror(ctx, client)
+	case j.targetPool != nil:
+		tasks, err = j.copyTasks(ctx, client)
+	case j.direction == transfer.DownloadDirection:
+		tasks, err = j.downloadTasks(ctx, client)
+	default:
+		tasks, err = j.uploadTasks(ctx)
+	}
+
+	// the listing session is given back before the transfers, to be used by the workers
+	j.pool.Recycle(client, err)
+
+	return tasks, err
+}
+
+func (j *TransferJob) uploadTasks(ctx context.Context) ([]transfer.Task, error) {
+	entries, err := j.list(j.source, func() ([]transfer.WalkEntry, error) {
+		if j.recursive {
+			return transfer.WalkLocal(ctx, j.source)
+		}
+
+		return transfer.ListLocal(j.source)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if j.bundleFormat != transfer.NoArchiveFormat {
+		if len(entries) == 0 {
+			return nil, nil
+		}
+
+		return []transfer.Task{j.bundleTask(ctx, entries)}, nil
+	}
+
+	tasks := make([]transfer.Task, 0, len(entries))
+	for _, entry := range entries {
+		localPath := filepath.Join(j.source, filepath.FromSlash(entry.Path))
+		remotePath := path.Join(j.destination, entry.Path) + j.compression.Extension()
+
+		tasks = append(tasks, transfer.Task{
+			Name: entry.Path,
+			Run: func(ctx context.Context, client transfer.Client) (*transfer.TransferResult, error) {
+				result, err := j.process(ctx, entry, remotePath, func() (*transfer.TransferResult, error) {
+					return client.Upload(ctx, localPath, remotePath, j.transferOptions...)
+				})
+				if err != nil {
+					if j.quarantine(ctx, err) {
+						err = errors.Join(err, transfer.Permanent(j.localAction(ctx, j.failureAction, entry, localPath)))
+					}
+
+					return nil, err
+				}
+
+				if result == nil && !j.postActionPending(ctx, entry, remotePath) {
+					return nil, nil
+				}
+
+				// post action errors are not retried with the succeeded transfer, the post action being applied again at the next execution
+				return result, transfer.Permanent(j.localAction(ctx, j.postAction, entry, localPath))
+			},
+		})
+	}
+
+	return tasks, nil
+}
+
+// bundleTask returns the [transfer.Task] bundling the local files into an archive, with its manifest, in the staging
+// directory, and uploading it under a name rendered from the bundle name template.
+func (j *TransferJob) bundleTask(ctx context.Context, entries []transfer.WalkEntry) transfer.Task {
+	name := transfer.RenderName(j.bundleName, j.nameVars(ctx)) + j.bundleFormat.Extension() + j.compression.Extension()
+
+	remotePath := path.Join(j.destination, name)
+
+	stagingDir := j.stagingDir
+	if stagingDir == "" {
+		stagingDir = os.TempDir()
+	}
+
+	return transfer.Task{
+		Name: name,
+		Run: func(ctx context.Context, client transfer.Client) (*transfer.TransferResult, error) {
+			logger := fxcron.CtxLogger(ctx)
+
+			files := make([]transfer.ArchiveFile, 0, len(entries))
+			for _, entry := range entries {
+				files = append(files, transfer.ArchiveFile{
+					Path:      entry.Path,
+					LocalPath: filepath.Join(j.source, filepath.FromSlash(entry.Path)),
+				})
+			}
+
+			archivePath := filepath.Join(stagingDir, path.Base(name))
+			defer os.Remove(archivePath)
+
+			manifest, err := transfer.CreateArchive(ctx, j.bundleFormat, archivePath, files)
+			if err != nil {
+				logger.Error().Err(err).Msgf("error bundling %d files into %s", len(files), name)
+
+				return nil, err
+			}
+
+			logger.Info().Int("files", len(manifest.Files)).Msgf("bundled %d files into %s", len(manifest.Files), name)
+
+			info, err := os.Stat(archivePath)
+			if err != nil {
+				return nil, err
+			}
+
+			result, err := j.process(ctx, transfer.WalkEntry{Path: name, Info: info}, remotePath, func() (*transfer.TransferResult, error) {
+				return client.Upload(ctx, archivePath, remotePath, j.transferOptions...)
+			})
+			if err != nil {
+				if j.quarantine(ctx, err) {
+					errs := []error{err}
+					for i, entry := range entries {
+						errs = append(errs, transfer.Permanent(j.localAction(ctx, j.failureAction, entry, files[i].LocalPath)))
+					}
+
+					err = errors.Join(errs...)
+				}
+
+				return nil, err
+			}
+
+			if result == nil {
+				return nil, nil
+			}
+
+			var errs []error
+			for i, entry := range entries {
+				errs = append(errs, transfer.Permanent(j.localAction(ctx, j.postAction, entry, files[i].LocalPath)))
+			}
+
+			return result, errors.Join(errs...)
+		},
+	}
+}
+
+// localAction archives, renames or deletes a transferred, or failed, local file.
+func (j *TransferJob) localAction(ctx context.Context, action FileAction, entry transfer.WalkEntry, localPath string) error {
+	logger := fxcron.CtxLogger(ctx)
+
+	var err error
+	switch action.Type {
+	case ArchivePostAction, RenamePostAction:
+		targetPath := filepath.FromSlash(action.path(j.nameVars(ctx), entry.Path, filepath.ToSlash(filepath.Dir(localPath))))
+		if err = transfer.MoveLocal(localPath, targetPath); err == nil {
+			logger.Debug().Msgf("%s of local file %s to %s", action.Type, localPath, targetPath)
+		}
+	case DeletePostAction:
+		err = os.Remove(localPath)
+	}
+
+	if err != nil {
+		logger.Error().Err(err).Msgf("error during %s post action of local file %s", action.Type, localPath)
+	}
+
+	return err
+}
+
+func (j *TransferJob) downloadTasks(ctx context.Context, client transfer.Client) ([]transfer.Task, error) {
+	entries, err := j.list(j.source, func() ([]transfer.WalkEntry, error) {
+		if j.recursive {
+			return client.Walk(ctx, j.source)
+		}
+
+		infos, err := client.List(ctx, j.source)
+
+		return transfer.Entries(infos), err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]transfer.Task, 0, len(entries))
+	for _, entry := range entries {
+		remotePath := path.Join(j.source, entry.Path)
+		localPath := strings.TrimSuffix(filepath.Join(j.destination, filepath.FromSlash(entry.Path)), j.compression.Extension())
+
+		tasks = append(tasks, transfer.Task{
+			Name: entry.Path,
+			Run: func(ctx context.Context, client transfer.Client) (*transfer.TransferResult, error) {
+				result, err := j.process(ctx, entry, remotePath, func() (*transfer.TransferResult, error) {
+					result, err := client.Download(ctx, remotePath, localPath, j.transferOptions...)
+					if err != nil {
+						return nil, err
+					}
+
+					return result, j.extractArchive(ctx, localPath)
+				})
+				if err != nil {
+					if j.quarantine(ctx, err) {
+						err = errors.Join(err, transfer.Permanent(j.remoteAction(ctx, client, j.failureAction, entry, remotePath)))
+					}
+
+					return nil, err
+				}
+
+				if result == nil && !j.postActionPending(ctx, entry, remotePath) {
+					return nil, nil
+				}
+
+				// post action errors are not retried with the succeeded transfer, the post action being applied again at the next execution
+				return result, transfer.Permanent(j.remoteAction(ctx, client, j.postAction, entry, remotePath))
+			},
+		})
+	}
+
+	return tasks, nil
+}
+
+func (j *TransferJob) copyTasks(ctx context.Context, client transfer.Client) ([]transfer.Task, error) {
+	entries, err := j.list(j.source, func() ([]transfer.WalkEntry, error) {
+		if j.recursive {
+			return client.Walk(ctx, j.source)
+		}
+
+		infos, err := client.List(ctx, j.source)
+
+		return transfer.Entries(infos), err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]transfer.Task, 0, len(entries))
+	for _, entry := range entries {
+		sourcePath := path.Join(j.source, entry.Path)
+		destinationPath := path.Join(j.destination, entry.Path) + j.compression.Extension()
+
+		tasks = append(tasks, transfer.Task{
+			Name: entry.Path,
+			Run: func(ctx context.Context, client transfer.Client) (*transfer.TransferResult, error) {
+				result, err := j.process(ctx, entry, sourcePath, func() (*transfer.TransferResult, error) {
+					target, err := j.targetPool.Acquire(ctx)
+					if err != nil {
+						return nil, err
+					}
+
+					result, err := transfer.Copy(ctx, client, sourcePath, target, destinationPath, j.transferOptions...)
+					j.targetPool.Recycle(target, err)
+
+					return result, err
+				})
+				if err != nil {
+					if j.quarantine(ctx, err) {
+						err = errors.Join(err, transfer.Permanent(j.remoteAction(ctx, client, j.failureAction, entry, sourcePath)))
+					}
+
+					return nil, err
+				}
+
+				if result == nil && !j.postActionPending(ctx, entry, sourcePath) {
+					return nil, nil
+				}
+
+				// post action errors are not retried with the succeeded transfer, the post action being applied again at the next execution
+				return result, transfer.Permanent(j.remoteAction(ctx, client, j.postAction, entry, sourcePath))
+			},
+		})
+	}
+
+	return tasks, nil
+}
+
+// extractArchive extracts a downloaded archive into its directory, if extraction is enabled and the file is an archive.
+// The archive is removed once extracted, unless kept.
+func (j *TransferJob) extractArchive(ctx context.Context, localPath string) error {
+	format := transfer.DetectArchiveFormat(localPath)
+	if !j.extract || format == transfer.NoArchiveFormat {
+		return nil
+	}
+
+	paths, err := transfer.ExtractArchive(ctx, format, localPath, filepath.Dir(localPath), j.extractOptions...)
+	if err != nil {
+		return err
+	}
+
+	fxcron.CtxLogger(ctx).Info().Int("files", len(paths)).Msgf("extracted %d files from %s", len(paths), localPath)
+
+	if j.keepArchives {
+		return nil
+	}
+
+	return os.Remove(localPath)
+}
+
+// remoteAction archives, renames or deletes a transferred, or failed, endpoint file.
+func (j *TransferJob) remoteAction(ctx context.Context, client transfer.Client, action FileAction, entry transfer.WalkEntry, remotePath string) error {
+	logger := fxcron.CtxLogger(ctx)
+
+	var err error
+	switch action.Type {
+	case ArchivePostAction, RenamePostAction:
+		targetPath := action.path(j.nameVars(ctx), entry.Path, path.Dir(remotePath))
+		if err = client.Mkdir(ctx, path.Dir(targetPath)); err == nil {
+			err = client.Move(ctx, remotePath, targetPath)
+		}
+
+		if err == nil {
+			logger.Debug().Msgf("%s of remote file %s to %s", action.Type, remotePath, targetPath)
+		}
+	case DeletePostAction:
+		err = client.Remove(ctx, remotePath)
+	}
+
+	if err != nil {
+		logger.Error().Err(err).Msgf("error during %s post action of remote file %s", action.Type, remotePath)
+	}
+
+	return err
+}
+
+// postActionPending returns true if a file skipped as already processed still has its post action to apply: the ledger
+// recorded its transfer as succeeded, but it is still listed at the source, its post action having failed.
+func (j *TransferJob) postActionPending(ctx context.Context, entry transfer.WalkEntry, remotePath string) bool {
+	if j.ledger == nil || j.postAction.Type == NoPostAction {
+		return false
+	}
+
+	succeeded, err := j.ledger.Succeeded(ctx, j.ledgerFile(entry, remotePath))
+	if err != nil {
+		fxcron.CtxLogger(ctx).Error().Err(err).Msgf("error checking ledger status of file %s", entry.Path)
+
+		return false
+	}
+
+	if succeeded {
+		fxcron.CtxLogger(ctx).Info().Msgf("retrying %s post action of already processed file %s", j.postAction.Type, entry.Path)
+	}
+
+	return succeeded
+}
+
+// quarantine returns true if the failure action applies to a transfer error: only permanent errors, like rejected
+// signatures or corrupted archives, the files failing with transient ones being retried at the next execution.
+func (j *TransferJob) quarantine(ctx context.Context, err error) bool {
+	return j.failureAction.Type != NoPostAction && ctx.Err() == nil && !transfer.IsRetryable(err)
+}
+
+// nameVars returns the [transfer.NameVars] of the execution, to render the file names templates.
+func (j *TransferJob) nameVars(ctx context.Context) transfer.NameVars {
+	return transfer.NameVars{
+		Job:         j.name,
+		ExecutionId: fxcron.CtxCronJobExecutionId(ctx),
+		Time:        time.Now(),
+	}
+}
+
+// operation returns the name of the [TransferJob] transfers, for logging.
+func (j *TransferJob) operation() string {
+	if j.targetPool != nil {
+		return "copy"
+	}
+
+	return j.direction.String()
+}
+
+// mirror synchronizes the destination tree from the source tree.
+func (j *TransferJob) mirror(ctx context.Context, client transfer.Client) error {
+	logger := fxcron.CtxLogger(ctx)
+
+	options := append([]transfer.SyncOption{
+		transfer.WithSyncFilter(j.filter),
+		transfer.WithSyncTransferOptions(j.transferOptions...),
+	}, j.syncOptions...)
+
+	result, err := transfer.Sync(ctx, client, j.direction, j.source, j.destination, options...)
+	if result != nil {
+		for _, action := range result.Actions {
+			logger.Info().
+				Str("action", action.Kind.String()).
+				Str("path", action.Path).
+				Str("reason", action.Reason).
+				Bool("dryRun", result.DryRun).
+				Msgf("sync %s of file %s", action.Kind, action.Path)
+		}
+
+		logger.Info().
+			Int("actions", len(result.Actions)).
+			Int("transferred", len(result.Transfers)).
+			Int("unchanged", result.Unchanged).
+			Bool("dryRun", result.DryRun).
+			Msgf("sync %s of %s to %s", j.direction, j.source, j.destination)
+	}
+
+	return err
+}
+
+// list returns the entries of the source directory selected by the [TransferJob] filter, and stable if required:
+// with a stability interval, the entries are stable on the second poll of the execution.
+func (j *TransferJob) list(dir string, fn func() ([]transfer.WalkEntry, error)) ([]transfer.WalkEntry, error) {
+	entries, err := fn()
+	if err != nil {
+		return nil, err
+	}
+
+	selected := j.filter.Apply(entries)
+	if j.stability == nil {
+		return selected, nil
+	}
+
+	return j.stability.Stable(dir, selected), nil
+}
+
+// process transfers a file, at most once if the [TransferJob] has a [ledger.Ledger].
+// It returns a nil [transfer.TransferResult] without error if the file was already processed.
+func (j *TransferJob) process(ctx context.Context, entry transfer.WalkEntry, remotePath string, fn func() (*transfer.TransferResult, error)) (*transfer.TransferResult, error) {
+	logger := fxcron.CtxLogger(ctx)
+
+	var result *transfer.TransferResult
+	run := func() (string, error) {
+		var err error
+		result, err = fn()
+		if err != nil {
+			return "", err
+		}
+
+		logger.Info().
+			Str("source", result.Source).
+			Str("destination", result.Destination).
+			Int64("bytes", result.Bytes).
+			Int64("offset", result.Offset).
+			Dur("duration", result.Duration).
+			Str("algorithm", result.Algorithm.String()).
+			Str("digest", result.Digest).
+			Bool("verified", result.Verified).
+			Msgf("%s of file %s", j.operation(), result.Source)
+
+		return result.Digest, nil
+	}
+
+	var processed bool
+	var err error
+	if j.ledger == nil {
+		processed = true
+		_, err = run()
+	} else {
+		processed, err = j.ledger.Process(ctx, j.ledgerFile(entry, remotePath), fxcron.CtxCronJobExecutionId(ctx), run)
+	}
+
+	if err != nil {
+		logger.Error().Err(err).Msgf("error during %s of file %s", j.operation(), entry.Path)
+
+		return nil, err
+	}
+
+	if !processed {
+		logger.Debug().Msgf("skipping already processed %s file %s", j.operation(), entry.Path)
+	}
+
+	return result, nil
+}
+
+// ledgerFile returns the [ledger.File] of an entry, identified by its endpoint path.
+func (j *TransferJob) ledgerFile(entry transfer.WalkEntry, remotePath string) ledger.File {
+	return ledger.File{
+		Endpoint:  j.endpoint.Name,
+		Direction: ledger.FetchDirection(j.direction.String()),
+		Path:      remotePath,
+		Size:      entry.Info.Size(),
+		ModTime:   entry.Info.ModTime(),
+	}
+}
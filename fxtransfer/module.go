@@ -0,0 +1,18 @@
+package fxtransfer
+
+import (
+	"github.com/templatedop/ftptemplate/fxcron"
+	"go.uber.org/fx"
+)
+
+const (
+	ModuleName = "transfer"
+)
+
+// FxTransferModule is the [Fx] transfer module, registering the [TransferJob] declared in config as cron jobs.
+//
+// [Fx]: https://github.com/uber-go/fx
+var FxTransferModule = fx.Module(
+	ModuleName,
+	fxcron.AsCronJobProvider(NewFxTransferJobProvider),
+)
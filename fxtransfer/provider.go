@@ -0,0 +1,405 @@
+package fxtransfer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/templatedop/ftptemplate/config"
+	"github.com/templatedop/ftptemplate/fxcron"
+	"github.com/templatedop/ftptemplate/ledger"
+	"github.com/templatedop/ftptemplate/transfer"
+	"go.uber.org/fx"
+)
+
+const (
+	JobsConfigKey     = "modules.transfer.jobs"
+	DefaultBundleName = "{job}-{date:20060102150405}"
+)
+
+// TransferJobConfig is the config of a [TransferJob], as declared under modules.transfer.jobs.
+type TransferJobConfig struct {
+	Name         string                     `mapstructure:"name"`
+	Schedule     string                     `mapstructure:"schedule"`
+	Timeout      time.Duration              `mapstructure:"timeout"`
+	Direction    string                     `mapstructure:"direction"`
+	Endpoint     string                     `mapstructure:"endpoint"`
+	Target       string                     `mapstructure:"target_endpoint"`
+	Source       string                     `mapstructure:"source"`
+	Destination  string                     `mapstructure:"destination"`
+	Include      []string                   `mapstructure:"include"`
+	Exclude      []string                   `mapstructure:"exclude"`
+	IncludeRegex []string                   `mapstructure:"include_regex"`
+	ExcludeRegex []string                   `mapstructure:"exclude_regex"`
+	MinAge       time.Duration              `mapstructure:"min_age"`
+	MinSize      int64                      `mapstructure:"min_size"`
+	MaxSize      int64                      `mapstructure:"max_size"`
+	Stability    TransferJobStabilityConfig `mapstructure:"stability"`
+	Recursive    bool                       `mapstructure:"recursive"`
+	Sync         TransferJobSyncConfig      `mapstructure:"sync"`
+	PostAction   TransferJobPostConfig      `mapstructure:"post_action"`
+	Compression  TransferJobCompressConfig  `mapstructure:"compression"`
+	Archive      TransferJobArchiveConfig   `mapstructure:"archive"`
+	Options      TransferJobOptionsConfig   `mapstructure:"options"`
+}
+
+// TransferJobStabilityConfig is the config of a [TransferJob] stable files check.
+// Without interval, files are picked up once unchanged between two executions, otherwise between two polls of the same execution.
+type TransferJobStabilityConfig struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Interval time.Duration `mapstructure:"interval"`
+}
+
+// TransferJobSyncConfig is the config of a [TransferJob] sync mode, mirroring the destination tree from the source tree.
+type TransferJobSyncConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Delete  bool   `mapstructure:"delete"`
+	DryRun  bool   `mapstructure:"dry_run"`
+	Compare string `mapstructure:"compare"`
+}
+
+// TransferJobPostConfig is the config of a [TransferJob] post action, applied to the source files once transferred,
+// and of its failure action, applied to the source files failing with a permanent error.
+type TransferJobPostConfig struct {
+	Type       string                   `mapstructure:"type"`
+	ArchiveDir string                   `mapstructure:"archive_dir"`
+	Rename     string                   `mapstructure:"rename"`
+	Failure    TransferJobFailureConfig `mapstructure:"failure"`
+}
+
+// TransferJobFailureConfig is the config of a [TransferJob] failure action, quarantining the files in their own directory.
+type TransferJobFailureConfig struct {
+	Type          string `mapstructure:"type"`
+	QuarantineDir string `mapstructure:"quarantine_dir"`
+	Rename        string `mapstructure:"rename"`
+}
+
+// TransferJobCompressConfig is the config of a [TransferJob] per file compression: uploaded and copied files are
+// compressed with the algorithm extension appended to their name, downloaded files are decompressed with it removed.
+type TransferJobCompressConfig struct {
+	Algorithm string `mapstructure:"algorithm"`
+}
+
+// TransferJobArchiveConfig is the config of a [TransferJob] archives: uploaded files are bundled into a single archive
+// named from a template, with a manifest, and downloaded archives are extracted into the destination directory.
+type TransferJobArchiveConfig struct {
+	Format      string `mapstructure:"format"`
+	Name        string `mapstructure:"name"`
+	Extract     bool   `mapstructure:"extract"`
+	Keep        bool   `mapstructure:"keep"`
+	MaxFileSize int64  `mapstructure:"max_file_size"`
+	MaxSize     int64  `mapstructure:"max_size"`
+}
+
+// TransferJobOptionsConfig is the config of a [TransferJob] options, overriding the endpoint ones when set.
+type TransferJobOptionsConfig struct {
+	Ledger     *bool  `mapstructure:"ledger"`
+	Resume     *bool  `mapstructure:"resume"`
+	Atomic     *bool  `mapstructure:"atomic"`
+	Workers    int    `mapstructure:"workers"`
+	StagingDir string `mapstructure:"staging_dir"`
+}
+
+// TransferJobProvider is a [fxcron.CronJobProvider] resolving the [TransferJob] declared in config.
+type TransferJobProvider struct {
+	config    *config.Config
+	endpoints *transfer.EndpointRegistry
+	pools     *transfer.PoolRegistry
+	ledger    *ledger.Ledger
+}
+
+// FxTransferJobProviderParam allows injection of the required dependencies in [NewFxTransferJobProvider].
+type FxTransferJobProviderParam struct {
+	fx.In
+	Config    *config.Config
+	Endpoints *transfer.EndpointRegistry
+	Pools     *transfer.PoolRegistry
+	Ledger    *ledger.Ledger `optional:"true"`
+}
+
+// NewFxTransferJobProvider returns a new [TransferJobProvider].
+func NewFxTransferJobProvider(p FxTransferJobProviderParam) *TransferJobProvider {
+	return &TransferJobProvider{
+		config:    p.Config,
+		endpoints: p.Endpoints,
+		pools:     p.Pools,
+		ledger:    p.Ledger,
+	}
+}
+
+// CronJobs returns the [TransferJob] declared in config, resolved with their schedules.
+func (p *TransferJobProvider) CronJobs() ([]*fxcron.ResolvedCronJob, error) {
+	var jobsConfig []TransferJobConfig
+	if err := p.config.UnmarshalKey(JobsConfigKey, &jobsConfig); err != nil {
+		return nil, fmt.Errorf("invalid %s config: %w", JobsConfigKey, err)
+	}
+
+	names := map[string]bool{}
+
+	var resolvedCronJobs []*fxcron.ResolvedCronJob
+	for i, jobConfig := range jobsConfig {
+		if jobConfig.Name == "" {
+			return nil, fmt.Errorf("missing name for transfer job at index %d", i)
+		}
+
+		if names[jobConfig.Name] {
+			return nil, fmt.Errorf("duplicate transfer job with name %s", jobConfig.Name)
+		}
+		names[jobConfig.Name] = true
+
+		if jobConfig.Schedule == "" {
+			return nil, fmt.Errorf("missing schedule for transfer job %s", jobConfig.Name)
+		}
+
+		job, err := p.buildTransferJob(jobConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		if jobConfig.Timeout < 0 {
+			return nil, fmt.Errorf("invalid timeout for transfer job %s: %s", jobConfig.Name, jobConfig.Timeout)
+		}
+
+		resolvedCronJob := fxcron.NewResolvedCronJob(job, jobConfig.Schedule)
+		if jobConfig.Timeout > 0 {
+			resolvedCronJob.WithExecutionOptions(fxcron.WithTimeout(jobConfig.Timeout))
+		}
+
+		resolvedCronJobs = append(resolvedCronJobs, resolvedCronJob)
+	}
+
+	return resolvedCronJobs, nil
+}
+
+func (p *TransferJobProvider) buildTransferJob(c TransferJobConfig) (*TransferJob, error) {
+	endpoint, err := p.endpoints.Get(c.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint for transfer job %s: %w", c.Name, err)
+	}
+
+	pool, err := p.pools.Get(c.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint for transfer job %s: %w", c.Name, err)
+	}
+
+	filter, err := transfer.NewFilter(
+		transfer.WithInclude(c.Include...),
+		transfer.WithExclude(c.Exclude...),
+		transfer.WithIncludeRegex(c.IncludeRegex...),
+		transfer.WithExcludeRegex(c.ExcludeRegex...),
+		transfer.WithMinAge(c.MinAge),
+		transfer.WithSizeBounds(c.MinSize, c.MaxSize),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter for transfer job %s: %w", c.Name, err)
+	}
+
+	direction, err := transfer.ParseDirection(c.Direction)
+	if err != nil {
+		return nil, fmt.Errorf("invalid direction for transfer job %s: %w", c.Name, err)
+	}
+
+	// copy from the endpoint to a target endpoint, downloading from the first and uploading to the second
+	var target *transfer.Endpoint
+	var targetPool *transfer.Pool
+	if c.Target != "" {
+		if c.Direction != "" && direction != transfer.DownloadDirection {
+			return nil, fmt.Errorf("direction %s is not supported with target_endpoint for transfer job %s", c.Direction, c.Name)
+		}
+
+		if c.Sync.Enabled {
+			return nil, fmt.Errorf("sync is not supported with target_endpoint for transfer job %s", c.Name)
+		}
+
+		if target, err = p.endpoints.Get(c.Target); err != nil {
+			return nil, fmt.Errorf("invalid target_endpoint for transfer job %s: %w", c.Name, err)
+		}
+
+		if targetPool, err = p.pools.Get(c.Target); err != nil {
+			return nil, fmt.Errorf("invalid target_endpoint for transfer job %s: %w", c.Name, err)
+		}
+
+		direction = transfer.DownloadDirection
+	}
+
+	// the remote sides default to the endpoint directory named after the direction
+	source, destination := c.Source, c.Destination
+	if direction == transfer.UploadDirection && destination == "" {
+		destination = endpoint.Dir(direction.String())
+	}
+	if direction == transfer.DownloadDirection && source == "" {
+		source = endpoint.Dir(direction.String())
+	}
+	if target != nil && destination == "" {
+		destination = target.Dir(transfer.UploadDirection.String())
+	}
+
+	if source == "" || destination == "" {
+		return nil, fmt.Errorf("missing source or destination for transfer job %s", c.Name)
+	}
+
+	postActionType, err := ParsePostAction(c.PostAction.Type)
+	if err != nil {
+		return nil, fmt.Errorf("invalid post_action.type for transfer job %s: %w", c.Name, err)
+	}
+
+	failureActionType, err := ParsePostAction(c.PostAction.Failure.Type)
+	if err != nil {
+		return nil, fmt.Errorf("invalid post_action.failure.type for transfer job %s: %w", c.Name, err)
+	}
+
+	var syncOptions []transfer.SyncOption
+	if c.Sync.Enabled {
+		if postActionType != NoPostAction || failureActionType != NoPostAction {
+			return nil, fmt.Errorf("post_action is not supported in sync mode for transfer job %s", c.Name)
+		}
+
+		compareMode, err := transfer.ParseCompareMode(c.Sync.Compare)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sync.compare for transfer job %s: %w", c.Name, err)
+		}
+
+		syncOptions = append(syncOptions, transfer.WithCompare(compareMode))
+
+		if c.Sync.Delete {
+			syncOptions = append(syncOptions, transfer.WithDelete())
+		}
+
+		if c.Sync.DryRun {
+			syncOptions = append(syncOptions, transfer.WithDryRun())
+		}
+	}
+
+	postAction := FileAction{
+		Type:   postActionType,
+		Dir:    c.PostAction.ArchiveDir,
+		Rename: c.PostAction.Rename,
+	}
+	failureAction := FileAction{
+		Type:   failureActionType,
+		Dir:    c.PostAction.Failure.QuarantineDir,
+		Rename: c.PostAction.Failure.Rename,
+	}
+
+	switch {
+	case postAction.Type == ArchivePostAction && postAction.Dir == "":
+		return nil, fmt.Errorf("missing post_action.archive_dir for transfer job %s", c.Name)
+	case postAction.Type == RenamePostAction && postAction.Rename == "":
+		return nil, fmt.Errorf("missing post_action.rename for transfer job %s", c.Name)
+	case failureAction.Type == ArchivePostAction && failureAction.Dir == "":
+		return nil, fmt.Errorf("missing post_action.failure.quarantine_dir for transfer job %s", c.Name)
+	case failureAction.Type == RenamePostAction && failureAction.Rename == "":
+		return nil, fmt.Errorf("missing post_action.failure.rename for transfer job %s", c.Name)
+	}
+
+	// per file compression, and archives bundled on upload or extracted on download
+	compression := transfer.FetchCompressionAlgorithm(c.Compression.Algorithm)
+	bundleFormat := transfer.FetchArchiveFormat(c.Archive.Format)
+
+	switch {
+	case c.Compression.Algorithm != "" && compression == transfer.NoCompressionAlgorithm && !strings.EqualFold(c.Compression.Algorithm, "none"):
+		return nil, fmt.Errorf("invalid compression.algorithm %s for transfer job %s", c.Compression.Algorithm, c.Name)
+	case c.Archive.Format != "" && bundleFormat == transfer.NoArchiveFormat && !strings.EqualFold(c.Archive.Format, "none"):
+		return nil, fmt.Errorf("invalid archive.format %s for transfer job %s", c.Archive.Format, c.Name)
+	case c.Sync.Enabled && (compression != transfer.NoCompressionAlgorithm || bundleFormat != transfer.NoArchiveFormat || c.Archive.Extract):
+		return nil, fmt.Errorf("compression and archive are not supported in sync mode for transfer job %s", c.Name)
+	case bundleFormat != transfer.NoArchiveFormat && (direction != transfer.UploadDirection || target != nil):
+		return nil, fmt.Errorf("archive.format is only supported for upload transfer job %s", c.Name)
+	case bundleFormat != transfer.NoArchiveFormat && postActionType != ArchivePostAction && postActionType != DeletePostAction:
+		// the bundled files would otherwise be bundled again at each execution
+		return nil, fmt.Errorf("archive.format requires an archive or delete post_action for transfer job %s", c.Name)
+	case c.Archive.Extract && (direction != transfer.DownloadDirection || target != nil):
+		return nil, fmt.Errorf("archive.extract is only supported for download transfer job %s", c.Name)
+	}
+
+	bundleName := c.Archive.Name
+	if bundleName == "" {
+		bundleName = DefaultBundleName
+	}
+
+	// extracted archives maximum sizes, default 1GiB per file and 4GiB in total
+	var extractOptions []transfer.ExtractOption
+	if c.Archive.MaxFileSize > 0 {
+		extractOptions = append(extractOptions, transfer.WithExtractMaxFileSize(c.Archive.MaxFileSize))
+	}
+	if c.Archive.MaxSize > 0 {
+		extractOptions = append(extractOptions, transfer.WithExtractMaxSize(c.Archive.MaxSize))
+	}
+
+	var transferOptions []transfer.TransferOption
+	if compression != transfer.NoCompressionAlgorithm {
+		if direction == transfer.UploadDirection || target != nil {
+			stage, err := transfer.NewCompressStage(compression)
+			if err != nil {
+				return nil, err
+			}
+
+			transferOptions = append(transferOptions, transfer.WithUploadStages(stage))
+		} else {
+			stage, err := transfer.NewDecompressStage(compression)
+			if err != nil {
+				return nil, err
+			}
+
+			transferOptions = append(transferOptions, transfer.WithDownloadStages(stage))
+		}
+	}
+
+	if c.Options.Resume != nil {
+		if *c.Options.Resume {
+			transferOptions = append(transferOptions, transfer.WithResume(endpoint.ResumeVerify))
+		} else {
+			transferOptions = append(transferOptions, transfer.WithoutResume())
+		}
+	}
+	if c.Options.Atomic != nil {
+		if *c.Options.Atomic {
+			transferOptions = append(transferOptions, transfer.WithAtomic(endpoint.TemporaryPattern))
+		} else {
+			transferOptions = append(transferOptions, transfer.WithoutAtomic())
+		}
+	}
+
+	if c.Options.StagingDir != "" {
+		transferOptions = append(transferOptions, transfer.WithStagingDir(c.Options.StagingDir))
+	}
+
+	jobLedger := p.ledger
+	if c.Options.Ledger != nil && !*c.Options.Ledger {
+		jobLedger = nil
+	}
+
+	var stability *transfer.StabilityTracker
+	if c.Stability.Enabled {
+		stability = transfer.NewStabilityTracker()
+	}
+
+	return &TransferJob{
+		name:              c.Name,
+		direction:         direction,
+		endpoint:          endpoint,
+		pool:              pool,
+		target:            target,
+		targetPool:        targetPool,
+		workers:           c.Options.Workers,
+		ledger:            jobLedger,
+		source:            source,
+		destination:       destination,
+		recursive:         c.Recursive || c.Sync.Enabled,
+		filter:            filter,
+		stability:         stability,
+		stabilityInterval: c.Stability.Interval,
+		sync:              c.Sync.Enabled,
+		syncOptions:       syncOptions,
+		postAction:        postAction,
+		failureAction:     failureAction,
+		compression:       compression,
+		bundleFormat:      bundleFormat,
+		bundleName:        bundleName,
+		extract:           c.Archive.Extract,
+		extractOptions:    extractOptions,
+		keepArchives:      c.Archive.Keep,
+		stagingDir:        c.Options.StagingDir,
+		transferOptions:   transferOptions,
+	}, nil
+}
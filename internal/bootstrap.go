@@ -1,13 +1,27 @@
 package internal
 
 import (
-	"github.com/templatedop/ftptemplate/fxdb"
 	"github.com/templatedop/ftptemplate/fxcore"
 	"github.com/templatedop/ftptemplate/fxcron"
+	"github.com/templatedop/ftptemplate/fxcronadmin"
+	"github.com/templatedop/ftptemplate/fxcronhistory"
+	"github.com/templatedop/ftptemplate/fxcronlock"
+	"github.com/templatedop/ftptemplate/fxdb"
+	"github.com/templatedop/ftptemplate/fxledger"
+	"github.com/templatedop/ftptemplate/fxsftp"
+	"github.com/templatedop/ftptemplate/fxsftpserver"
+	"github.com/templatedop/ftptemplate/fxtransfer"
 )
 
 var Bootstrapper = fxcore.NewBootstrapper().WithOptions(
 	fxdb.FxDBModule,
+	fxledger.FxLedgerModule,
+	fxsftp.FxSftpModule,
+	fxsftpserver.FxSftpServerModule,
+	fxcronlock.FxCronLockModule,
+	fxcronhistory.FxCronHistoryModule,
 	fxcron.FxCronModule,
+	fxcronadmin.FxCronAdminModule,
+	fxtransfer.FxTransferModule,
 	Register(),
 )
@@ -0,0 +1,28 @@
+package cron
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/templatedop/ftptemplate/transfer"
+)
+
+func listLocalFiles(directory string) ([]fs.DirEntry, error) {
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return nil, err
+	}
+	var files []fs.DirEntry
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			files = append(files, entry)
+		}
+	}
+	return files, nil
+}
+
+func moveLocalFile(sourcePath, destinationPath, filename string) error {
+	// Move the file, copying it across filesystems
+	return transfer.MoveLocal(filepath.Join(sourcePath, filename), filepath.Join(destinationPath, filename))
+}
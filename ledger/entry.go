@@ -0,0 +1,36 @@
+package ledger
+
+import (
+	"time"
+)
+
+// File identifies a file version in the [Ledger]: a file is processed once per endpoint, direction, path, size and modification time.
+type File struct {
+	Endpoint  string
+	Direction Direction
+	Path      string
+	Size      int64
+	ModTime   time.Time
+}
+
+// Entry is a [File] record of the [Ledger].
+type Entry struct {
+	Id          int64     `db:"id"`
+	Endpoint    string    `db:"endpoint"`
+	Direction   string    `db:"direction"`
+	Path        string    `db:"path"`
+	Size        int64     `db:"size"`
+	ModTime     time.Time `db:"mtime"`
+	Checksum    string    `db:"checksum"`
+	Status      string    `db:"status"`
+	ExecutionId string    `db:"execution_id"`
+	Attempts    int       `db:"attempts"`
+	Error       string    `db:"error"`
+	CreatedAt   time.Time `db:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at"`
+}
+
+// modTime returns the modification time as recorded in the [Ledger]: SFTP servers only expose seconds, in UTC.
+func (f File) modTime() time.Time {
+	return f.ModTime.UTC().Truncate(time.Second)
+}
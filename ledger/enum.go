@@ -0,0 +1,64 @@
+package ledger
+
+import "strings"
+
+// Direction is an enum for the transfer directions recorded in the [Ledger].
+type Direction int
+
+const (
+	Upload Direction = iota
+	Download
+)
+
+// String returns a string representation of a [Direction].
+func (d Direction) String() string {
+	switch d {
+	case Download:
+		return "download"
+	default:
+		return "upload"
+	}
+}
+
+// FetchDirection returns a [Direction] for a given value.
+func FetchDirection(d string) Direction {
+	switch strings.ToLower(d) {
+	case "download":
+		return Download
+	default:
+		return Upload
+	}
+}
+
+// Status is an enum for the file statuses recorded in the [Ledger].
+type Status int
+
+const (
+	InProgress Status = iota
+	Succeeded
+	Failed
+)
+
+// String returns a string representation of a [Status].
+func (s Status) String() string {
+	switch s {
+	case Succeeded:
+		return "succeeded"
+	case Failed:
+		return "failed"
+	default:
+		return "in_progress"
+	}
+}
+
+// FetchStatus returns a [Status] for a given value.
+func FetchStatus(s string) Status {
+	switch strings.ToLower(s) {
+	case "succeeded":
+		return Succeeded
+	case "failed":
+		return Failed
+	default:
+		return InProgress
+	}
+}
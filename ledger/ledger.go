@@ -0,0 +1,211 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/templatedop/ftptemplate/db"
+	"github.com/templatedop/ftptemplate/repo"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS %[1]s (
+	id           BIGSERIAL PRIMARY KEY,
+	endpoint     TEXT NOT NULL,
+	direction    TEXT NOT NULL,
+	path         TEXT NOT NULL,
+	size         BIGINT NOT NULL,
+	mtime        TIMESTAMPTZ NOT NULL,
+	checksum     TEXT NOT NULL DEFAULT '',
+	status       TEXT NOT NULL,
+	execution_id TEXT NOT NULL,
+	attempts     INTEGER NOT NULL DEFAULT 1,
+	error        TEXT NOT NULL DEFAULT '',
+	created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+	UNIQUE (endpoint, direction, path, size, mtime)
+);
+CREATE INDEX IF NOT EXISTS %[1]s_status_idx ON %[1]s (endpoint, direction, status);
+`
+
+var columns = []string{
+	"id", "endpoint", "direction", "path", "size", "mtime", "checksum",
+	"status", "execution_id", "attempts", "error", "created_at", "updated_at",
+}
+
+// Ledger records transferred files in a Postgres table, to process each file version exactly once.
+//
+// A file is claimed before its transfer, and marked as succeeded or failed after it:
+// succeeded files are never claimed again, failed files (and in progress files left by interrupted executions) are retried.
+type Ledger struct {
+	db      *db.DB
+	options Options
+}
+
+// NewLedger returns a new [Ledger], for a [db.DB] and a list of [LedgerOption].
+func NewLedger(db *db.DB, options ...LedgerOption) *Ledger {
+	appliedOpts := DefaultLedgerOptions()
+	for _, opt := range options {
+		opt(&appliedOpts)
+	}
+
+	return &Ledger{
+		db:      db,
+		options: appliedOpts,
+	}
+}
+
+// Table returns the ledger table name.
+func (l *Ledger) Table() string {
+	return l.options.Table
+}
+
+// Migrate creates the ledger table and its indexes if they do not exist.
+func (l *Ledger) Migrate(ctx context.Context) error {
+	if _, err := l.db.Exec(ctx, fmt.Sprintf(schema, l.options.Table)); err != nil {
+		return fmt.Errorf("unable to migrate ledger table %s: %w", l.options.Table, err)
+	}
+
+	return nil
+}
+
+// Claim marks a [File] as in progress for an execution id, and returns true if the file has to be processed.
+// It returns false if the file already succeeded, or is in progress in another execution.
+func (l *Ledger) Claim(ctx context.Context, f File, executionId string) (bool, error) {
+	query := repo.Psql.
+		Insert(l.options.Table+" AS l").
+		Columns("endpoint", "direction", "path", "size", "mtime", "status", "execution_id").
+		Values(f.Endpoint, f.Direction.String(), f.Path, f.Size, f.modTime(), InProgress.String(), executionId).
+		Suffix(
+			`ON CONFLICT (endpoint, direction, path, size, mtime) DO UPDATE
+			SET status = EXCLUDED.status, execution_id = EXCLUDED.execution_id, attempts = l.attempts + 1, error = '', updated_at = now()
+			WHERE l.status = ? OR (l.status = ? AND l.updated_at < ?)
+			RETURNING l.id`,
+			Failed.String(),
+			InProgress.String(),
+			time.Now().Add(-l.options.StaleAfter),
+		)
+
+	_, err := repo.InsertReturning(ctx, l.db, query, pgx.RowTo[int64])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("unable to claim ledger file %s: %w", f.Path, err)
+	}
+
+	return true, nil
+}
+
+// Succeed marks a claimed [File] as succeeded, with its checksum.
+func (l *Ledger) Succeed(ctx context.Context, f File, executionId string, checksum string) error {
+	return l.complete(ctx, f, executionId, Succeeded, map[string]any{"checksum": checksum})
+}
+
+// Fail marks a claimed [File] as failed, with its error, to be claimed again by the next executions.
+func (l *Ledger) Fail(ctx context.Context, f File, executionId string, cause error) error {
+	message := ""
+	if cause != nil {
+		message = cause.Error()
+	}
+
+	return l.complete(ctx, f, executionId, Failed, map[string]any{"error": message})
+}
+
+// Process claims a [File], runs a transfer function returning the file checksum, and records its outcome.
+// It returns false without running the function if the file was not claimed.
+func (l *Ledger) Process(ctx context.Context, f File, executionId string, fn func() (string, error)) (bool, error) {
+	claimed, err := l.Claim(ctx, f, executionId)
+	if err != nil || !claimed {
+		return false, err
+	}
+
+	checksum, err := fn()
+
+	// the outcome is recorded even if the execution timed out or was cancelled, not to leave the file in progress
+	ctx = context.WithoutCancel(ctx)
+
+	if err != nil {
+		if failErr := l.Fail(ctx, f, executionId, err); failErr != nil {
+			return true, errors.Join(err, failErr)
+		}
+
+		return true, err
+	}
+
+	return true, l.Succeed(ctx, f, executionId, checksum)
+}
+
+// Succeeded returns true if a [File] was already processed successfully.
+func (l *Ledger) Succeeded(ctx context.Context, f File) (bool, error) {
+	query := repo.Psql.
+		Select("id").
+		From(l.options.Table).
+		Where(sq.Eq{
+			"endpoint":  f.Endpoint,
+			"direction": f.Direction.String(),
+			"path":      f.Path,
+			"size":      f.Size,
+			"mtime":     f.modTime(),
+			"status":    Succeeded.String(),
+		})
+
+	_, found, err := repo.SelectRowsOK(ctx, l.db, query, pgx.RowTo[int64])
+	if err != nil {
+		return false, fmt.Errorf("unable to select ledger file %s: %w", f.Path, err)
+	}
+
+	return found, nil
+}
+
+// Entries returns the ledger entries of an endpoint and direction with a given [Status], most recent first.
+func (l *Ledger) Entries(ctx context.Context, endpoint string, direction Direction, status Status) ([]Entry, error) {
+	query := repo.Psql.
+		Select(columns...).
+		From(l.options.Table).
+		Where(sq.Eq{
+			"endpoint":  endpoint,
+			"direction": direction.String(),
+			"status":    status.String(),
+		}).
+		OrderBy("updated_at DESC")
+
+	entries, _, err := repo.SelectRowsOK(ctx, l.db, query, pgx.RowToStructByName[Entry])
+	if err != nil {
+		return nil, fmt.Errorf("unable to select ledger entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (l *Ledger) complete(ctx context.Context, f File, executionId string, status Status, values map[string]any) error {
+	query := repo.Psql.
+		Update(l.options.Table).
+		Set("status", status.String()).
+		Set("updated_at", sq.Expr("now()")).
+		SetMap(values).
+		Where(sq.Eq{
+			"endpoint":     f.Endpoint,
+			"direction":    f.Direction.String(),
+			"path":         f.Path,
+			"size":         f.Size,
+			"mtime":        f.modTime(),
+			"execution_id": executionId,
+		})
+
+	tag, err := repo.Update(ctx, l.db, query)
+	if err != nil {
+		return fmt.Errorf("unable to mark ledger file %s as %s: %w", f.Path, status, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("ledger file %s is not claimed by execution %s", f.Path, executionId)
+	}
+
+	return nil
+}
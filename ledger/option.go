@@ -0,0 +1,39 @@
+package ledger
+
+import "time"
+
+const (
+	DefaultTable      = "transfer_ledger"
+	DefaultStaleAfter = time.Hour
+)
+
+// Options are options for the [Ledger].
+type Options struct {
+	Table      string
+	StaleAfter time.Duration
+}
+
+// DefaultLedgerOptions are the default options used in the [Ledger].
+func DefaultLedgerOptions() Options {
+	return Options{
+		Table:      DefaultTable,
+		StaleAfter: DefaultStaleAfter,
+	}
+}
+
+// LedgerOption are functional options for the [Ledger].
+type LedgerOption func(o *Options)
+
+// WithTable is used to specify the ledger table name.
+func WithTable(t string) LedgerOption {
+	return func(o *Options) {
+		o.Table = t
+	}
+}
+
+// WithStaleAfter is used to specify the duration after which an in progress file, left by an interrupted execution, can be claimed again.
+func WithStaleAfter(d time.Duration) LedgerOption {
+	return func(o *Options) {
+		o.StaleAfter = d
+	}
+}
@@ -0,0 +1,139 @@
+package sftpserver
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// FileReceivedEvent is emitted once a file is completely received from a user: when its upload handle is closed
+// without transfer error, or when it is renamed from a temporary name (see [WithTemporaryPatterns]) to its final name.
+type FileReceivedEvent struct {
+	User       string
+	Path       string // slash separated path, in the user root
+	LocalPath  string // path on the local filesystem
+	Size       int64
+	ModTime    time.Time
+	RemoteAddr string
+	SessionId  string
+	ReceivedAt time.Time
+}
+
+// FileReceivedHandler is the interface for the [FileReceivedEvent] handlers.
+type FileReceivedHandler interface {
+	Name() string
+	Handle(ctx context.Context, event FileReceivedEvent) error
+}
+
+// FileReceivedHandlerFunc adapts a function into a named [FileReceivedHandler].
+func FileReceivedHandlerFunc(name string, fn func(ctx context.Context, event FileReceivedEvent) error) FileReceivedHandler {
+	return &funcHandler{
+		name: name,
+		fn:   fn,
+	}
+}
+
+type funcHandler struct {
+	name string
+	fn   func(ctx context.Context, event FileReceivedEvent) error
+}
+
+func (h *funcHandler) Name() string {
+	return h.name
+}
+
+func (h *funcHandler) Handle(ctx context.Context, event FileReceivedEvent) error {
+	return h.fn(ctx, event)
+}
+
+type subscription struct {
+	id      int
+	handler FileReceivedHandler
+}
+
+// Subscribe registers a [FileReceivedHandler], called for each received file until the returned function is called.
+// Cron jobs can subscribe from their constructor, to be notified of the partner uploads instead of polling.
+//
+// The handlers are called one after the other, in their subscription order, from a single dispatcher:
+// long running handlers should hand the events over to their own goroutines.
+func (s *Server) Subscribe(handler FileReceivedHandler) func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.subscriptionId++
+	id := s.subscriptionId
+
+	s.subscriptions = append(s.subscriptions, subscription{id: id, handler: handler})
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		for i, sub := range s.subscriptions {
+			if sub.id == id {
+				s.subscriptions = append(s.subscriptions[:i:i], s.subscriptions[i+1:]...)
+
+				return
+			}
+		}
+	}
+}
+
+// emit queues an event for the dispatcher. The event is dropped, with an error log, if the handlers lag behind and
+// the queue is full, so that the uploads never wait for them.
+func (s *Server) emit(event FileReceivedEvent) {
+	s.options.Logger.Info().
+		Str("user", event.User).
+		Str("path", event.Path).
+		Int64("size", event.Size).
+		Str("session", event.SessionId).
+		Msg("file received")
+
+	select {
+	case s.events <- event:
+	default:
+		s.options.Logger.Error().
+			Str("user", event.User).
+			Str("path", event.Path).
+			Str("session", event.SessionId).
+			Msg("file received event dropped, events queue is full")
+	}
+}
+
+// dispatch calls the subscribed handlers for each queued event, until the queue is closed.
+func (s *Server) dispatch(ctx context.Context) {
+	defer close(s.dispatched)
+
+	for event := range s.events {
+		s.mu.Lock()
+		subscriptions := s.subscriptions
+		s.mu.Unlock()
+
+		for _, sub := range subscriptions {
+			if err := s.handle(ctx, sub.handler, event); err != nil {
+				s.options.Logger.Error().
+					Err(err).
+					Str("handler", sub.handler.Name()).
+					Str("user", event.User).
+					Str("path", event.Path).
+					Msg("file received handler error")
+			}
+		}
+	}
+}
+
+func (s *Server) handle(ctx context.Context, handler FileReceivedHandler, event FileReceivedEvent) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panic: %v", r)
+		}
+	}()
+
+	logger := s.options.Logger.With().
+		Str("handler", handler.Name()).
+		Str("user", event.User).
+		Str("session", event.SessionId).
+		Logger()
+
+	return handler.Handle(logger.WithContext(ctx), event)
+}
@@ -0,0 +1,371 @@
+package sftpserver
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/pkg/sftp"
+)
+
+var (
+	_ sftp.FileReader      = (*session)(nil)
+	_ sftp.OpenFileWriter  = (*session)(nil)
+	_ sftp.FileCmder       = (*session)(nil)
+	_ sftp.FileLister      = (*session)(nil)
+	_ sftp.LstatFileLister = (*session)(nil)
+)
+
+// session serves the sftp requests of an authenticated user, in its root directory: paths cannot escape it,
+// neither by their dot dot elements nor through symbolic links, and links cannot be created.
+type session struct {
+	server     *Server
+	user       string
+	root       string
+	remoteAddr string
+	id         string
+}
+
+func (s *session) handlers() sftp.Handlers {
+	return sftp.Handlers{
+		FileGet:  s,
+		FilePut:  s,
+		FileCmd:  s,
+		FileList: s,
+	}
+}
+
+// Fileread opens a file for reading.
+func (s *session) Fileread(r *sftp.Request) (io.ReaderAt, error) {
+	local, err := s.resolve(r.Filepath)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(local)
+	if err != nil {
+		return nil, clientError(err, r.Filepath)
+	}
+
+	return f, nil
+}
+
+// Filewrite opens a file for writing, reported as received once closed if written.
+func (s *session) Filewrite(r *sftp.Request) (io.WriterAt, error) {
+	return s.open(r, os.O_WRONLY)
+}
+
+// OpenFile opens a file for reading and writing, reported as received once closed if written.
+func (s *session) OpenFile(r *sftp.Request) (sftp.WriterAtReaderAt, error) {
+	return s.open(r, os.O_RDWR)
+}
+
+func (s *session) open(r *sftp.Request, flags int) (*receivedFile, error) {
+	local, err := s.resolve(r.Filepath)
+	if err != nil {
+		return nil, err
+	}
+
+	// no O_APPEND, the clients write at explicit offsets
+	pflags := r.Pflags()
+	if pflags.Creat {
+		flags |= os.O_CREATE
+	}
+
+	if pflags.Trunc {
+		flags |= os.O_TRUNC
+	}
+
+	if pflags.Excl {
+		flags |= os.O_EXCL
+	}
+
+	f, err := os.OpenFile(local, flags, 0o644)
+	if err != nil {
+		return nil, clientError(err, r.Filepath)
+	}
+
+	file := &receivedFile{
+		File:    f,
+		session: s,
+		path:    r.Filepath,
+	}
+
+	// truncated files are received even without writes, like empty uploads
+	file.written.Store(pflags.Trunc)
+
+	return file, nil
+}
+
+// Filecmd runs the setstat, rename, rmdir, mkdir and remove commands.
+func (s *session) Filecmd(r *sftp.Request) error {
+	local, err := s.resolve(r.Filepath)
+	if err != nil {
+		return err
+	}
+
+	switch r.Method {
+	case "Setstat":
+		err = s.setstat(r, local)
+	case "Rename":
+		err = s.rename(r, local, false)
+	case "PosixRename":
+		err = s.rename(r, local, true)
+	case "Rmdir":
+		err = s.remove(local, true)
+	case "Remove":
+		err = s.remove(local, false)
+	case "Mkdir":
+		err = os.Mkdir(local, 0o755)
+	default:
+		return sftp.ErrSSHFxOpUnsupported
+	}
+
+	return clientError(err, r.Filepath)
+}
+
+// PosixRename renames a file, replacing the existing target.
+func (s *session) PosixRename(r *sftp.Request) error {
+	local, err := s.resolve(r.Filepath)
+	if err != nil {
+		return err
+	}
+
+	return clientError(s.rename(r, local, true), r.Filepath)
+}
+
+func (s *session) setstat(r *sftp.Request, local string) error {
+	attrs := r.Attributes()
+	flags := r.AttrFlags()
+
+	if flags.Size {
+		if err := os.Truncate(local, int64(attrs.Size)); err != nil {
+			return err
+		}
+	}
+
+	if flags.Permissions {
+		if err := os.Chmod(local, attrs.FileMode().Perm()); err != nil {
+			return err
+		}
+	}
+
+	if flags.Acmodtime {
+		if err := os.Chtimes(local, time.Unix(int64(attrs.Atime), 0), time.Unix(int64(attrs.Mtime), 0)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rename renames a file, refusing to replace an existing target unless overwrite is true, as per the sftp protocol.
+// A file renamed from a temporary name to its final name is reported as received.
+func (s *session) rename(r *sftp.Request, local string, overwrite bool) error {
+	target, err := s.resolve(r.Target)
+	if err != nil {
+		return err
+	}
+
+	if !overwrite {
+		if _, err = os.Lstat(target); err == nil {
+			return fmt.Errorf("rename %s: %w", r.Target, fs.ErrExist)
+		}
+	}
+
+	if err = os.Rename(local, target); err != nil {
+		return err
+	}
+
+	if s.server.temporary(r.Filepath) && !s.server.temporary(r.Target) {
+		if info, err := os.Stat(target); err == nil && info.Mode().IsRegular() {
+			s.received(r.Target, target, info)
+		}
+	}
+
+	return nil
+}
+
+func (s *session) remove(local string, dir bool) error {
+	info, err := os.Lstat(local)
+	if err != nil {
+		return err
+	}
+
+	if info.IsDir() != dir {
+		if dir {
+			return syscall.ENOTDIR
+		}
+
+		return syscall.EISDIR
+	}
+
+	return os.Remove(local)
+}
+
+// Filelist lists directories, and stats files.
+func (s *session) Filelist(r *sftp.Request) (sftp.ListerAt, error) {
+	local, err := s.resolve(r.Filepath)
+	if err != nil {
+		return nil, err
+	}
+
+	switch r.Method {
+	case "List":
+		entries, err := os.ReadDir(local)
+		if err != nil {
+			return nil, clientError(err, r.Filepath)
+		}
+
+		infos := make([]fs.FileInfo, 0, len(entries))
+		for _, entry := range entries {
+			// removed since listed
+			if info, err := entry.Info(); err == nil {
+				infos = append(infos, info)
+			}
+		}
+
+		return listerAt(infos), nil
+	case "Stat":
+		info, err := os.Stat(local)
+		if err != nil {
+			return nil, clientError(err, r.Filepath)
+		}
+
+		return listerAt{info}, nil
+	}
+
+	return nil, sftp.ErrSSHFxOpUnsupported
+}
+
+// Lstat stats files, without following symbolic links.
+func (s *session) Lstat(r *sftp.Request) (sftp.ListerAt, error) {
+	local, err := s.resolve(r.Filepath)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Lstat(local)
+	if err != nil {
+		return nil, clientError(err, r.Filepath)
+	}
+
+	return listerAt{info}, nil
+}
+
+// resolve returns the local path of a sftp path, in the session root.
+// Paths leading out of the root through symbolic links are denied.
+func (s *session) resolve(p string) (string, error) {
+	local := filepath.Join(s.root, filepath.FromSlash(path.Clean("/"+p)))
+
+	// the path itself may not exist yet, like the files being uploaded
+	real, err := filepath.EvalSymlinks(local)
+	if errors.Is(err, fs.ErrNotExist) {
+		if real, err = filepath.EvalSymlinks(filepath.Dir(local)); err == nil {
+			real = filepath.Join(real, filepath.Base(local))
+		}
+	}
+
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return local, nil
+		}
+
+		return "", clientError(err, p)
+	}
+
+	if real != s.root && !strings.HasPrefix(real, s.root+string(filepath.Separator)) {
+		return "", &fs.PathError{Op: "resolve", Path: p, Err: syscall.EACCES}
+	}
+
+	return local, nil
+}
+
+// received reports a received file to the server subscribers.
+func (s *session) received(p string, local string, info fs.FileInfo) {
+	s.server.emit(FileReceivedEvent{
+		User:       s.user,
+		Path:       path.Clean("/" + p),
+		LocalPath:  local,
+		Size:       info.Size(),
+		ModTime:    info.ModTime(),
+		RemoteAddr: s.remoteAddr,
+		SessionId:  s.id,
+		ReceivedAt: time.Now(),
+	})
+}
+
+// receivedFile is a file opened for writing, reported as received once closed without transfer error.
+type receivedFile struct {
+	*os.File
+	session *session
+	path    string
+	written atomic.Bool
+	failed  atomic.Bool
+}
+
+// WriteAt writes to the file, then reported as received once closed.
+func (f *receivedFile) WriteAt(b []byte, off int64) (int, error) {
+	f.written.Store(true)
+
+	return f.File.WriteAt(b, off)
+}
+
+// TransferError is called by the sftp server when the session ends with the file still open.
+func (f *receivedFile) TransferError(error) {
+	f.failed.Store(true)
+}
+
+// Close closes the file, and reports it as received if it was written, unless its transfer failed or it has a temporary name.
+func (f *receivedFile) Close() error {
+	info, statErr := f.File.Stat()
+
+	if err := f.File.Close(); err != nil {
+		return clientError(err, f.path)
+	}
+
+	if statErr == nil && f.written.Load() && !f.failed.Load() && !f.session.server.temporary(f.path) {
+		f.session.received(f.path, f.File.Name(), info)
+	}
+
+	return nil
+}
+
+// listerAt is the [sftp.ListerAt] of a list of [fs.FileInfo].
+type listerAt []fs.FileInfo
+
+func (l listerAt) ListAt(infos []fs.FileInfo, offset int64) (int, error) {
+	if offset >= int64(len(l)) {
+		return 0, io.EOF
+	}
+
+	n := copy(infos, l[offset:])
+	if n < len(infos) {
+		return n, io.EOF
+	}
+
+	return n, nil
+}
+
+// clientError hides the local paths from the errors sent to the clients, reporting their sftp path instead.
+func clientError(err error, p string) error {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return &fs.PathError{Op: pathErr.Op, Path: p, Err: pathErr.Err}
+	}
+
+	var linkErr *os.LinkError
+	if errors.As(err, &linkErr) {
+		return &fs.PathError{Op: linkErr.Op, Path: p, Err: linkErr.Err}
+	}
+
+	return err
+}
@@ -0,0 +1,59 @@
+package sftpserver
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// LoadHostKey returns the host key stored in a PEM or OpenSSH private key file.
+// If the file does not exist and generate is true, an ed25519 key is generated and stored in it,
+// so that the host key, trusted by the clients, stays the same across restarts.
+func LoadHostKey(path string, generate bool) (ssh.Signer, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && generate {
+		data, err = generateHostKey(path)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to read host key %s: %w", path, err)
+	}
+
+	signer, err := ssh.ParsePrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse host key %s: %w", path, err)
+	}
+
+	return signer, nil
+}
+
+func generateHostKey(path string) ([]byte, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := ssh.MarshalPrivateKey(key, "")
+	if err != nil {
+		return nil, err
+	}
+
+	data := pem.EncodeToMemory(block)
+
+	if err = os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+
+	if err = os.WriteFile(path, data, 0o600); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
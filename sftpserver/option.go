@@ -0,0 +1,124 @@
+package sftpserver
+
+import (
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/templatedop/ftptemplate/log"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	DefaultAddress      = ":2022"
+	DefaultRoot         = "./sftp"
+	DefaultTimeout      = 30 * time.Second
+	DefaultIdleTimeout  = 15 * time.Minute
+	DefaultMaxAuthTries = 6
+	DefaultEventsBuffer = 100
+)
+
+// Options are options for the [Server].
+type Options struct {
+	Address           string
+	HostKeys          []ssh.Signer
+	Users             UserStore
+	Root              string
+	Timeout           time.Duration
+	IdleTimeout       time.Duration
+	MaxAuthTries      int
+	TemporaryPatterns []string
+	EventsBuffer      int
+	Logger            *log.Logger
+}
+
+// DefaultServerOptions are the default options used in the [Server].
+func DefaultServerOptions() Options {
+	return Options{
+		Address:           DefaultAddress,
+		Root:              DefaultRoot,
+		Timeout:           DefaultTimeout,
+		IdleTimeout:       DefaultIdleTimeout,
+		MaxAuthTries:      DefaultMaxAuthTries,
+		TemporaryPatterns: []string{"*.filepart", "*.part", "*.tmp"},
+		EventsBuffer:      DefaultEventsBuffer,
+		Logger:            log.FromZerolog(zerolog.Nop()),
+	}
+}
+
+// ServerOption are functional options for the [Server].
+type ServerOption func(o *Options)
+
+// WithAddress is used to specify the listen address of the server, ":2022" by default.
+func WithAddress(a string) ServerOption {
+	return func(o *Options) {
+		o.Address = a
+	}
+}
+
+// WithHostKeys is used to specify the host keys the server authenticates with, at least one is required.
+func WithHostKeys(keys ...ssh.Signer) ServerOption {
+	return func(o *Options) {
+		o.HostKeys = append(o.HostKeys, keys...)
+	}
+}
+
+// WithUsers is used to specify the [UserStore] the users are authenticated from.
+func WithUsers(s UserStore) ServerOption {
+	return func(o *Options) {
+		o.Users = s
+	}
+}
+
+// WithRoot is used to specify the base directory of the users roots, for users without their own root (<root>/<user>).
+func WithRoot(r string) ServerOption {
+	return func(o *Options) {
+		o.Root = r
+	}
+}
+
+// WithTimeout is used to specify the timeout of the ssh handshakes, including the users lookups.
+func WithTimeout(t time.Duration) ServerOption {
+	return func(o *Options) {
+		o.Timeout = t
+	}
+}
+
+// WithIdleTimeout is used to specify the duration after which connections without traffic are closed (0 to disable).
+func WithIdleTimeout(t time.Duration) ServerOption {
+	return func(o *Options) {
+		o.IdleTimeout = t
+	}
+}
+
+// WithMaxAuthTries is used to specify the maximum number of authentication attempts per connection.
+func WithMaxAuthTries(n int) ServerOption {
+	return func(o *Options) {
+		if n > 0 {
+			o.MaxAuthTries = n
+		}
+	}
+}
+
+// WithTemporaryPatterns is used to specify the glob patterns of the temporary upload names, like "*.filepart":
+// files with such names are reported as received once renamed to their final name, not when uploaded.
+func WithTemporaryPatterns(patterns ...string) ServerOption {
+	return func(o *Options) {
+		o.TemporaryPatterns = patterns
+	}
+}
+
+// WithEventsBuffer is used to specify the number of [FileReceivedEvent] queued for the handlers, the next ones being dropped.
+func WithEventsBuffer(n int) ServerOption {
+	return func(o *Options) {
+		if n > 0 {
+			o.EventsBuffer = n
+		}
+	}
+}
+
+// WithLogger is used to specify the [log.Logger] of the server.
+func WithLogger(l *log.Logger) ServerOption {
+	return func(o *Options) {
+		o.Logger = l
+	}
+}
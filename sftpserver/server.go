@@ -0,0 +1,436 @@
+package sftpserver
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	rootExtension   = "sftpserver-root"
+	methodExtension = "sftpserver-method"
+)
+
+var errInvalidCredentials = errors.New("invalid credentials")
+
+// Server is an embedded SFTP server, for partners pushing files instead of being polled.
+//
+// Users authenticate by password or public key from a [UserStore], and are chrooted in their root directory.
+// Each completely received file emits a [FileReceivedEvent] to the subscribed [FileReceivedHandler].
+type Server struct {
+	options        Options
+	config         *ssh.ServerConfig
+	mu             sync.Mutex
+	listener       net.Listener
+	conns          map[net.Conn]struct{}
+	connections    sync.WaitGroup
+	closed         bool
+	subscriptions  []subscription
+	subscriptionId int
+	events         chan FileReceivedEvent
+	dispatched     chan struct{}
+	cancel         context.CancelFunc
+}
+
+// NewServer returns a new [Server], and accepts a list of [ServerOption].
+func NewServer(options ...ServerOption) *Server {
+	appliedOpts := DefaultServerOptions()
+	for _, opt := range options {
+		opt(&appliedOpts)
+	}
+
+	s := &Server{
+		options:    appliedOpts,
+		conns:      make(map[net.Conn]struct{}),
+		events:     make(chan FileReceivedEvent, appliedOpts.EventsBuffer),
+		dispatched: make(chan struct{}),
+	}
+
+	s.config = &ssh.ServerConfig{
+		MaxAuthTries:      appliedOpts.MaxAuthTries,
+		PasswordCallback:  s.passwordCallback,
+		PublicKeyCallback: s.publicKeyCallback,
+		AuthLogCallback:   s.authLogCallback,
+	}
+
+	for _, key := range appliedOpts.HostKeys {
+		s.config.AddHostKey(key)
+	}
+
+	return s
+}
+
+// Addr returns the address the server listens on, or nil if not started.
+func (s *Server) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.listener == nil {
+		return nil
+	}
+
+	return s.listener.Addr()
+}
+
+// Start listens on the server address, and serves the connections and the events in background.
+// It requires at least one host key, and a user store.
+func (s *Server) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.listener != nil || s.closed {
+		return fmt.Errorf("sftp server already started")
+	}
+
+	if len(s.options.HostKeys) == 0 {
+		return fmt.Errorf("missing host key for sftp server")
+	}
+
+	if s.options.Users == nil {
+		return fmt.Errorf("missing user store for sftp server")
+	}
+
+	listener, err := new(net.ListenConfig).Listen(ctx, "tcp", s.options.Address)
+	if err != nil {
+		return fmt.Errorf("unable to listen on %s: %w", s.options.Address, err)
+	}
+
+	s.listener = listener
+
+	dispatchCtx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	go s.dispatch(s.options.Logger.WithContext(dispatchCtx))
+	go s.serve(listener)
+
+	return nil
+}
+
+// Shutdown stops listening, closes the connections, then waits for the queued events to be handled, or for the context to be done.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+
+		return nil
+	}
+
+	s.closed = true
+
+	started := s.listener != nil
+	if started {
+		s.listener.Close()
+	}
+
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	if !started {
+		return nil
+	}
+	defer s.cancel()
+
+	closed := make(chan struct{})
+	go func() {
+		s.connections.Wait()
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	close(s.events)
+
+	select {
+	case <-s.dispatched:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Server) serve(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+
+			s.options.Logger.Error().Err(err).Msg("sftp server accept error")
+			time.Sleep(100 * time.Millisecond)
+
+			continue
+		}
+
+		if !s.track(conn) {
+			conn.Close()
+
+			return
+		}
+
+		go func() {
+			defer s.untrack(conn)
+
+			s.handleConn(conn)
+		}()
+	}
+}
+
+func (s *Server) track(conn net.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return false
+	}
+
+	s.conns[conn] = struct{}{}
+	s.connections.Add(1)
+
+	return true
+}
+
+func (s *Server) untrack(conn net.Conn) {
+	s.mu.Lock()
+	delete(s.conns, conn)
+	s.mu.Unlock()
+
+	conn.Close()
+	s.connections.Done()
+}
+
+func (s *Server) handleConn(conn net.Conn) {
+	if s.options.IdleTimeout > 0 {
+		conn = &idleConn{Conn: conn, timeout: s.options.IdleTimeout}
+	}
+
+	// handshake and authentication timeout
+	timer := time.AfterFunc(s.options.Timeout, func() {
+		conn.Close()
+	})
+
+	sshConn, channels, requests, err := ssh.NewServerConn(conn, s.config)
+	timer.Stop()
+
+	if err != nil {
+		s.options.Logger.Debug().Err(err).Str("remote_addr", conn.RemoteAddr().String()).Msg("sftp server handshake failure")
+
+		return
+	}
+	defer sshConn.Close()
+
+	go ssh.DiscardRequests(requests)
+
+	sess := &session{
+		server:     s,
+		user:       sshConn.User(),
+		root:       sshConn.Permissions.Extensions[rootExtension],
+		remoteAddr: sshConn.RemoteAddr().String(),
+		id:         hex.EncodeToString(sshConn.SessionID())[:16],
+	}
+
+	logger := s.options.Logger.With().
+		Str("user", sess.user).
+		Str("remote_addr", sess.remoteAddr).
+		Str("session", sess.id).
+		Logger()
+
+	logger.Info().Str("method", sshConn.Permissions.Extensions[methodExtension]).Msg("sftp session opened")
+
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+
+			continue
+		}
+
+		channel, channelRequests, err := newChannel.Accept()
+		if err != nil {
+			logger.Error().Err(err).Msg("sftp server channel error")
+
+			continue
+		}
+
+		// the connection is tracked until its channels are served, since they emit events
+		s.connections.Add(1)
+		go func() {
+			defer s.connections.Done()
+			defer channel.Close()
+
+			if !sftpRequested(channelRequests) {
+				return
+			}
+
+			server := sftp.NewRequestServer(channel, sess.handlers())
+			if err := server.Serve(); err != nil && !errors.Is(err, io.EOF) {
+				logger.Warn().Err(err).Msg("sftp server session error")
+			}
+
+			server.Close()
+		}()
+	}
+
+	logger.Info().Msg("sftp session closed")
+}
+
+// sftpRequested answers the channel requests, accepting the sftp subsystem only, and returns true once it is requested.
+func sftpRequested(requests <-chan *ssh.Request) bool {
+	requested := make(chan bool, 1)
+
+	go func() {
+		ok := false
+		for req := range requests {
+			// subsystem name, prefixed by its length
+			accept := !ok && req.Type == "subsystem" && len(req.Payload) > 4 && string(req.Payload[4:]) == "sftp"
+			if req.WantReply {
+				req.Reply(accept, nil)
+			}
+
+			if accept {
+				ok = true
+				requested <- true
+			}
+		}
+
+		if !ok {
+			requested <- false
+		}
+	}()
+
+	return <-requested
+}
+
+func (s *Server) passwordCallback(meta ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+	user, err := s.lookup(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.CheckPassword(string(password)) {
+		return nil, errInvalidCredentials
+	}
+
+	return s.permissions(user, "password")
+}
+
+func (s *Server) publicKeyCallback(meta ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+	user, err := s.lookup(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.CheckPublicKey(key) {
+		return nil, errInvalidCredentials
+	}
+
+	return s.permissions(user, "publickey")
+}
+
+func (s *Server) authLogCallback(meta ssh.ConnMetadata, method string, err error) {
+	if err == nil || method == "none" {
+		return
+	}
+
+	s.options.Logger.Warn().
+		Err(err).
+		Str("user", meta.User()).
+		Str("remote_addr", meta.RemoteAddr().String()).
+		Str("method", method).
+		Msg("sftp server authentication failure")
+}
+
+func (s *Server) lookup(meta ssh.ConnMetadata) (*User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.options.Timeout)
+	defer cancel()
+
+	user, err := s.options.Users.User(ctx, meta.User())
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, errInvalidCredentials
+		}
+
+		s.options.Logger.Error().Err(err).Str("user", meta.User()).Msg("sftp server user lookup error")
+
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// permissions returns the [ssh.Permissions] of an authenticated user, with its root directory, created if missing.
+func (s *Server) permissions(user *User, method string) (*ssh.Permissions, error) {
+	root := user.Root
+	if root == "" {
+		if user.Name == "" || strings.ContainsAny(user.Name, `/\`) || path.Clean(user.Name) != user.Name || user.Name == ".." {
+			return nil, fmt.Errorf("invalid user name %q for a default root", user.Name)
+		}
+
+		root = filepath.Join(s.options.Root, user.Name)
+	}
+
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, fmt.Errorf("unable to create root of user %s: %w", user.Name, err)
+	}
+
+	// the symbolic links in the root itself are resolved, to check the paths in it
+	root, err := filepath.Abs(root)
+	if err == nil {
+		root, err = filepath.EvalSymlinks(root)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve root of user %s: %w", user.Name, err)
+	}
+
+	return &ssh.Permissions{
+		Extensions: map[string]string{
+			rootExtension:   root,
+			methodExtension: method,
+		},
+	}, nil
+}
+
+// temporary returns true if a path name matches one of the temporary upload patterns.
+func (s *Server) temporary(p string) bool {
+	name := path.Base(p)
+
+	for _, pattern := range s.options.TemporaryPatterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// idleConn closes the connections without received traffic for a duration, clients keepalives included.
+type idleConn struct {
+	net.Conn
+	timeout time.Duration
+}
+
+func (c *idleConn) Read(b []byte) (int, error) {
+	if err := c.Conn.SetReadDeadline(time.Now().Add(c.timeout)); err != nil {
+		return 0, err
+	}
+
+	return c.Conn.Read(b)
+}
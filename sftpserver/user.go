@@ -0,0 +1,126 @@
+package sftpserver
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/ssh"
+)
+
+// ErrUserNotFound is returned by the [UserStore] implementations for unknown (or disabled) users.
+var ErrUserNotFound = errors.New("user not found")
+
+// User is a user allowed to connect to the [Server], by password or public key.
+type User struct {
+	Name           string
+	Password       string // plain text password, compared in constant time
+	PasswordHash   string // bcrypt password hash, taking precedence over the plain text password
+	AuthorizedKeys []ssh.PublicKey
+	Root           string // chroot directory, <server root>/<name> by default
+}
+
+// CheckPassword returns true if a password matches the user password hash, or plain text password.
+func (u *User) CheckPassword(password string) bool {
+	if u.PasswordHash != "" {
+		return bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)) == nil
+	}
+
+	if u.Password == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(u.Password), []byte(password)) == 1
+}
+
+// CheckPublicKey returns true if a public key is one of the user authorized keys.
+func (u *User) CheckPublicKey(key ssh.PublicKey) bool {
+	marshaled := key.Marshal()
+
+	for _, authorizedKey := range u.AuthorizedKeys {
+		if bytes.Equal(authorizedKey.Marshal(), marshaled) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// UserStore is the interface for the users lookups of the [Server].
+type UserStore interface {
+	User(ctx context.Context, name string) (*User, error)
+}
+
+// StaticUserStore is a [UserStore] of a fixed set of users, like the ones declared in the configuration.
+type StaticUserStore struct {
+	users map[string]*User
+}
+
+// NewStaticUserStore returns a new [StaticUserStore], for a list of [User].
+func NewStaticUserStore(users ...*User) *StaticUserStore {
+	store := &StaticUserStore{
+		users: make(map[string]*User, len(users)),
+	}
+
+	for _, user := range users {
+		store.users[user.Name] = user
+	}
+
+	return store
+}
+
+// User returns a [User] by name, or [ErrUserNotFound].
+func (s *StaticUserStore) User(_ context.Context, name string) (*User, error) {
+	if user, ok := s.users[name]; ok {
+		return user, nil
+	}
+
+	return nil, ErrUserNotFound
+}
+
+// Len returns the number of users of the store.
+func (s *StaticUserStore) Len() int {
+	return len(s.users)
+}
+
+type chainUserStore []UserStore
+
+// ChainUserStores returns a [UserStore] looking users up in a list of [UserStore], in order, until found.
+func ChainUserStores(stores ...UserStore) UserStore {
+	return chainUserStore(stores)
+}
+
+func (c chainUserStore) User(ctx context.Context, name string) (*User, error) {
+	for _, store := range c {
+		user, err := store.User(ctx, name)
+		if !errors.Is(err, ErrUserNotFound) {
+			return user, err
+		}
+	}
+
+	return nil, ErrUserNotFound
+}
+
+// ParseAuthorizedKeys parses public keys in the authorized_keys format, one per line, comments and empty lines ignored.
+func ParseAuthorizedKeys(data []byte) ([]ssh.PublicKey, error) {
+	var keys []ssh.PublicKey
+
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+
+		key, _, _, _, err := ssh.ParseAuthorizedKey(line)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse authorized key at line %d: %w", i+1, err)
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
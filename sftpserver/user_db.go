@@ -0,0 +1,100 @@
+package sftpserver
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/templatedop/ftptemplate/db"
+	"github.com/templatedop/ftptemplate/repo"
+)
+
+const DefaultUsersTable = "sftp_users"
+
+const usersSchema = `
+CREATE TABLE IF NOT EXISTS %[1]s (
+	username        TEXT PRIMARY KEY,
+	password_hash   TEXT NOT NULL DEFAULT '',
+	authorized_keys TEXT NOT NULL DEFAULT '',
+	root            TEXT NOT NULL DEFAULT '',
+	enabled         BOOLEAN NOT NULL DEFAULT true,
+	created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+`
+
+// dbUser is a row of the users table.
+type dbUser struct {
+	Username       string `db:"username"`
+	PasswordHash   string `db:"password_hash"`
+	AuthorizedKeys string `db:"authorized_keys"`
+	Root           string `db:"root"`
+}
+
+// DBUserStore is a [UserStore] of the enabled users of a Postgres table, looked up at each authentication:
+// users can be added, disabled or have their keys rotated without restart.
+//
+// Passwords are stored as bcrypt hashes, and public keys in the authorized_keys format, one per line.
+type DBUserStore struct {
+	db    *db.DB
+	table string
+}
+
+// NewDBUserStore returns a new [DBUserStore], for a [db.DB] and a table name ([DefaultUsersTable] if empty).
+func NewDBUserStore(db *db.DB, table string) *DBUserStore {
+	if table == "" {
+		table = DefaultUsersTable
+	}
+
+	return &DBUserStore{
+		db:    db,
+		table: table,
+	}
+}
+
+// Table returns the users table name.
+func (s *DBUserStore) Table() string {
+	return s.table
+}
+
+// Migrate creates the users table if it does not exist.
+func (s *DBUserStore) Migrate(ctx context.Context) error {
+	if _, err := s.db.Exec(ctx, fmt.Sprintf(usersSchema, s.table)); err != nil {
+		return fmt.Errorf("unable to migrate sftp users table %s: %w", s.table, err)
+	}
+
+	return nil
+}
+
+// User returns an enabled [User] by name, or [ErrUserNotFound].
+func (s *DBUserStore) User(ctx context.Context, name string) (*User, error) {
+	query := repo.Psql.
+		Select("username", "password_hash", "authorized_keys", "root").
+		From(s.table).
+		Where(sq.Eq{
+			"username": name,
+			"enabled":  true,
+		})
+
+	row, found, err := repo.SelectOneOK(ctx, s.db, query, pgx.RowToStructByName[dbUser])
+	if err != nil {
+		return nil, fmt.Errorf("unable to select sftp user %s: %w", name, err)
+	}
+
+	if !found {
+		return nil, ErrUserNotFound
+	}
+
+	keys, err := ParseAuthorizedKeys([]byte(row.AuthorizedKeys))
+	if err != nil {
+		return nil, fmt.Errorf("invalid authorized keys for sftp user %s: %w", name, err)
+	}
+
+	return &User{
+		Name:           row.Username,
+		PasswordHash:   row.PasswordHash,
+		AuthorizedKeys: keys,
+		Root:           row.Root,
+	}, nil
+}
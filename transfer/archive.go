@@ -0,0 +1,556 @@
+package transfer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ManifestName is the name of the manifest written at the root of the archives, listing their files.
+const ManifestName = "MANIFEST.json"
+
+// maxManifestSize is the maximum size of the archives manifest read on extraction.
+const maxManifestSize = 16 << 20
+
+// DefaultMaxExtractFileSize and DefaultMaxExtractSize are the default maximum sizes of an extracted archive file,
+// and of all the extracted archive files, against decompression bombs.
+const (
+	DefaultMaxExtractFileSize = 1 << 30
+	DefaultMaxExtractSize     = 4 << 30
+)
+
+// ErrArchiveTooLarge is returned when an extracted archive file, or all the extracted files, exceed their maximum size.
+var ErrArchiveTooLarge = errors.New("archive too large")
+
+// ErrUnsafeArchiveEntry is returned when an archive entry would be extracted outside of its directory (zip slip),
+// or is a link or a special file.
+var ErrUnsafeArchiveEntry = errors.New("unsafe archive entry")
+
+// Manifest is the list of the files of an archive, with their size, modification time and sha256 digest.
+type Manifest struct {
+	CreatedAt time.Time      `json:"created_at"`
+	Files     []ManifestFile `json:"files"`
+}
+
+// ManifestFile is a file listed in a [Manifest], under its slash separated path in the archive.
+type ManifestFile struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+	Sha256  string    `json:"sha256"`
+}
+
+// ArchiveFile is a local file bundled into an archive, under a slash separated path.
+type ArchiveFile struct {
+	Path      string
+	LocalPath string
+}
+
+// CreateArchive bundles local files into an archive file of an [ArchiveFormat], followed by its [Manifest],
+// written to a temporary name then renamed once complete. It returns the archive [Manifest].
+func CreateArchive(ctx context.Context, format ArchiveFormat, archivePath string, files []ArchiveFile) (*Manifest, error) {
+	if err := os.MkdirAll(filepath.Dir(archivePath), 0o755); err != nil {
+		return nil, fmt.Errorf("unable to create local directory [%s]: %w", filepath.Dir(archivePath), err)
+	}
+
+	archiveFile, err := os.CreateTemp(filepath.Dir(archivePath), fmt.Sprintf(".%s.*.part", filepath.Base(archivePath)))
+	if err != nil {
+		return nil, fmt.Errorf("unable to create archive [%s]: %w", archivePath, err)
+	}
+
+	manifest, err := WriteArchive(ctx, format, archiveFile, files)
+	if closeErr := archiveFile.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(archiveFile.Name(), archivePath)
+	}
+
+	if err != nil {
+		os.Remove(archiveFile.Name())
+
+		return nil, fmt.Errorf("unable to create archive [%s]: %w", archivePath, err)
+	}
+
+	return manifest, nil
+}
+
+// WriteArchive streams local files into an archive of an [ArchiveFormat] written to a writer, followed by its [Manifest].
+func WriteArchive(ctx context.Context, format ArchiveFormat, w io.Writer, files []ArchiveFile) (*Manifest, error) {
+	aw, err := newArchiveWriter(format, w)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{
+		CreatedAt: time.Now().UTC(),
+		Files:     make([]ManifestFile, 0, len(files)),
+	}
+
+	for _, file := range files {
+		if file.Path == ManifestName {
+			aw.Close()
+
+			return nil, fmt.Errorf("file [%s] conflicts with the archive manifest", file.LocalPath)
+		}
+
+		manifestFile, err := writeArchiveFile(ctx, aw, file)
+		if err != nil {
+			aw.Close()
+
+			return nil, err
+		}
+
+		manifest.Files = append(manifest.Files, *manifestFile)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		aw.Close()
+
+		return nil, err
+	}
+
+	mw, err := aw.create(ManifestName, int64(len(data)), manifest.CreatedAt)
+	if err == nil {
+		_, err = mw.Write(data)
+	}
+
+	if err != nil {
+		aw.Close()
+
+		return nil, fmt.Errorf("unable to write archive manifest: %w", err)
+	}
+
+	if err = aw.Close(); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+func writeArchiveFile(ctx context.Context, aw archiveWriter, file ArchiveFile) (*ManifestFile, error) {
+	srcFile, err := os.Open(file.LocalPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open local file [%s]: %w", file.LocalPath, err)
+	}
+	defer srcFile.Close()
+
+	info, err := srcFile.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("unable to stat local file [%s]: %w", file.LocalPath, err)
+	}
+
+	fw, err := aw.create(file.Path, info.Size(), info.ModTime())
+	if err != nil {
+		return nil, fmt.Errorf("unable to add [%s] to archive: %w", file.Path, err)
+	}
+
+	// the entry size is announced upfront, the files changed while bundled are rejected
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(fw, hash), io.LimitReader(NewContextReader(ctx, srcFile), info.Size()))
+	if err != nil {
+		return nil, fmt.Errorf("unable to add [%s] to archive: %w", file.Path, err)
+	}
+
+	if n != info.Size() {
+		return nil, fmt.Errorf("local file [%s] size %d does not match expected size %d", file.LocalPath, n, info.Size())
+	}
+
+	return &ManifestFile{
+		Path:    file.Path,
+		Size:    info.Size(),
+		ModTime: info.ModTime().UTC(),
+		Sha256:  hex.EncodeToString(hash.Sum(nil)),
+	}, nil
+}
+
+// ExtractOptions are options for [ExtractArchive].
+type ExtractOptions struct {
+	MaxFileSize int64
+	MaxSize     int64
+}
+
+// DefaultExtractOptions are the default options used in [ExtractArchive].
+func DefaultExtractOptions() ExtractOptions {
+	return ExtractOptions{
+		MaxFileSize: DefaultMaxExtractFileSize,
+		MaxSize:     DefaultMaxExtractSize,
+	}
+}
+
+// ExtractOption are functional options for [ExtractArchive].
+type ExtractOption func(o *ExtractOptions)
+
+// WithExtractMaxFileSize is used to specify the maximum size of an extracted file, 0 for none.
+func WithExtractMaxFileSize(n int64) ExtractOption {
+	return func(o *ExtractOptions) {
+		o.MaxFileSize = n
+	}
+}
+
+// WithExtractMaxSize is used to specify the maximum size of all the extracted files, 0 for none.
+func WithExtractMaxSize(n int64) ExtractOption {
+	return func(o *ExtractOptions) {
+		o.MaxSize = n
+	}
+}
+
+// ExtractArchive extracts an archive file of an [ArchiveFormat] into a local directory, and returns the extracted paths.
+// Entries leading out of the directory (absolute or dot dot paths), links and special files are rejected with
+// [ErrUnsafeArchiveEntry], and archives exceeding the [ExtractOptions] maximum sizes with [ErrArchiveTooLarge].
+// The files are extracted into a temporary directory, verified against the archive [Manifest] if any, then moved into
+// the directory, so that a rejected archive leaves no extracted file.
+func ExtractArchive(ctx context.Context, format ArchiveFormat, archivePath string, dir string, options ...ExtractOption) ([]string, error) {
+	appliedOpts := DefaultExtractOptions()
+	for _, opt := range options {
+		opt(&appliedOpts)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("unable to create local directory [%s]: %w", dir, err)
+	}
+
+	tmpDir, err := os.MkdirTemp(dir, ".extract-*")
+	if err != nil {
+		return nil, fmt.Errorf("unable to create local directory in [%s]: %w", dir, err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	extracted := make(map[string]*ManifestFile)
+	var manifest *Manifest
+	var size int64
+
+	err = walkArchive(format, archivePath, func(entry archiveEntry) error {
+		name := strings.TrimSuffix(entry.name, "/")
+		if !filepath.IsLocal(filepath.FromSlash(name)) {
+			return fmt.Errorf("%w: %s", ErrUnsafeArchiveEntry, entry.name)
+		}
+
+		switch {
+		case entry.dir:
+			return os.MkdirAll(filepath.Join(tmpDir, filepath.FromSlash(name)), 0o755)
+		case !entry.regular:
+			return fmt.Errorf("%w: %s is not a regular file", ErrUnsafeArchiveEntry, entry.name)
+		case name == ManifestName:
+			manifest = &Manifest{}
+
+			return json.NewDecoder(io.LimitReader(entry.reader, maxManifestSize)).Decode(manifest)
+		}
+
+		file, err := extractArchiveFile(ctx, tmpDir, name, entry.reader, extractLimit(appliedOpts, size))
+		if err != nil {
+			return err
+		}
+
+		extracted[name] = file
+		size += file.Size
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to extract archive [%s]: %w", archivePath, err)
+	}
+
+	if manifest != nil {
+		if err = verifyManifest(manifest, extracted); err != nil {
+			return nil, fmt.Errorf("unable to extract archive [%s]: %w", archivePath, err)
+		}
+	}
+
+	names := make([]string, 0, len(extracted))
+	for name := range extracted {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	paths := make([]string, 0, len(names))
+	for _, name := range names {
+		target, err := extractTarget(dir, name)
+		if err != nil {
+			return paths, fmt.Errorf("unable to extract archive [%s]: %w", archivePath, err)
+		}
+
+		if err = os.Rename(filepath.Join(tmpDir, filepath.FromSlash(name)), target); err != nil {
+			return paths, fmt.Errorf("unable to extract archive [%s]: %w", archivePath, err)
+		}
+
+		paths = append(paths, target)
+	}
+
+	return paths, nil
+}
+
+// extractLimit returns the maximum size of the next extracted file, from the maximum sizes and the already extracted size,
+// -1 for none.
+func extractLimit(o ExtractOptions, extracted int64) int64 {
+	limit := int64(-1)
+	if o.MaxFileSize > 0 {
+		limit = o.MaxFileSize
+	}
+
+	if o.MaxSize > 0 && (limit < 0 || o.MaxSize-extracted < limit) {
+		limit = max(o.MaxSize-extracted, 0)
+	}
+
+	return limit
+}
+
+func extractArchiveFile(ctx context.Context, tmpDir string, name string, r io.Reader, limit int64) (*ManifestFile, error) {
+	localPath := filepath.Join(tmpDir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(localPath), 0o755); err != nil {
+		return nil, err
+	}
+
+	// duplicated entries are rejected
+	dstFile, err := os.OpenFile(localPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	defer dstFile.Close()
+
+	// one more byte than the limit is read to detect the files exceeding it
+	var src io.Reader = NewContextReader(ctx, r)
+	if limit >= 0 {
+		src = io.LimitReader(src, limit+1)
+	}
+
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(dstFile, hash), src)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit >= 0 && n > limit {
+		return nil, fmt.Errorf("%w: %s exceeds the maximum extracted size", ErrArchiveTooLarge, name)
+	}
+
+	return &ManifestFile{
+		Path:   name,
+		Size:   n,
+		Sha256: hex.EncodeToString(hash.Sum(nil)),
+	}, dstFile.Close()
+}
+
+// extractTarget returns the local path an extracted file is moved to, creating its directories,
+// and denies the paths leading out of the directory through symbolic links.
+func extractTarget(dir string, name string) (string, error) {
+	target := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return "", err
+	}
+
+	realDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", err
+	}
+
+	realParent, err := filepath.EvalSymlinks(filepath.Dir(target))
+	if err != nil {
+		return "", err
+	}
+
+	if realParent != realDir && !strings.HasPrefix(realParent, realDir+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %s", ErrUnsafeArchiveEntry, name)
+	}
+
+	return target, nil
+}
+
+func verifyManifest(manifest *Manifest, extracted map[string]*ManifestFile) error {
+	listed := make(map[string]bool, len(manifest.Files))
+
+	for _, expected := range manifest.Files {
+		listed[expected.Path] = true
+
+		actual, ok := extracted[expected.Path]
+		if !ok {
+			return fmt.Errorf("file [%s] of the archive manifest is missing", expected.Path)
+		}
+
+		if actual.Size != expected.Size {
+			return fmt.Errorf("file [%s] size %d does not match manifest size %d", expected.Path, actual.Size, expected.Size)
+		}
+
+		if expected.Sha256 != "" && !strings.EqualFold(actual.Sha256, expected.Sha256) {
+			return &ChecksumError{
+				Path:      expected.Path,
+				Algorithm: Sha256ChecksumAlgorithm,
+				Expected:  expected.Sha256,
+				Actual:    actual.Sha256,
+			}
+		}
+	}
+
+	for name := range extracted {
+		if !listed[name] {
+			return fmt.Errorf("file [%s] is missing from the archive manifest", name)
+		}
+	}
+
+	return nil
+}
+
+// archiveWriter writes the entries of an archive.
+type archiveWriter interface {
+	create(name string, size int64, modTime time.Time) (io.Writer, error)
+	Close() error
+}
+
+func newArchiveWriter(format ArchiveFormat, w io.Writer) (archiveWriter, error) {
+	switch format {
+	case ZipArchiveFormat:
+		return &zipArchiveWriter{zw: zip.NewWriter(w)}, nil
+	case TarGzArchiveFormat:
+		gw := gzip.NewWriter(w)
+
+		return &tarGzArchiveWriter{gw: gw, tw: tar.NewWriter(gw)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported archive format %s", format)
+	}
+}
+
+type zipArchiveWriter struct {
+	zw *zip.Writer
+}
+
+func (w *zipArchiveWriter) create(name string, size int64, modTime time.Time) (io.Writer, error) {
+	return w.zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: modTime,
+	})
+}
+
+func (w *zipArchiveWriter) Close() error {
+	return w.zw.Close()
+}
+
+type tarGzArchiveWriter struct {
+	gw *gzip.Writer
+	tw *tar.Writer
+}
+
+func (w *tarGzArchiveWriter) create(name string, size int64, modTime time.Time) (io.Writer, error) {
+	err := w.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     size,
+		Mode:     0o644,
+		ModTime:  modTime,
+		Format:   tar.FormatPAX,
+	})
+
+	return w.tw, err
+}
+
+func (w *tarGzArchiveWriter) Close() error {
+	if err := w.tw.Close(); err != nil {
+		w.gw.Close()
+
+		return err
+	}
+
+	return w.gw.Close()
+}
+
+// archiveEntry is an entry read from an archive.
+type archiveEntry struct {
+	name    string
+	dir     bool
+	regular bool
+	reader  io.Reader
+}
+
+// walkArchive calls a function for each entry of an archive file, in order.
+func walkArchive(format ArchiveFormat, archivePath string, fn func(entry archiveEntry) error) error {
+	switch format {
+	case ZipArchiveFormat:
+		zr, err := zip.OpenReader(archivePath)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+
+		for _, f := range zr.File {
+			if err = walkZipEntry(f, fn); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case TarGzArchiveFormat:
+		archiveFile, err := os.Open(archivePath)
+		if err != nil {
+			return err
+		}
+		defer archiveFile.Close()
+
+		gr, err := gzip.NewReader(archiveFile)
+		if err != nil {
+			return err
+		}
+		defer gr.Close()
+
+		tr := tar.NewReader(gr)
+		for {
+			header, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			if err != nil {
+				return err
+			}
+
+			// pax and gnu headers are consumed by the reader, the remaining types are the entries ones
+			entry := archiveEntry{
+				name:    header.Name,
+				dir:     header.Typeflag == tar.TypeDir,
+				regular: header.Typeflag == tar.TypeReg,
+				reader:  tr,
+			}
+
+			if err = fn(entry); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported archive format %s", format)
+	}
+}
+
+func walkZipEntry(f *zip.File, fn func(entry archiveEntry) error) error {
+	entry := archiveEntry{
+		name:    f.Name,
+		dir:     f.Mode().IsDir(),
+		regular: f.Mode().IsRegular(),
+		reader:  strings.NewReader(""),
+	}
+
+	if entry.regular {
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+
+		entry.reader = rc
+	}
+
+	return fn(entry)
+}
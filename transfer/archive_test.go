@@ -0,0 +1,271 @@
+package transfer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type testArchiveEntry struct {
+	name     string
+	body     string
+	mode     fs.FileMode
+	linkname string
+	typeflag byte
+}
+
+func writeTestZip(t *testing.T, path string, entries []testArchiveEntry) {
+	t.Helper()
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	for _, entry := range entries {
+		header := &zip.FileHeader{Name: entry.name, Method: zip.Deflate}
+		header.SetMode(entry.mode | 0o644)
+
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = w.Write([]byte(entry.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err = zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeTestTarGz(t *testing.T, path string, entries []testArchiveEntry) {
+	t.Helper()
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	for _, entry := range entries {
+		typeflag := entry.typeflag
+		if typeflag == 0 {
+			typeflag = tar.TypeReg
+		}
+
+		header := &tar.Header{
+			Name:     entry.name,
+			Typeflag: typeflag,
+			Linkname: entry.linkname,
+			Mode:     0o644,
+		}
+		if typeflag == tar.TypeReg {
+			header.Size = int64(len(entry.body))
+		}
+
+		if err = tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = tw.Write([]byte(entry.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err = tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExtractArchive(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		format  ArchiveFormat
+		entries []testArchiveEntry
+		options []ExtractOption
+		want    []string
+		wantErr error
+	}{
+		{
+			name:   "zip files",
+			format: ZipArchiveFormat,
+			entries: []testArchiveEntry{
+				{name: "a.txt", body: "a"},
+				{name: "sub/b.txt", body: "b"},
+			},
+			want: []string{"a.txt", filepath.Join("sub", "b.txt")},
+		},
+		{
+			name:   "tar.gz files",
+			format: TarGzArchiveFormat,
+			entries: []testArchiveEntry{
+				{name: "sub/", typeflag: tar.TypeDir},
+				{name: "sub/b.txt", body: "b"},
+			},
+			want: []string{filepath.Join("sub", "b.txt")},
+		},
+		{
+			name:    "zip slip",
+			format:  ZipArchiveFormat,
+			entries: []testArchiveEntry{{name: "../evil.txt", body: "evil"}},
+			wantErr: ErrUnsafeArchiveEntry,
+		},
+		{
+			name:    "nested zip slip",
+			format:  ZipArchiveFormat,
+			entries: []testArchiveEntry{{name: "sub/../../evil.txt", body: "evil"}},
+			wantErr: ErrUnsafeArchiveEntry,
+		},
+		{
+			name:    "absolute path",
+			format:  TarGzArchiveFormat,
+			entries: []testArchiveEntry{{name: "/tmp/evil.txt", body: "evil"}},
+			wantErr: ErrUnsafeArchiveEntry,
+		},
+		{
+			name:    "tar.gz slip",
+			format:  TarGzArchiveFormat,
+			entries: []testArchiveEntry{{name: "../evil.txt", body: "evil"}},
+			wantErr: ErrUnsafeArchiveEntry,
+		},
+		{
+			name:    "zip symbolic link",
+			format:  ZipArchiveFormat,
+			entries: []testArchiveEntry{{name: "link", body: "/etc/passwd", mode: fs.ModeSymlink}},
+			wantErr: ErrUnsafeArchiveEntry,
+		},
+		{
+			name:   "tar.gz symbolic link",
+			format: TarGzArchiveFormat,
+			entries: []testArchiveEntry{
+				{name: "link", typeflag: tar.TypeSymlink, linkname: ".."},
+				{name: "link/evil.txt", body: "evil"},
+			},
+			wantErr: ErrUnsafeArchiveEntry,
+		},
+		{
+			name:    "tar.gz hard link",
+			format:  TarGzArchiveFormat,
+			entries: []testArchiveEntry{{name: "link", typeflag: tar.TypeLink, linkname: "/etc/passwd"}},
+			wantErr: ErrUnsafeArchiveEntry,
+		},
+		{
+			name:    "file too large",
+			format:  ZipArchiveFormat,
+			entries: []testArchiveEntry{{name: "a.txt", body: "0123456789"}},
+			options: []ExtractOption{WithExtractMaxFileSize(5)},
+			wantErr: ErrArchiveTooLarge,
+		},
+		{
+			name:   "archive too large",
+			format: TarGzArchiveFormat,
+			entries: []testArchiveEntry{
+				{name: "a.txt", body: "01234"},
+				{name: "b.txt", body: "56789"},
+			},
+			options: []ExtractOption{WithExtractMaxSize(8)},
+			wantErr: ErrArchiveTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			root := t.TempDir()
+			archivePath := filepath.Join(root, "archive"+tt.format.Extension())
+			dir := filepath.Join(root, "out")
+
+			if tt.format == ZipArchiveFormat {
+				writeTestZip(t, archivePath, tt.entries)
+			} else {
+				writeTestTarGz(t, archivePath, tt.entries)
+			}
+
+			paths, err := ExtractArchive(context.Background(), tt.format, archivePath, dir, tt.options...)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ExtractArchive() error = %v, want %v", err, tt.wantErr)
+				}
+
+				// a rejected archive leaves no extracted file, in or out of the directory
+				if _, err = os.Stat(filepath.Join(root, "evil.txt")); !errors.Is(err, fs.ErrNotExist) {
+					t.Errorf("file extracted out of the directory: %v", err)
+				}
+
+				entries, err := os.ReadDir(dir)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if len(entries) != 0 {
+					t.Errorf("ExtractArchive() left %d entries in the directory", len(entries))
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ExtractArchive() error = %v", err)
+			}
+
+			want := make([]string, len(tt.want))
+			for i, p := range tt.want {
+				want[i] = filepath.Join(dir, p)
+			}
+
+			if !reflect.DeepEqual(paths, want) {
+				t.Errorf("ExtractArchive() = %v, want %v", paths, want)
+			}
+		})
+	}
+}
+
+func TestExtractLimit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		options   ExtractOptions
+		extracted int64
+		want      int64
+	}{
+		{name: "no limit", options: ExtractOptions{}, extracted: 100, want: -1},
+		{name: "file limit", options: ExtractOptions{MaxFileSize: 10}, extracted: 100, want: 10},
+		{name: "remaining size", options: ExtractOptions{MaxSize: 100}, extracted: 40, want: 60},
+		{name: "file limit below remaining size", options: ExtractOptions{MaxFileSize: 10, MaxSize: 100}, extracted: 40, want: 10},
+		{name: "remaining size below file limit", options: ExtractOptions{MaxFileSize: 10, MaxSize: 100}, extracted: 95, want: 5},
+		{name: "size exhausted", options: ExtractOptions{MaxFileSize: 10, MaxSize: 100}, extracted: 100, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := extractLimit(tt.options, tt.extracted); got != tt.want {
+				t.Errorf("extractLimit() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// AuthError is returned when a server rejects the credentials of a user.
+type AuthError struct {
+	User string
+	Err  error
+}
+
+// Error returns the error message.
+func (e *AuthError) Error() string {
+	return fmt.Sprintf("unable to authenticate as [%s]: %v", e.User, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *AuthError) Unwrap() error {
+	return e.Err
+}
+
+// sshAuthFailed returns true if an SSH handshake error is the rejection of all the auth methods, which the ssh package
+// reports untyped: network failures during the authentication are not.
+func sshAuthFailed(err error) bool {
+	return strings.Contains(err.Error(), "ssh: unable to authenticate")
+}
+
+// NewAuthMethods returns the list of [ssh.AuthMethod] to authenticate with, built from the [Options] auth order.
+// Unavailable kinds (no key configured, no reachable agent, no password) are skipped.
+// Since the SSH client tries each method only once, the certificate, public key and agent kinds
+// are merged into a single public key method, placed at the position of the first of them, keeping their relative order.
+// The SSH agent is dialed once, on first use: the returned [io.Closer], nil without agent kind, closes its connection.
+func NewAuthMethods(o Options) ([]ssh.AuthMethod, io.Closer, error) {
+	order := o.AuthOrder
+	if len(order) == 0 {
+		order = DefaultAuthOrder
+	}
+
+	var auths []ssh.AuthMethod
+	var signerSources []func() ([]ssh.Signer, error)
+	var agentSource *agentSigners
+	publicKeyIndex := -1
+
+	for _, kind := range order {
+		switch kind {
+		case AgentAuthKind:
+			if agentSource == nil {
+				agentSource = &agentSigners{}
+			}
+
+			signerSources = append(signerSources, agentSource.Signers)
+
+			if publicKeyIndex < 0 {
+				publicKeyIndex = len(auths)
+				auths = append(auths, nil)
+			}
+		case CertificateAuthKind, PublicKeyAuthKind:
+			source, err := signerSource(kind, o)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if source == nil {
+				continue
+			}
+
+			signerSources = append(signerSources, source)
+
+			if publicKeyIndex < 0 {
+				publicKeyIndex = len(auths)
+				auths = append(auths, nil)
+			}
+		case KeyboardInteractiveAuthKind:
+			if o.Password != "" {
+				auths = append(auths, ssh.KeyboardInteractive(keyboardInteractiveChallenge(o.Password)))
+			}
+		case PasswordAuthKind:
+			if o.Password != "" {
+				auths = append(auths, ssh.Password(o.Password))
+			}
+		}
+	}
+
+	if publicKeyIndex >= 0 {
+		auths[publicKeyIndex] = ssh.PublicKeysCallback(func() ([]ssh.Signer, error) {
+			var signers []ssh.Signer
+			for _, source := range signerSources {
+				sourceSigners, err := source()
+				if err != nil {
+					return nil, err
+				}
+
+				signers = append(signers, sourceSigners...)
+			}
+
+			return signers, nil
+		})
+	}
+
+	if agentSource == nil {
+		return append(auths, o.AuthMethods...), nil, nil
+	}
+
+	return append(auths, o.AuthMethods...), agentSource, nil
+}
+
+// signerSource returns a source of [ssh.Signer] for a key [AuthKind], or nil if the kind is not configured.
+func signerSource(kind AuthKind, o Options) (func() ([]ssh.Signer, error), error) {
+	switch kind {
+	case CertificateAuthKind:
+		if o.KeyPath == "" || o.CertificatePath == "" {
+			return nil, nil
+		}
+
+		signer, err := loadCertificateSigner(o.KeyPath, o.KeyPassphrase, o.CertificatePath)
+		if err != nil {
+			return nil, err
+		}
+
+		return staticSigners(signer), nil
+	case PublicKeyAuthKind:
+		if o.KeyPath == "" {
+			return nil, nil
+		}
+
+		signer, err := loadSigner(o.KeyPath, o.KeyPassphrase)
+		if err != nil {
+			return nil, err
+		}
+
+		return staticSigners(signer), nil
+	default:
+		return nil, nil
+	}
+}
+
+func staticSigners(signers ...ssh.Signer) func() ([]ssh.Signer, error) {
+	return func() ([]ssh.Signer, error) {
+		return signers, nil
+	}
+}
+
+// agentSigners is a lazy source of the signers of the local SSH agent, reached through SSH_AUTH_SOCK.
+// The agent is dialed once, its connection being kept open for the signatures until closed.
+type agentSigners struct {
+	once    sync.Once
+	conn    net.Conn
+	signers []ssh.Signer
+}
+
+// Signers returns the signers of the local SSH agent, or none if no agent is reachable.
+func (a *agentSigners) Signers() ([]ssh.Signer, error) {
+	a.once.Do(func() {
+		socket := os.Getenv("SSH_AUTH_SOCK")
+		if socket == "" {
+			return
+		}
+
+		conn, err := net.Dial("unix", socket)
+		if err != nil {
+			return
+		}
+
+		signers, err := agent.NewClient(conn).Signers()
+		if err != nil {
+			conn.Close()
+
+			return
+		}
+
+		a.conn = conn
+		a.signers = signers
+	})
+
+	return a.signers, nil
+}
+
+// Close closes the agent connection, if dialed.
+func (a *agentSigners) Close() error {
+	if a.conn == nil {
+		return nil
+	}
+
+	return a.conn.Close()
+}
+
+// loadSigner returns an [ssh.Signer] from a PEM or OpenSSH private key file, with an optional passphrase.
+func loadSigner(keyPath string, passphrase string) (ssh.Signer, error) {
+	key, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read private key [%s]: %w", keyPath, err)
+	}
+
+	var signer ssh.Signer
+	if passphrase != "" {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(key, []byte(passphrase))
+	} else {
+		signer, err = ssh.ParsePrivateKey(key)
+	}
+
+	if err != nil {
+		var missingErr *ssh.PassphraseMissingError
+		if errors.As(err, &missingErr) {
+			return nil, fmt.Errorf("private key [%s] is encrypted, a passphrase is required: %w", keyPath, err)
+		}
+
+		return nil, fmt.Errorf("unable to parse private key [%s]: %w", keyPath, err)
+	}
+
+	return signer, nil
+}
+
+// loadCertificateSigner returns an [ssh.Signer] presenting an SSH certificate signed for a private key.
+func loadCertificateSigner(keyPath string, passphrase string, certificatePath string) (ssh.Signer, error) {
+	signer, err := loadSigner(keyPath, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(certificatePath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read certificate [%s]: %w", certificatePath, err)
+	}
+
+	publicKey, _, _, _, err := ssh.ParseAuthorizedKey(content)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse certificate [%s]: %w", certificatePath, err)
+	}
+
+	certificate, ok := publicKey.(*ssh.Certificate)
+	if !ok {
+		return nil, fmt.Errorf("file [%s] is not an ssh certificate", certificatePath)
+	}
+
+	certSigner, err := ssh.NewCertSigner(certificate, signer)
+	if err != nil {
+		return nil, fmt.Errorf("unable to use certificate [%s] with private key [%s]: %w", certificatePath, keyPath, err)
+	}
+
+	return certSigner, nil
+}
+
+// keyboardInteractiveChallenge answers all the server questions with the password.
+func keyboardInteractiveChallenge(password string) ssh.KeyboardInteractiveChallenge {
+	return func(name, instruction string, questions []string, echos []bool) ([]string, error) {
+		answers := make([]string, len(questions))
+		for i := range questions {
+			answers[i] = password
+		}
+
+		return answers, nil
+	}
+}
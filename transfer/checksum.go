@@ -0,0 +1,70 @@
+package transfer
+
+import (
+	"crypto/md5" //nolint:gosec
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+)
+
+// ChecksumError is returned when a transferred file checksum does not match the expected one.
+type ChecksumError struct {
+	Path      string
+	Algorithm ChecksumAlgorithm
+	Expected  string
+	Actual    string
+}
+
+// Error returns the error message.
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("%s checksum mismatch for [%s]: expected %s, got %s", e.Algorithm, e.Path, e.Expected, e.Actual)
+}
+
+// NewHash returns a new [hash.Hash] for a [ChecksumAlgorithm], or nil for no checksum.
+func NewHash(algorithm ChecksumAlgorithm) hash.Hash {
+	switch algorithm {
+	case Sha256ChecksumAlgorithm:
+		return sha256.New()
+	case Md5ChecksumAlgorithm:
+		return md5.New() //nolint:gosec
+	default:
+		return nil
+	}
+}
+
+// Digest returns the hex encoded digest of a reader content, for a [ChecksumAlgorithm].
+func Digest(r io.Reader, algorithm ChecksumAlgorithm) (string, error) {
+	h := NewHash(algorithm)
+	if h == nil {
+		return "", nil
+	}
+
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// SidecarPath returns the checksum sidecar file path of a file path, for a [ChecksumAlgorithm] (e.g. file.csv.sha256).
+func SidecarPath(filePath string, algorithm ChecksumAlgorithm) string {
+	return filePath + "." + algorithm.String()
+}
+
+// SidecarContent returns the checksum sidecar file content, in the sha256sum / md5sum format.
+func SidecarContent(digest string, fileName string) []byte {
+	return []byte(fmt.Sprintf("%s  %s\n", digest, fileName))
+}
+
+// ParseSidecarContent returns the digest from a checksum sidecar file content.
+func ParseSidecarContent(content []byte) (string, error) {
+	fields := strings.Fields(string(content))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("empty checksum sidecar")
+	}
+
+	return strings.ToLower(fields[0]), nil
+}
@@ -0,0 +1,29 @@
+package transfer
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"time"
+)
+
+// Client is the interface for file transfer clients.
+type Client interface {
+	List(ctx context.Context, dir string) ([]fs.FileInfo, error)
+	Walk(ctx context.Context, dir string) ([]WalkEntry, error)
+	Upload(ctx context.Context, localPath string, remotePath string, options ...TransferOption) (*TransferResult, error)
+	Download(ctx context.Context, remotePath string, localPath string, options ...TransferOption) (*TransferResult, error)
+	Move(ctx context.Context, sourcePath string, destinationPath string) error
+	Remove(ctx context.Context, path string) error
+	Stat(ctx context.Context, path string) (fs.FileInfo, error)
+	Mkdir(ctx context.Context, path string) error
+	Open(ctx context.Context, path string) (io.ReadCloser, error)
+	Chtimes(ctx context.Context, path string, modTime time.Time) error
+	Close() error
+}
+
+// WalkEntry is a file of a directory tree, with its slash separated path relative to the tree root.
+type WalkEntry struct {
+	Path string
+	Info fs.FileInfo
+}
@@ -0,0 +1,102 @@
+package transfer
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// CompressStage is a [Stage] compressing files, like before uploading them.
+type CompressStage struct {
+	algorithm CompressionAlgorithm
+}
+
+// NewCompressStage returns a new [CompressStage] for a [CompressionAlgorithm].
+func NewCompressStage(algorithm CompressionAlgorithm) (*CompressStage, error) {
+	if algorithm == NoCompressionAlgorithm {
+		return nil, fmt.Errorf("missing compression algorithm")
+	}
+
+	return &CompressStage{
+		algorithm: algorithm,
+	}, nil
+}
+
+// Name returns the stage name.
+func (s *CompressStage) Name() string {
+	return fmt.Sprintf("%s-compress", s.algorithm)
+}
+
+// Process streams the compressed content of a reader to a writer.
+func (s *CompressStage) Process(ctx context.Context, w io.Writer, r io.Reader) error {
+	var cw io.WriteCloser
+	switch s.algorithm {
+	case ZstdCompressionAlgorithm:
+		zw, err := zstd.NewWriter(w)
+		if err != nil {
+			return err
+		}
+
+		cw = zw
+	default:
+		cw = gzip.NewWriter(w)
+	}
+
+	if _, err := io.Copy(cw, r); err != nil {
+		cw.Close()
+
+		return err
+	}
+
+	return cw.Close()
+}
+
+// DecompressStage is a [Stage] decompressing files, like once downloaded.
+type DecompressStage struct {
+	algorithm CompressionAlgorithm
+}
+
+// NewDecompressStage returns a new [DecompressStage] for a [CompressionAlgorithm].
+func NewDecompressStage(algorithm CompressionAlgorithm) (*DecompressStage, error) {
+	if algorithm == NoCompressionAlgorithm {
+		return nil, fmt.Errorf("missing compression algorithm")
+	}
+
+	return &DecompressStage{
+		algorithm: algorithm,
+	}, nil
+}
+
+// Name returns the stage name.
+func (s *DecompressStage) Name() string {
+	return fmt.Sprintf("%s-decompress", s.algorithm)
+}
+
+// Process streams the decompressed content of a reader to a writer.
+func (s *DecompressStage) Process(ctx context.Context, w io.Writer, r io.Reader) error {
+	switch s.algorithm {
+	case ZstdCompressionAlgorithm:
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+
+		_, err = io.Copy(w, zr)
+
+		return err
+	default:
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer gr.Close()
+
+		_, err = io.Copy(w, gr)
+
+		return err
+	}
+}
@@ -0,0 +1,52 @@
+package transfer
+
+import (
+	"context"
+	"io"
+)
+
+// ContextReader is an [io.Reader] that stops reading once its [context.Context] is done.
+type ContextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+// NewContextReader returns a [ContextReader] for a provided [context.Context] and [io.Reader].
+func NewContextReader(ctx context.Context, reader io.Reader) *ContextReader {
+	return &ContextReader{
+		ctx:    ctx,
+		reader: reader,
+	}
+}
+
+// Read reads from the underlying [io.Reader], or returns the context error if it is done.
+func (r *ContextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return r.reader.Read(p)
+}
+
+// ContextWriter is an [io.Writer] that stops writing once its [context.Context] is done.
+type ContextWriter struct {
+	ctx    context.Context
+	writer io.Writer
+}
+
+// NewContextWriter returns a [ContextWriter] for a provided [context.Context] and [io.Writer].
+func NewContextWriter(ctx context.Context, writer io.Writer) *ContextWriter {
+	return &ContextWriter{
+		ctx:    ctx,
+		writer: writer,
+	}
+}
+
+// Write writes to the underlying [io.Writer], or returns the context error if it is done.
+func (w *ContextWriter) Write(p []byte) (int, error) {
+	if err := w.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return w.writer.Write(p)
+}
@@ -0,0 +1,71 @@
+package transfer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"time"
+)
+
+// Copy copies a file between two [Client], like from an SFTP endpoint to an S3 bucket, and returns a [TransferResult].
+// The file is staged in a local file, so that both transfers keep their resume, atomic, checksum and sidecar semantics:
+// with resume, the staged file of a failed copy is kept, and resumed by the next attempt.
+func Copy(ctx context.Context, src Client, sourcePath string, dst Client, destinationPath string, options ...TransferOption) (*TransferResult, error) {
+	start := time.Now()
+
+	appliedOpts := DefaultTransferOptions()
+	for _, applyOpt := range options {
+		applyOpt(&appliedOpts)
+	}
+
+	// the staged file name is stable across attempts, for the download to be resumed
+	stagedPath := stagingPath(appliedOpts.StagingDir, sourcePath, destinationPath, path.Base(sourcePath))
+
+	downloaded, err := src.Download(ctx, sourcePath, stagedPath, options...)
+	if err != nil {
+		if !appliedOpts.Resume {
+			os.Remove(stagedPath)
+		}
+
+		return nil, fmt.Errorf("unable to copy file [%s] to [%s]: %w", sourcePath, destinationPath, err)
+	}
+
+	uploaded, err := dst.Upload(ctx, stagedPath, destinationPath, options...)
+	if err != nil {
+		if !appliedOpts.Resume {
+			os.Remove(stagedPath)
+		}
+
+		return nil, fmt.Errorf("unable to copy file [%s] to [%s]: %w", sourcePath, destinationPath, err)
+	}
+
+	os.Remove(stagedPath)
+
+	if downloaded.Digest != "" && uploaded.Digest != "" && downloaded.Algorithm == uploaded.Algorithm && downloaded.Digest != uploaded.Digest {
+		return nil, &ChecksumError{
+			Path:      destinationPath,
+			Algorithm: uploaded.Algorithm,
+			Expected:  downloaded.Digest,
+			Actual:    uploaded.Digest,
+		}
+	}
+
+	result := &TransferResult{
+		Source:      sourcePath,
+		Destination: destinationPath,
+		Bytes:       uploaded.Bytes,
+		Offset:      downloaded.Offset,
+		Duration:    time.Since(start),
+		Algorithm:   uploaded.Algorithm,
+		Digest:      uploaded.Digest,
+		Verified:    uploaded.Verified,
+	}
+
+	if result.Digest == "" {
+		result.Algorithm = downloaded.Algorithm
+		result.Digest = downloaded.Digest
+	}
+
+	return result, nil
+}
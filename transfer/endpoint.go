@@ -0,0 +1,110 @@
+package transfer
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Endpoint is a named remote endpoint configuration.
+type Endpoint struct {
+	Name                  string
+	Protocol              Protocol
+	Host                  string
+	Port                  int
+	User                  string
+	Password              string
+	KeyPath               string
+	KeyPassphrase         string
+	CertificatePath       string
+	AuthOrder             []AuthKind
+	KnownHostsFile        string
+	HostKeyMode           HostKeyMode
+	Fingerprints          []string
+	FtpMode               FtpMode
+	ActiveAddress         string
+	TLSCertificatePath    string
+	TLSKeyPath            string
+	TLSCAPath             string
+	TLSServerName         string
+	TLSInsecureSkipVerify bool
+	Root                  string
+	Bucket                string
+	Region                string
+	S3Secure              bool
+	S3PathStyle           bool
+	Timeout               time.Duration
+	ChunkSize             int
+	ConcurrentRequests    int
+	Workers               int
+	Connections           int
+	KeepAlive             time.Duration
+	IdleTimeout           time.Duration
+	MaxLifetime           time.Duration
+	Validate              bool
+	Warmup                bool
+	RetryMaxAttempts      int
+	RetryBaseDelay        time.Duration
+	RetryMaxDelay         time.Duration
+	RetryJitter           float64
+	Resume                bool
+	ResumeVerify          ResumeVerifyMode
+	Atomic                bool
+	TemporaryPattern      string
+	StaleAfter            time.Duration
+	Checksum              ChecksumAlgorithm
+	ChecksumVerify        ChecksumVerifyMode
+	ChecksumSidecar       bool
+	UploadStages          []Stage
+	DownloadStages        []Stage
+	Dirs                  map[string]string
+}
+
+// Dir returns the remote base directory registered for a name, or an empty string if not found.
+func (e *Endpoint) Dir(name string) string {
+	return e.Dirs[name]
+}
+
+// Address returns the endpoint address, in the host:port form.
+func (e *Endpoint) Address() string {
+	return fmt.Sprintf("%s:%d", e.Host, e.Port)
+}
+
+// EndpointRegistry is the registry of named [Endpoint].
+type EndpointRegistry struct {
+	endpoints map[string]*Endpoint
+}
+
+// NewEndpointRegistry returns a new [EndpointRegistry] for a provided list of [Endpoint].
+func NewEndpointRegistry(endpoints ...*Endpoint) *EndpointRegistry {
+	registry := &EndpointRegistry{
+		endpoints: make(map[string]*Endpoint),
+	}
+
+	for _, endpoint := range endpoints {
+		registry.endpoints[endpoint.Name] = endpoint
+	}
+
+	return registry
+}
+
+// Names returns the sorted names of the registered [Endpoint].
+func (r *EndpointRegistry) Names() []string {
+	names := make([]string, 0, len(r.endpoints))
+	for name := range r.endpoints {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Get returns a registered [Endpoint] by name.
+func (r *EndpointRegistry) Get(name string) (*Endpoint, error) {
+	if endpoint, ok := r.endpoints[name]; ok {
+		return endpoint, nil
+	}
+
+	return nil, fmt.Errorf("sftp endpoint with name %s was not found", name)
+}
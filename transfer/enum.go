@@ -0,0 +1,513 @@
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// HostKeyMode is an enum for the supported server host key verification modes.
+type HostKeyMode int
+
+const (
+	KnownHostsHostKeyMode HostKeyMode = iota
+	FingerprintHostKeyMode
+	TofuHostKeyMode
+	InsecureHostKeyMode
+)
+
+// String returns a string representation of a [HostKeyMode].
+func (m HostKeyMode) String() string {
+	switch m {
+	case FingerprintHostKeyMode:
+		return "fingerprint"
+	case TofuHostKeyMode:
+		return "tofu"
+	case InsecureHostKeyMode:
+		return "insecure"
+	default:
+		return "known_hosts"
+	}
+}
+
+// FetchHostKeyMode returns a [HostKeyMode] for a given value.
+func FetchHostKeyMode(m string) HostKeyMode {
+	switch strings.ToLower(m) {
+	case "fingerprint":
+		return FingerprintHostKeyMode
+	case "tofu":
+		return TofuHostKeyMode
+	case "insecure":
+		return InsecureHostKeyMode
+	default:
+		return KnownHostsHostKeyMode
+	}
+}
+
+// AuthKind is an enum for the supported SSH authentication kinds.
+type AuthKind int
+
+const (
+	PublicKeyAuthKind AuthKind = iota
+	CertificateAuthKind
+	AgentAuthKind
+	KeyboardInteractiveAuthKind
+	PasswordAuthKind
+)
+
+// DefaultAuthOrder is the default order in which the [AuthKind] are tried.
+var DefaultAuthOrder = []AuthKind{
+	CertificateAuthKind,
+	PublicKeyAuthKind,
+	AgentAuthKind,
+	PasswordAuthKind,
+	KeyboardInteractiveAuthKind,
+}
+
+// String returns a string representation of an [AuthKind].
+func (k AuthKind) String() string {
+	switch k {
+	case CertificateAuthKind:
+		return "certificate"
+	case AgentAuthKind:
+		return "agent"
+	case KeyboardInteractiveAuthKind:
+		return "keyboard-interactive"
+	case PasswordAuthKind:
+		return "password"
+	default:
+		return "publickey"
+	}
+}
+
+// FetchAuthKind returns an [AuthKind] for a given value, [PublicKeyAuthKind] if unknown.
+func FetchAuthKind(k string) AuthKind {
+	kind, _ := ParseAuthKind(k)
+
+	return kind
+}
+
+// ParseAuthKind returns an [AuthKind] for a given value, or an error if unknown.
+func ParseAuthKind(k string) (AuthKind, error) {
+	switch strings.ToLower(k) {
+	case "publickey":
+		return PublicKeyAuthKind, nil
+	case "certificate":
+		return CertificateAuthKind, nil
+	case "agent":
+		return AgentAuthKind, nil
+	case "keyboard-interactive":
+		return KeyboardInteractiveAuthKind, nil
+	case "password":
+		return PasswordAuthKind, nil
+	default:
+		return PublicKeyAuthKind, fmt.Errorf("unknown auth kind %q", k)
+	}
+}
+
+// ResumeVerifyMode is an enum for the supported partial file prefix verification modes, used when resuming transfers.
+type ResumeVerifyMode int
+
+const (
+	HashResumeVerifyMode ResumeVerifyMode = iota
+	SizeResumeVerifyMode
+)
+
+// String returns a string representation of a [ResumeVerifyMode].
+func (m ResumeVerifyMode) String() string {
+	switch m {
+	case SizeResumeVerifyMode:
+		return "size"
+	default:
+		return "hash"
+	}
+}
+
+// FetchResumeVerifyMode returns a [ResumeVerifyMode] for a given value.
+func FetchResumeVerifyMode(m string) ResumeVerifyMode {
+	switch strings.ToLower(m) {
+	case "size":
+		return SizeResumeVerifyMode
+	default:
+		return HashResumeVerifyMode
+	}
+}
+
+// ChecksumAlgorithm is an enum for the supported transfer checksum algorithms.
+type ChecksumAlgorithm int
+
+const (
+	NoChecksumAlgorithm ChecksumAlgorithm = iota
+	Sha256ChecksumAlgorithm
+	Md5ChecksumAlgorithm
+)
+
+// String returns a string representation of a [ChecksumAlgorithm].
+func (a ChecksumAlgorithm) String() string {
+	switch a {
+	case Sha256ChecksumAlgorithm:
+		return "sha256"
+	case Md5ChecksumAlgorithm:
+		return "md5"
+	default:
+		return "none"
+	}
+}
+
+// FetchChecksumAlgorithm returns a [ChecksumAlgorithm] for a given value, [NoChecksumAlgorithm] if unknown.
+func FetchChecksumAlgorithm(a string) ChecksumAlgorithm {
+	algorithm, _ := ParseChecksumAlgorithm(a)
+
+	return algorithm
+}
+
+// ParseChecksumAlgorithm returns a [ChecksumAlgorithm] for a given value, [NoChecksumAlgorithm] if empty, or an error if unknown.
+func ParseChecksumAlgorithm(a string) (ChecksumAlgorithm, error) {
+	switch strings.ToLower(a) {
+	case "", "none":
+		return NoChecksumAlgorithm, nil
+	case "sha256", "sha-256":
+		return Sha256ChecksumAlgorithm, nil
+	case "md5":
+		return Md5ChecksumAlgorithm, nil
+	default:
+		return NoChecksumAlgorithm, fmt.Errorf("unknown checksum algorithm %q", a)
+	}
+}
+
+// ChecksumVerifyMode is an enum for the supported transfer checksum verification modes.
+type ChecksumVerifyMode int
+
+const (
+	NoChecksumVerifyMode ChecksumVerifyMode = iota
+	RereadChecksumVerifyMode
+)
+
+// String returns a string representation of a [ChecksumVerifyMode].
+func (m ChecksumVerifyMode) String() string {
+	switch m {
+	case RereadChecksumVerifyMode:
+		return "reread"
+	default:
+		return "none"
+	}
+}
+
+// FetchChecksumVerifyMode returns a [ChecksumVerifyMode] for a given value, [NoChecksumVerifyMode] if unknown.
+func FetchChecksumVerifyMode(m string) ChecksumVerifyMode {
+	mode, _ := ParseChecksumVerifyMode(m)
+
+	return mode
+}
+
+// ParseChecksumVerifyMode returns a [ChecksumVerifyMode] for a given value, [NoChecksumVerifyMode] if empty, or an error
+// if unknown. The check-file extension is not exposed by the SFTP client, "check-file" is rejected rather than re-read.
+func ParseChecksumVerifyMode(m string) (ChecksumVerifyMode, error) {
+	switch strings.ToLower(m) {
+	case "", "none":
+		return NoChecksumVerifyMode, nil
+	case "reread":
+		return RereadChecksumVerifyMode, nil
+	case "check-file":
+		return NoChecksumVerifyMode, errors.New(`checksum verify mode "check-file" is not supported, use "reread"`)
+	default:
+		return NoChecksumVerifyMode, fmt.Errorf("unknown checksum verify mode %q", m)
+	}
+}
+
+// Direction is an enum for the transfer directions.
+type Direction int
+
+const (
+	UploadDirection Direction = iota
+	DownloadDirection
+)
+
+// String returns a string representation of a [Direction].
+func (d Direction) String() string {
+	switch d {
+	case DownloadDirection:
+		return "download"
+	default:
+		return "upload"
+	}
+}
+
+// FetchDirection returns a [Direction] for a given value, [UploadDirection] if unknown.
+func FetchDirection(d string) Direction {
+	direction, _ := ParseDirection(d)
+
+	return direction
+}
+
+// ParseDirection returns a [Direction] for a given value, [UploadDirection] if empty, or an error if unknown.
+func ParseDirection(d string) (Direction, error) {
+	switch strings.ToLower(d) {
+	case "", "upload":
+		return UploadDirection, nil
+	case "download":
+		return DownloadDirection, nil
+	default:
+		return UploadDirection, fmt.Errorf("unknown direction %q", d)
+	}
+}
+
+// CompareMode is an enum for the supported [Sync] file comparison modes.
+type CompareMode int
+
+const (
+	ModTimeCompareMode CompareMode = iota
+	SizeCompareMode
+	ChecksumCompareMode
+)
+
+// String returns a string representation of a [CompareMode].
+func (m CompareMode) String() string {
+	switch m {
+	case SizeCompareMode:
+		return "size"
+	case ChecksumCompareMode:
+		return "checksum"
+	default:
+		return "mtime"
+	}
+}
+
+// FetchCompareMode returns a [CompareMode] for a given value, [ModTimeCompareMode] if unknown.
+func FetchCompareMode(m string) CompareMode {
+	mode, _ := ParseCompareMode(m)
+
+	return mode
+}
+
+// ParseCompareMode returns a [CompareMode] for a given value, [ModTimeCompareMode] if empty, or an error if unknown.
+func ParseCompareMode(m string) (CompareMode, error) {
+	switch strings.ToLower(m) {
+	case "", "mtime":
+		return ModTimeCompareMode, nil
+	case "size":
+		return SizeCompareMode, nil
+	case "checksum", "hash":
+		return ChecksumCompareMode, nil
+	default:
+		return ModTimeCompareMode, fmt.Errorf("unknown compare mode %q", m)
+	}
+}
+
+// SyncActionKind is an enum for the [Sync] actions.
+type SyncActionKind int
+
+const (
+	TransferSyncAction SyncActionKind = iota
+	DeleteSyncAction
+)
+
+// String returns a string representation of a [SyncActionKind].
+func (k SyncActionKind) String() string {
+	switch k {
+	case DeleteSyncAction:
+		return "delete"
+	default:
+		return "transfer"
+	}
+}
+
+// Protocol is an enum for the supported transfer protocols, selected by the endpoints URL scheme.
+type Protocol int
+
+const (
+	SftpProtocol Protocol = iota
+	FtpProtocol
+	FtpsProtocol
+	FtpesProtocol
+	LocalProtocol
+	S3Protocol
+)
+
+// String returns a string representation of a [Protocol], also used as URL scheme.
+func (p Protocol) String() string {
+	switch p {
+	case FtpProtocol:
+		return "ftp"
+	case FtpsProtocol:
+		return "ftps"
+	case FtpesProtocol:
+		return "ftpes"
+	case LocalProtocol:
+		return "local"
+	case S3Protocol:
+		return "s3"
+	default:
+		return "sftp"
+	}
+}
+
+// DefaultPort returns the default port of a [Protocol], or 0 if it has none (local) or depends on the scheme (S3).
+func (p Protocol) DefaultPort() int {
+	switch p {
+	case FtpProtocol, FtpesProtocol:
+		return DefaultFtpPort
+	case FtpsProtocol:
+		return DefaultFtpsImplicitPort
+	case LocalProtocol, S3Protocol:
+		return 0
+	default:
+		return DefaultSftpPort
+	}
+}
+
+// IsFtp returns true if a [Protocol] is FTP, with or without TLS.
+func (p Protocol) IsFtp() bool {
+	return p == FtpProtocol || p == FtpsProtocol || p == FtpesProtocol
+}
+
+// FetchProtocol returns a [Protocol] for a given value: ftps is FTP over implicit TLS, ftpes FTP over explicit TLS,
+// local a local (or mounted) directory, and s3 an S3 compatible object storage bucket.
+func FetchProtocol(p string) Protocol {
+	switch strings.ToLower(p) {
+	case "ftp":
+		return FtpProtocol
+	case "ftps":
+		return FtpsProtocol
+	case "ftpes":
+		return FtpesProtocol
+	case "local":
+		return LocalProtocol
+	case "s3":
+		return S3Protocol
+	default:
+		return SftpProtocol
+	}
+}
+
+// FtpMode is an enum for the supported FTP data connection modes.
+type FtpMode int
+
+const (
+	PassiveFtpMode FtpMode = iota
+	ActiveFtpMode
+)
+
+// String returns a string representation of a [FtpMode].
+func (m FtpMode) String() string {
+	switch m {
+	case ActiveFtpMode:
+		return "active"
+	default:
+		return "passive"
+	}
+}
+
+// FetchFtpMode returns a [FtpMode] for a given value.
+func FetchFtpMode(m string) FtpMode {
+	switch strings.ToLower(m) {
+	case "active":
+		return ActiveFtpMode
+	default:
+		return PassiveFtpMode
+	}
+}
+
+// CompressionAlgorithm is an enum for the supported file compression algorithms.
+type CompressionAlgorithm int
+
+const (
+	NoCompressionAlgorithm CompressionAlgorithm = iota
+	GzipCompressionAlgorithm
+	ZstdCompressionAlgorithm
+)
+
+// String returns a string representation of a [CompressionAlgorithm].
+func (a CompressionAlgorithm) String() string {
+	switch a {
+	case GzipCompressionAlgorithm:
+		return "gzip"
+	case ZstdCompressionAlgorithm:
+		return "zstd"
+	default:
+		return "none"
+	}
+}
+
+// Extension returns the file name extension of a [CompressionAlgorithm], or an empty string for no compression.
+func (a CompressionAlgorithm) Extension() string {
+	switch a {
+	case GzipCompressionAlgorithm:
+		return ".gz"
+	case ZstdCompressionAlgorithm:
+		return ".zst"
+	default:
+		return ""
+	}
+}
+
+// FetchCompressionAlgorithm returns a [CompressionAlgorithm] for a given value.
+func FetchCompressionAlgorithm(a string) CompressionAlgorithm {
+	switch strings.ToLower(a) {
+	case "gzip", "gz":
+		return GzipCompressionAlgorithm
+	case "zstd", "zst":
+		return ZstdCompressionAlgorithm
+	default:
+		return NoCompressionAlgorithm
+	}
+}
+
+// ArchiveFormat is an enum for the supported archive formats, bundling several files.
+type ArchiveFormat int
+
+const (
+	NoArchiveFormat ArchiveFormat = iota
+	ZipArchiveFormat
+	TarGzArchiveFormat
+)
+
+// String returns a string representation of an [ArchiveFormat].
+func (f ArchiveFormat) String() string {
+	switch f {
+	case ZipArchiveFormat:
+		return "zip"
+	case TarGzArchiveFormat:
+		return "tar.gz"
+	default:
+		return "none"
+	}
+}
+
+// Extension returns the file name extension of an [ArchiveFormat], or an empty string for no archive.
+func (f ArchiveFormat) Extension() string {
+	switch f {
+	case ZipArchiveFormat:
+		return ".zip"
+	case TarGzArchiveFormat:
+		return ".tar.gz"
+	default:
+		return ""
+	}
+}
+
+// FetchArchiveFormat returns an [ArchiveFormat] for a given value.
+func FetchArchiveFormat(f string) ArchiveFormat {
+	switch strings.ToLower(f) {
+	case "zip":
+		return ZipArchiveFormat
+	case "tar.gz", "tgz", "targz":
+		return TarGzArchiveFormat
+	default:
+		return NoArchiveFormat
+	}
+}
+
+// DetectArchiveFormat returns the [ArchiveFormat] of a file name, by its extension.
+func DetectArchiveFormat(name string) ArchiveFormat {
+	name = strings.ToLower(name)
+
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		return ZipArchiveFormat
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return TarGzArchiveFormat
+	default:
+		return NoArchiveFormat
+	}
+}
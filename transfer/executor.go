@@ -0,0 +1,200 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Task is a unit of work of the [Executor], running with a [Client] session acquired from its [Pool].
+// A task returning neither a [TransferResult] nor an error is reported as skipped.
+type Task struct {
+	Name string
+	Run  func(ctx context.Context, client Client) (*TransferResult, error)
+}
+
+// TaskResult is the result of a [Task] execution.
+type TaskResult struct {
+	Name     string
+	Result   *TransferResult
+	Attempts int
+	Err      error
+}
+
+// Progress is the aggregated progress of an [Executor] execution, reported after each [Task].
+type Progress struct {
+	Total     int
+	Done      int
+	Succeeded int
+	Failed    int
+	Skipped   int
+	Retries   int
+	Bytes     int64
+}
+
+// ExecutionReport is the aggregated result of an [Executor] execution.
+type ExecutionReport struct {
+	Progress
+	Duration time.Duration
+	Results  []TaskResult
+}
+
+// Err returns the joined errors of the failed tasks, or nil if none failed.
+func (r *ExecutionReport) Err() error {
+	var errs []error
+	for _, result := range r.Results {
+		if result.Err != nil {
+			errs = append(errs, result.Err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ExecutorOptions are options for the [Executor].
+type ExecutorOptions struct {
+	Workers  int
+	Progress func(Progress)
+}
+
+// ExecutorOption are functional options for the [Executor].
+type ExecutorOption func(o *ExecutorOptions)
+
+// WithWorkers is used to specify the number of workers, bounded by the [Pool] size (default).
+func WithWorkers(n int) ExecutorOption {
+	return func(o *ExecutorOptions) {
+		o.Workers = n
+	}
+}
+
+// WithProgress is used to specify a function receiving the [Progress] after each [Task], called sequentially.
+func WithProgress(fn func(Progress)) ExecutorOption {
+	return func(o *ExecutorOptions) {
+		o.Progress = fn
+	}
+}
+
+// Executor fans tasks out to workers sharing the sessions of a [Pool].
+type Executor struct {
+	pool    *Pool
+	options ExecutorOptions
+}
+
+// NewExecutor returns a new [Executor] for a [Pool], and accepts a list of [ExecutorOption].
+func NewExecutor(pool *Pool, options ...ExecutorOption) *Executor {
+	appliedOpts := ExecutorOptions{
+		Workers: pool.Size(),
+	}
+	for _, opt := range options {
+		opt(&appliedOpts)
+	}
+
+	if appliedOpts.Workers <= 0 || appliedOpts.Workers > pool.Size() {
+		appliedOpts.Workers = pool.Size()
+	}
+
+	return &Executor{
+		pool:    pool,
+		options: appliedOpts,
+	}
+}
+
+// Execute runs the tasks, and returns their [ExecutionReport] once all done, with the results in the tasks order.
+// Tasks not started before the context cancellation are reported as failed with the context error.
+func (e *Executor) Execute(ctx context.Context, tasks []Task) *ExecutionReport {
+	start := time.Now()
+
+	report := &ExecutionReport{
+		Progress: Progress{Total: len(tasks)},
+		Results:  make([]TaskResult, len(tasks)),
+	}
+
+	if len(tasks) == 0 {
+		return report
+	}
+
+	indexes := make(chan int)
+	go func() {
+		defer close(indexes)
+
+		for i := range tasks {
+			indexes <- i
+		}
+	}()
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+
+	workers := e.options.Workers
+	if workers > len(tasks) {
+		workers = len(tasks)
+	}
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				result := e.run(ctx, tasks[i])
+
+				mutex.Lock()
+				report.Results[i] = result
+				report.Done++
+
+				if result.Attempts > 1 {
+					report.Retries += result.Attempts - 1
+				}
+
+				switch {
+				case result.Err != nil:
+					report.Failed++
+				case result.Result == nil:
+					report.Skipped++
+				default:
+					report.Succeeded++
+					report.Bytes += result.Result.Bytes
+				}
+
+				if e.options.Progress != nil {
+					e.options.Progress(report.Progress)
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	report.Duration = time.Since(start)
+
+	return report
+}
+
+// run runs a task with a session of the pool, retried with a new session while failing with a retryable error.
+func (e *Executor) run(ctx context.Context, task Task) TaskResult {
+	if err := ctx.Err(); err != nil {
+		return TaskResult{Name: task.Name, Err: err}
+	}
+
+	for attempt := 1; ; attempt++ {
+		client, err := e.pool.Acquire(ctx)
+		if err != nil {
+			return TaskResult{Name: task.Name, Attempts: attempt, Err: err}
+		}
+
+		result, err := task.Run(ctx, client)
+
+		e.pool.Recycle(client, err)
+
+		if err == nil {
+			return TaskResult{Name: task.Name, Result: result, Attempts: attempt}
+		}
+
+		if err = e.pool.Retrier().Wait(ctx, "task "+task.Name, attempt, err); err != nil {
+			return TaskResult{Name: task.Name, Attempts: attempt, Err: err}
+		}
+	}
+}
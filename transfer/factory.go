@@ -0,0 +1,340 @@
+package transfer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// SftpClientFactory is the interface for [Client] factories.
+type SftpClientFactory interface {
+	Create(options ...SftpClientOption) (Client, error)
+}
+
+// ClientFactory is an alias of [SftpClientFactory], whose implementations also create FTP, local and S3 [Client].
+type ClientFactory = SftpClientFactory
+
+// DefaultClientFactory is the [SftpClientFactory] implementation creating the [Client] of the configured [Protocol]:
+// SFTP ones with a [DefaultSftpClientFactory], FTP ones with a [DefaultFtpClientFactory],
+// local ones with a [DefaultLocalClientFactory], and S3 ones with a [DefaultS3ClientFactory].
+type DefaultClientFactory struct {
+	sftpFactory  SftpClientFactory
+	ftpFactory   SftpClientFactory
+	localFactory SftpClientFactory
+	s3Factory    SftpClientFactory
+}
+
+// NewDefaultClientFactory returns a [DefaultClientFactory], implementing [SftpClientFactory].
+func NewDefaultClientFactory() SftpClientFactory {
+	return &DefaultClientFactory{
+		sftpFactory:  NewDefaultSftpClientFactory(),
+		ftpFactory:   NewDefaultFtpClientFactory(),
+		localFactory: NewDefaultLocalClientFactory(),
+		s3Factory:    NewDefaultS3ClientFactory(),
+	}
+}
+
+// Create returns a new [Client] for the [Protocol] of the options, and accepts a list of [SftpClientOption].
+// For example:
+//
+//	client, err := transfer.NewDefaultClientFactory().Create(
+//		transfer.WithProtocol(transfer.FtpesProtocol),
+//		transfer.WithHost("ftp.example.com"),
+//		transfer.WithUser("user"),
+//		transfer.WithPassword("secret"),
+//	)
+func (f *DefaultClientFactory) Create(options ...SftpClientOption) (Client, error) {
+	appliedOpts := DefaultSftpClientOptions()
+	for _, applyOpt := range options {
+		applyOpt(&appliedOpts)
+	}
+
+	switch {
+	case appliedOpts.Protocol.IsFtp():
+		return f.ftpFactory.Create(options...)
+	case appliedOpts.Protocol == LocalProtocol:
+		return f.localFactory.Create(options...)
+	case appliedOpts.Protocol == S3Protocol:
+		return f.s3Factory.Create(options...)
+	default:
+		return f.sftpFactory.Create(options...)
+	}
+}
+
+// DefaultSftpClientFactory is the default [SftpClientFactory] implementation.
+type DefaultSftpClientFactory struct{}
+
+// NewDefaultSftpClientFactory returns a [DefaultSftpClientFactory], implementing [SftpClientFactory].
+func NewDefaultSftpClientFactory() SftpClientFactory {
+	return &DefaultSftpClientFactory{}
+}
+
+// Create returns a new SFTP [Client], and accepts a list of [SftpClientOption].
+// For example:
+//
+//	client, err := transfer.NewDefaultSftpClientFactory().Create(
+//		transfer.WithHost("sftp.example.com"),
+//		transfer.WithUser("user"),
+//		transfer.WithPassword("secret"),
+//	)
+func (f *DefaultSftpClientFactory) Create(options ...SftpClientOption) (Client, error) {
+	appliedOpts := DefaultSftpClientOptions()
+	for _, applyOpt := range options {
+		applyOpt(&appliedOpts)
+	}
+
+	if appliedOpts.Host == "" {
+		return nil, fmt.Errorf("missing sftp host")
+	}
+
+	auths, agentCloser, err := NewAuthMethods(appliedOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	// the agent connection, used by the handshake, is closed with the client
+	closeAgent := func() {
+		if agentCloser != nil {
+			agentCloser.Close()
+		}
+	}
+
+	hostKeyCallback := appliedOpts.HostKeyCallback
+	if hostKeyCallback == nil {
+		hostKeyCallback, err = NewHostKeyCallback(appliedOpts.HostKeyMode, appliedOpts.KnownHostsFile, appliedOpts.Fingerprints)
+		if err != nil {
+			closeAgent()
+
+			return nil, err
+		}
+	}
+
+	sshConfig := &ssh.ClientConfig{
+		User:            appliedOpts.User,
+		Auth:            auths,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         appliedOpts.Timeout,
+	}
+
+	addr := net.JoinHostPort(appliedOpts.Host, strconv.Itoa(appliedOpts.Port))
+
+	conn, err := ssh.Dial("tcp", addr, sshConfig)
+	if err != nil {
+		closeAgent()
+
+		if sshAuthFailed(err) {
+			err = &AuthError{User: appliedOpts.User, Err: err}
+		}
+
+		return nil, fmt.Errorf("unable to connect to [%s]: %w", addr, err)
+	}
+
+	sftpOptions := []sftp.ClientOption{
+		sftp.MaxPacket(appliedOpts.ChunkSize),
+		sftp.MaxConcurrentRequestsPerFile(appliedOpts.ConcurrentRequests),
+		sftp.UseConcurrentReads(appliedOpts.ConcurrentRequests > 1),
+		sftp.UseConcurrentWrites(appliedOpts.ConcurrentRequests > 1),
+	}
+
+	sc, err := sftp.NewClient(conn, sftpOptions...)
+	if err != nil {
+		conn.Close()
+		closeAgent()
+
+		return nil, fmt.Errorf("unable to start sftp subsystem on [%s]: %w", addr, err)
+	}
+
+	client := NewSftpClient(sc, conn, appliedOpts.TransferOptions...)
+	client.sftpOptions = sftpOptions
+	client.timeout = appliedOpts.Timeout
+	client.agent = agentCloser
+
+	return client, nil
+}
+
+// DefaultFtpClientFactory is the FTP and FTPS [SftpClientFactory] implementation.
+type DefaultFtpClientFactory struct{}
+
+// NewDefaultFtpClientFactory returns a [DefaultFtpClientFactory], implementing [SftpClientFactory].
+func NewDefaultFtpClientFactory() SftpClientFactory {
+	return &DefaultFtpClientFactory{}
+}
+
+// Create returns a new FTP [Client], and accepts a list of [SftpClientOption].
+// The [Protocol] selects plain FTP, FTP over implicit TLS (ftps) or FTP over explicit TLS (ftpes).
+// For example:
+//
+//	client, err := transfer.NewDefaultFtpClientFactory().Create(
+//		transfer.WithProtocol(transfer.FtpsProtocol),
+//		transfer.WithHost("ftp.example.com"),
+//		transfer.WithUser("user"),
+//		transfer.WithPassword("secret"),
+//		transfer.WithTLSClientCertificate("client.crt", "client.key"),
+//	)
+func (f *DefaultFtpClientFactory) Create(options ...SftpClientOption) (Client, error) {
+	appliedOpts := DefaultFtpClientOptions()
+	for _, applyOpt := range options {
+		applyOpt(&appliedOpts)
+	}
+
+	if appliedOpts.Host == "" {
+		return nil, fmt.Errorf("missing ftp host")
+	}
+
+	if !appliedOpts.Protocol.IsFtp() {
+		return nil, fmt.Errorf("unsupported ftp protocol %s", appliedOpts.Protocol)
+	}
+
+	if appliedOpts.Port == 0 {
+		appliedOpts.Port = appliedOpts.Protocol.DefaultPort()
+	}
+
+	conn, err := dialFtp(appliedOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	return newFtpClient(conn, appliedOpts.TransferOptions...), nil
+}
+
+// DefaultLocalClientFactory is the local filesystem [SftpClientFactory] implementation.
+type DefaultLocalClientFactory struct{}
+
+// NewDefaultLocalClientFactory returns a [DefaultLocalClientFactory], implementing [SftpClientFactory].
+func NewDefaultLocalClientFactory() SftpClientFactory {
+	return &DefaultLocalClientFactory{}
+}
+
+// Create returns a new local [Client] for the root directory of the options, and accepts a list of [SftpClientOption].
+// For example:
+//
+//	client, err := transfer.NewDefaultLocalClientFactory().Create(
+//		transfer.WithRoot("/mnt/partners"),
+//	)
+func (f *DefaultLocalClientFactory) Create(options ...SftpClientOption) (Client, error) {
+	appliedOpts := DefaultLocalClientOptions()
+	for _, applyOpt := range options {
+		applyOpt(&appliedOpts)
+	}
+
+	if appliedOpts.Root == "" {
+		return nil, fmt.Errorf("missing local root directory")
+	}
+
+	info, err := os.Stat(appliedOpts.Root)
+	if err != nil {
+		return nil, fmt.Errorf("unable to access local root directory [%s]: %w", appliedOpts.Root, err)
+	}
+
+	if !info.IsDir() {
+		return nil, fmt.Errorf("local root [%s] is not a directory", appliedOpts.Root)
+	}
+
+	return NewLocalClient(appliedOpts.Root, appliedOpts.TransferOptions...), nil
+}
+
+// DefaultS3ClientFactory is the S3 compatible object storage [SftpClientFactory] implementation.
+type DefaultS3ClientFactory struct{}
+
+// NewDefaultS3ClientFactory returns a [DefaultS3ClientFactory], implementing [SftpClientFactory].
+func NewDefaultS3ClientFactory() SftpClientFactory {
+	return &DefaultS3ClientFactory{}
+}
+
+// Create returns a new S3 [Client], and accepts a list of [SftpClientOption].
+// The user and password are the access key id and secret access key, read from the AWS and MinIO environment
+// variables, or from the instance IAM role if not provided.
+// For example:
+//
+//	client, err := transfer.NewDefaultS3ClientFactory().Create(
+//		transfer.WithHost("minio.example.com"),
+//		transfer.WithPort(9000),
+//		transfer.WithBucket("transfers"),
+//		transfer.WithS3PathStyle(true),
+//		transfer.WithUser("access-key"),
+//		transfer.WithPassword("secret-key"),
+//	)
+func (f *DefaultS3ClientFactory) Create(options ...SftpClientOption) (Client, error) {
+	appliedOpts := DefaultS3ClientOptions()
+	for _, applyOpt := range options {
+		applyOpt(&appliedOpts)
+	}
+
+	if appliedOpts.Host == "" {
+		return nil, fmt.Errorf("missing s3 host")
+	}
+
+	if appliedOpts.Bucket == "" {
+		return nil, fmt.Errorf("missing s3 bucket")
+	}
+
+	addr := appliedOpts.Host
+	if appliedOpts.Port > 0 {
+		addr = net.JoinHostPort(appliedOpts.Host, strconv.Itoa(appliedOpts.Port))
+	}
+
+	transport, err := minio.DefaultTransport(appliedOpts.S3Secure)
+	if err != nil {
+		return nil, err
+	}
+
+	transport.ResponseHeaderTimeout = appliedOpts.Timeout
+
+	if appliedOpts.S3Secure {
+		if transport.TLSClientConfig, err = NewTLSConfig(appliedOpts); err != nil {
+			return nil, err
+		}
+	}
+
+	creds := credentials.NewStaticV4(appliedOpts.User, appliedOpts.Password, "")
+	if appliedOpts.User == "" {
+		creds = credentials.NewChainCredentials([]credentials.Provider{
+			&credentials.EnvAWS{},
+			&credentials.EnvMinio{},
+			&credentials.IAM{Client: &http.Client{Transport: http.DefaultTransport}},
+		})
+	}
+
+	bucketLookup := minio.BucketLookupAuto
+	if appliedOpts.S3PathStyle {
+		bucketLookup = minio.BucketLookupPath
+	}
+
+	mc, err := minio.New(addr, &minio.Options{
+		Creds:        creds,
+		Secure:       appliedOpts.S3Secure,
+		Region:       appliedOpts.Region,
+		BucketLookup: bucketLookup,
+		Transport:    transport,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to [%s]: %w", addr, err)
+	}
+
+	client := NewS3Client(mc, appliedOpts.Bucket, appliedOpts.Root, appliedOpts.TransferOptions...)
+	client.transport = transport
+	client.timeout = appliedOpts.Timeout
+
+	ctx, cancel := context.WithTimeout(context.Background(), appliedOpts.Timeout)
+	defer cancel()
+
+	if err = client.checkBucket(ctx); err != nil {
+		client.Close()
+
+		if IsAuthError(err) {
+			return nil, fmt.Errorf("unable to connect to [%s]: %w", addr, &AuthError{User: appliedOpts.User, Err: err})
+		}
+
+		return nil, fmt.Errorf("unable to connect to [%s]: %w", addr, err)
+	}
+
+	return client, nil
+}
@@ -0,0 +1,44 @@
+package transfer
+
+import (
+	"io/fs"
+	"time"
+)
+
+// fileInfo is the [fs.FileInfo] of a file listed by a server, like an FTP server or an S3 bucket.
+type fileInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+// Name returns the base name of the file.
+func (i *fileInfo) Name() string {
+	return i.name
+}
+
+// Size returns the length in bytes of the file.
+func (i *fileInfo) Size() int64 {
+	return i.size
+}
+
+// Mode returns the file mode bits.
+func (i *fileInfo) Mode() fs.FileMode {
+	return i.mode
+}
+
+// ModTime returns the modification time of the file.
+func (i *fileInfo) ModTime() time.Time {
+	return i.modTime
+}
+
+// IsDir returns true if the file is a directory.
+func (i *fileInfo) IsDir() bool {
+	return i.mode.IsDir()
+}
+
+// Sys returns nil, listings having no underlying data source.
+func (i *fileInfo) Sys() any {
+	return nil
+}
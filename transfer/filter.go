@@ -0,0 +1,188 @@
+package transfer
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"time"
+)
+
+// FilterOptions are options for the [Filter].
+type FilterOptions struct {
+	Include      []string
+	Exclude      []string
+	IncludeRegex []string
+	ExcludeRegex []string
+	MinAge       time.Duration
+	MinSize      int64
+	MaxSize      int64
+}
+
+// FilterOption are functional options for the [Filter].
+type FilterOption func(o *FilterOptions)
+
+// WithInclude is used to select files with names matching any of the glob patterns, as supported by [path.Match] (e.g. *.csv).
+func WithInclude(patterns ...string) FilterOption {
+	return func(o *FilterOptions) {
+		o.Include = append(o.Include, patterns...)
+	}
+}
+
+// WithExclude is used to ignore files with names matching any of the glob patterns.
+func WithExclude(patterns ...string) FilterOption {
+	return func(o *FilterOptions) {
+		o.Exclude = append(o.Exclude, patterns...)
+	}
+}
+
+// WithIncludeRegex is used to select files with names matching any of the regular expressions.
+func WithIncludeRegex(expressions ...string) FilterOption {
+	return func(o *FilterOptions) {
+		o.IncludeRegex = append(o.IncludeRegex, expressions...)
+	}
+}
+
+// WithExcludeRegex is used to ignore files with names matching any of the regular expressions.
+func WithExcludeRegex(expressions ...string) FilterOption {
+	return func(o *FilterOptions) {
+		o.ExcludeRegex = append(o.ExcludeRegex, expressions...)
+	}
+}
+
+// WithMinAge is used to ignore files modified more recently than a duration.
+func WithMinAge(d time.Duration) FilterOption {
+	return func(o *FilterOptions) {
+		o.MinAge = d
+	}
+}
+
+// WithSizeBounds is used to ignore files smaller than min or bigger than max bytes, 0 meaning unbounded.
+func WithSizeBounds(min int64, max int64) FilterOption {
+	return func(o *FilterOptions) {
+		o.MinSize = min
+		o.MaxSize = max
+	}
+}
+
+// Filter selects files to transfer by name, age and size.
+// A file is selected if its name matches any include glob pattern or regular expression (or if there are none),
+// no exclude one, and if its age and size are within bounds.
+type Filter struct {
+	options      FilterOptions
+	includeRegex []*regexp.Regexp
+	excludeRegex []*regexp.Regexp
+}
+
+// NewFilter returns a new [Filter] for a list of [FilterOption], and validates its patterns.
+func NewFilter(options ...FilterOption) (*Filter, error) {
+	appliedOpts := FilterOptions{}
+	for _, opt := range options {
+		opt(&appliedOpts)
+	}
+
+	for _, pattern := range append(append([]string{}, appliedOpts.Include...), appliedOpts.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid filter pattern %s: %w", pattern, err)
+		}
+	}
+
+	includeRegex, err := compileAll(appliedOpts.IncludeRegex)
+	if err != nil {
+		return nil, err
+	}
+
+	excludeRegex, err := compileAll(appliedOpts.ExcludeRegex)
+	if err != nil {
+		return nil, err
+	}
+
+	if appliedOpts.MaxSize > 0 && appliedOpts.MinSize > appliedOpts.MaxSize {
+		return nil, fmt.Errorf("invalid filter size bounds: min %d is bigger than max %d", appliedOpts.MinSize, appliedOpts.MaxSize)
+	}
+
+	return &Filter{
+		options:      appliedOpts,
+		includeRegex: includeRegex,
+		excludeRegex: excludeRegex,
+	}, nil
+}
+
+// Match returns true if a file is selected by the [Filter].
+func (f *Filter) Match(info fs.FileInfo) bool {
+	if !f.MatchName(info) {
+		return false
+	}
+
+	if f.options.MinAge > 0 && time.Since(info.ModTime()) < f.options.MinAge {
+		return false
+	}
+
+	if info.Size() < f.options.MinSize {
+		return false
+	}
+
+	return f.options.MaxSize <= 0 || info.Size() <= f.options.MaxSize
+}
+
+// MatchName returns true if a file is selected by the [Filter] name patterns, whatever its age and size.
+func (f *Filter) MatchName(info fs.FileInfo) bool {
+	if info.IsDir() {
+		return false
+	}
+
+	name := info.Name()
+
+	hasIncludes := len(f.options.Include) > 0 || len(f.includeRegex) > 0
+	if hasIncludes && !matchAny(f.options.Include, name) && !matchAnyRegex(f.includeRegex, name) {
+		return false
+	}
+
+	return !matchAny(f.options.Exclude, name) && !matchAnyRegex(f.excludeRegex, name)
+}
+
+// Apply returns the entries selected by the [Filter].
+func (f *Filter) Apply(entries []WalkEntry) []WalkEntry {
+	var selected []WalkEntry
+	for _, entry := range entries {
+		if f.Match(entry.Info) {
+			selected = append(selected, entry)
+		}
+	}
+
+	return selected
+}
+
+func compileAll(expressions []string) ([]*regexp.Regexp, error) {
+	var compiled []*regexp.Regexp
+	for _, expression := range expressions {
+		re, err := regexp.Compile(expression)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter regular expression %s: %w", expression, err)
+		}
+
+		compiled = append(compiled, re)
+	}
+
+	return compiled, nil
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchAnyRegex(expressions []*regexp.Regexp, name string) bool {
+	for _, re := range expressions {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+
+	return false
+}
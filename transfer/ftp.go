@@ -0,0 +1,411 @@
+package transfer
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+var _ Client = (*FtpClient)(nil)
+
+// FtpClient is the FTP and FTPS [Client] implementation.
+// Its control connection runs one command at a time: it must not be used concurrently.
+type FtpClient struct {
+	conn            *ftpConn
+	transferOptions []TransferOption
+}
+
+func newFtpClient(conn *ftpConn, transferOptions ...TransferOption) *FtpClient {
+	return &FtpClient{
+		conn:            conn,
+		transferOptions: transferOptions,
+	}
+}
+
+// Features returns the extensions advertised by the FTP server, with their parameters.
+func (c *FtpClient) Features() map[string]string {
+	return c.conn.features
+}
+
+// KeepAlive sends a NOOP command over the control connection, and waits for its reply.
+func (c *FtpClient) KeepAlive() error {
+	if _, _, err := c.conn.cmd(200, "NOOP"); err != nil {
+		return fmt.Errorf("keepalive failure on [%s]: %w", c.conn.conn.RemoteAddr(), err)
+	}
+
+	return nil
+}
+
+// List returns the files (directories excluded) of a remote directory.
+func (c *FtpClient) List(ctx context.Context, dir string) ([]fs.FileInfo, error) {
+	entries, err := c.readDir(ctx, dir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list remote dir [%s]: %w", dir, err)
+	}
+
+	var files []fs.FileInfo
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			files = append(files, entry)
+		}
+	}
+
+	return files, nil
+}
+
+// readDir returns the entries of a remote directory, listed with MLSD if supported, or LIST otherwise.
+func (c *FtpClient) readDir(ctx context.Context, dir string) ([]fs.FileInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	mlsd := c.conn.hasFeature("MLST")
+
+	command := "LIST %s"
+	if mlsd {
+		command = "MLSD %s"
+	}
+
+	data, err := c.conn.transfer(ctx, 0, command, dir)
+	if err != nil {
+		// servers reply 450 or 550 to the listing of a missing directory
+		var ftpErr *FtpError
+		if errors.As(err, &ftpErr) && (ftpErr.Code == 450 || ftpErr.Code == 550) {
+			return nil, fmt.Errorf("%w: %w", fs.ErrNotExist, err)
+		}
+
+		return nil, err
+	}
+	defer data.Close()
+
+	now := time.Now()
+
+	var entries []fs.FileInfo
+	scanner := bufio.NewScanner(NewContextReader(ctx, data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+
+		var entry *fileInfo
+		if mlsd {
+			entry, err = parseMlsdLine(line)
+		} else {
+			entry, err = parseListLine(line, now)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if entry != nil {
+			entries = append(entries, entry)
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if err = data.Close(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// Walk returns the files (directories excluded) of a remote directory tree, with their paths relative to the directory.
+func (c *FtpClient) Walk(ctx context.Context, dir string) ([]WalkEntry, error) {
+	root := path.Clean(dir)
+
+	var entries []WalkEntry
+
+	var walk func(dir string) error
+	walk = func(dir string) error {
+		infos, err := c.readDir(ctx, dir)
+		if err != nil {
+			return fmt.Errorf("unable to walk remote dir [%s]: %w", dir, err)
+		}
+
+		for _, info := range infos {
+			p := path.Join(dir, info.Name())
+
+			if info.IsDir() {
+				if err = walk(p); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			entries = append(entries, WalkEntry{
+				Path: relativePath(root, p),
+				Info: info,
+			})
+		}
+
+		return nil
+	}
+
+	if err := walk(root); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// Upload copies a local file to a remote path, creating the missing remote directories, and returns a [TransferResult].
+// It supports the same options as [SftpClient.Upload], partial remote files being resumed with APPE.
+func (c *FtpClient) Upload(ctx context.Context, localPath string, remotePath string, options ...TransferOption) (*TransferResult, error) {
+	return streamUpload(ctx, c, localPath, remotePath, c.applyTransferOptions(options...))
+}
+
+// Download copies a remote file to a local path, creating the missing local directories, and returns a [TransferResult].
+// It supports the same options as [SftpClient.Download], partial local files being resumed with REST.
+func (c *FtpClient) Download(ctx context.Context, remotePath string, localPath string, options ...TransferOption) (*TransferResult, error) {
+	return streamDownload(ctx, c, remotePath, localPath, c.applyTransferOptions(options...))
+}
+
+// Move renames a remote file, the destination folder must exist.
+func (c *FtpClient) Move(ctx context.Context, sourcePath string, destinationPath string) error {
+	if _, err := c.Stat(ctx, sourcePath); err != nil {
+		return err
+	}
+
+	if _, err := c.Stat(ctx, path.Dir(destinationPath)); err != nil {
+		return err
+	}
+
+	if err := c.rename(sourcePath, destinationPath); err != nil {
+		return fmt.Errorf("unable to move remote file from [%s] to [%s]: %w", sourcePath, destinationPath, err)
+	}
+
+	return nil
+}
+
+func (c *FtpClient) rename(sourcePath string, destinationPath string) error {
+	if _, _, err := c.conn.cmd(350, "RNFR %s", sourcePath); err != nil {
+		return err
+	}
+
+	_, _, err := c.conn.cmd(250, "RNTO %s", destinationPath)
+
+	return err
+}
+
+// Remove removes a remote file or empty directory.
+func (c *FtpClient) Remove(ctx context.Context, path string) error {
+	info, err := c.Stat(ctx, path)
+	if err != nil {
+		return err
+	}
+
+	command := "DELE %s"
+	if info.IsDir() {
+		command = "RMD %s"
+	}
+
+	if _, _, err = c.conn.cmd(250, command, path); err != nil {
+		return fmt.Errorf("unable to remove remote path [%s]: %w", path, err)
+	}
+
+	return nil
+}
+
+// Stat returns the [fs.FileInfo] of a remote path, with MLST if supported, or by listing its parent directory otherwise.
+func (c *FtpClient) Stat(ctx context.Context, p string) (fs.FileInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	info, err := c.stat(ctx, p)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("remote path [%s] does not exist: %w", p, err)
+		}
+
+		return nil, fmt.Errorf("unable to stat remote path [%s]: %w", p, err)
+	}
+
+	return info, nil
+}
+
+func (c *FtpClient) stat(ctx context.Context, p string) (fs.FileInfo, error) {
+	clean := path.Clean(p)
+
+	if c.conn.hasFeature("MLST") {
+		_, message, err := c.conn.cmd(250, "MLST %s", clean)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, line := range strings.Split(message, "\n") {
+			if !strings.HasPrefix(line, " ") {
+				continue
+			}
+
+			info, err := parseMlsdLine(strings.TrimPrefix(line, " "))
+			if err != nil {
+				return nil, err
+			}
+
+			if info == nil {
+				// the current or parent directory
+				info = &fileInfo{mode: fs.ModeDir | 0o755}
+			}
+
+			info.name = path.Base(clean)
+
+			return info, nil
+		}
+
+		return nil, fmt.Errorf("invalid mlst reply [%s]", message)
+	}
+
+	if clean == "." || clean == "/" {
+		if _, _, err := c.conn.cmd(257, "PWD"); err != nil {
+			return nil, err
+		}
+
+		return &fileInfo{name: clean, mode: fs.ModeDir | 0o755}, nil
+	}
+
+	entries, err := c.readDir(ctx, path.Dir(clean))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if entry.Name() == path.Base(clean) {
+			return entry, nil
+		}
+	}
+
+	return nil, fs.ErrNotExist
+}
+
+// Mkdir creates a remote directory, and all its missing parents.
+func (c *FtpClient) Mkdir(ctx context.Context, p string) error {
+	info, err := c.Stat(ctx, p)
+	if err == nil {
+		if !info.IsDir() {
+			return fmt.Errorf("unable to create remote directory [%s]: %w", p, fs.ErrExist)
+		}
+
+		return nil
+	}
+
+	if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	if parent := path.Dir(path.Clean(p)); parent != "." && parent != "/" {
+		if err = c.Mkdir(ctx, parent); err != nil {
+			return err
+		}
+	}
+
+	if _, _, err = c.conn.cmd(257, "MKD %s", p); err != nil {
+		return fmt.Errorf("unable to create remote directory [%s]: %w", p, err)
+	}
+
+	return nil
+}
+
+// Open opens a remote file for reading.
+func (c *FtpClient) Open(ctx context.Context, path string) (io.ReadCloser, error) {
+	f, err := c.read(ctx, path, 0)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open remote file [%s]: %w", path, err)
+	}
+
+	return f, nil
+}
+
+// Chtimes changes the modification time of a remote file, with the MFMT extension.
+// It returns an [errors.ErrUnsupported] error if the server does not support it.
+func (c *FtpClient) Chtimes(ctx context.Context, path string, modTime time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if !c.conn.hasFeature("MFMT") {
+		return fmt.Errorf("unable to change remote file [%s] times: %w", path, errors.ErrUnsupported)
+	}
+
+	if _, _, err := c.conn.cmd(213, "MFMT %s %s", modTime.UTC().Format("20060102150405"), path); err != nil {
+		return fmt.Errorf("unable to change remote file [%s] times: %w", path, err)
+	}
+
+	return nil
+}
+
+func (c *FtpClient) chtimesSupported() bool {
+	return c.conn.hasFeature("MFMT")
+}
+
+// Close quits the FTP session, and closes its control connection.
+func (c *FtpClient) Close() error {
+	return c.conn.close()
+}
+
+func (c *FtpClient) read(ctx context.Context, path string, offset int64) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return c.conn.transfer(ctx, offset, "RETR %s", path)
+}
+
+func (c *FtpClient) write(ctx context.Context, path string, r io.Reader, _ int64, append bool) (int64, error) {
+	command := "STOR %s"
+	if append {
+		command = "APPE %s"
+	}
+
+	data, err := c.conn.transfer(ctx, 0, command, path)
+	if err != nil {
+		return 0, fmt.Errorf("unable to open remote file: %w", err)
+	}
+
+	data.upload = true
+
+	n, err := io.Copy(data, r)
+	if closeErr := data.Close(); err == nil {
+		err = closeErr
+	}
+
+	return n, err
+}
+
+// replace renames a remote file, removing the existing destination first, since servers may refuse to overwrite it.
+func (c *FtpClient) replace(ctx context.Context, sourcePath string, destinationPath string) error {
+	if _, err := c.Stat(ctx, destinationPath); err == nil {
+		if _, _, err = c.conn.cmd(250, "DELE %s", destinationPath); err != nil {
+			return fmt.Errorf("unable to replace remote file [%s]: %w", destinationPath, err)
+		}
+	}
+
+	if err := c.rename(sourcePath, destinationPath); err != nil {
+		return fmt.Errorf("unable to rename remote file [%s] to [%s]: %w", sourcePath, destinationPath, err)
+	}
+
+	return nil
+}
+
+func (c *FtpClient) applyTransferOptions(options ...TransferOption) TransferOptions {
+	appliedOpts := DefaultTransferOptions()
+	for _, applyOpt := range slices.Concat(c.transferOptions, options) {
+		applyOpt(&appliedOpts)
+	}
+
+	return appliedOpts
+}
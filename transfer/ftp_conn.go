@@ -0,0 +1,511 @@
+package transfer
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/textproto"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var pasvRegexp = regexp.MustCompile(`(\d+),(\d+),(\d+),(\d+),(\d+),(\d+)`)
+
+// FtpError is a negative reply of an FTP server.
+type FtpError struct {
+	Code    int
+	Message string
+}
+
+// Error returns the error message.
+func (e *FtpError) Error() string {
+	return fmt.Sprintf("ftp reply %d: %s", e.Code, e.Message)
+}
+
+// Temporary returns true for the transient negative replies (4xx), which may succeed when retried.
+func (e *FtpError) Temporary() bool {
+	return e.Code >= 400 && e.Code < 500
+}
+
+// Is reports the file unavailable reply (550) as [fs.ErrNotExist].
+func (e *FtpError) Is(target error) bool {
+	return target == fs.ErrNotExist && e.Code == 550
+}
+
+func isFtpError(err error) bool {
+	var ftpErr *FtpError
+
+	return errors.As(err, &ftpErr)
+}
+
+// ftpConn is an FTP control connection, running one command at a time.
+type ftpConn struct {
+	conn      net.Conn
+	text      *textproto.Conn
+	options   Options
+	tlsConfig *tls.Config
+	features  map[string]string
+	noEpsv    bool
+}
+
+// dialFtp opens an FTP control connection, negotiates TLS if required by the [Protocol], and logs in.
+func dialFtp(o Options) (*ftpConn, error) {
+	addr := net.JoinHostPort(o.Host, strconv.Itoa(o.Port))
+
+	c := &ftpConn{
+		options:  o,
+		features: make(map[string]string),
+	}
+
+	if o.Protocol == FtpsProtocol || o.Protocol == FtpesProtocol {
+		tlsConfig, err := NewTLSConfig(o)
+		if err != nil {
+			return nil, err
+		}
+
+		c.tlsConfig = tlsConfig
+	}
+
+	dialer := &net.Dialer{Timeout: o.Timeout}
+
+	var conn net.Conn
+	var err error
+	if o.Protocol == FtpsProtocol {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, c.tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to [%s]: %w", addr, err)
+	}
+
+	c.setConn(conn)
+
+	if err = c.handshake(); err != nil {
+		c.conn.Close()
+
+		return nil, fmt.Errorf("unable to connect to [%s]: %w", addr, err)
+	}
+
+	return c, nil
+}
+
+func (c *ftpConn) setConn(conn net.Conn) {
+	c.conn = conn
+	c.text = textproto.NewConn(conn)
+}
+
+func (c *ftpConn) handshake() error {
+	if _, _, err := c.response(220); err != nil {
+		return err
+	}
+
+	if c.options.Protocol == FtpesProtocol {
+		if _, _, err := c.cmd(234, "AUTH TLS"); err != nil {
+			return fmt.Errorf("unable to negotiate explicit tls: %w", err)
+		}
+
+		tlsConn := tls.Client(c.conn, c.tlsConfig)
+		c.deadline()
+
+		if err := tlsConn.Handshake(); err != nil {
+			return fmt.Errorf("unable to negotiate explicit tls: %w", err)
+		}
+
+		c.setConn(tlsConn)
+	}
+
+	if err := c.login(); err != nil {
+		return err
+	}
+
+	if c.tlsConfig != nil {
+		if _, _, err := c.cmd(200, "PBSZ 0"); err != nil {
+			return fmt.Errorf("unable to protect data connections: %w", err)
+		}
+
+		if _, _, err := c.cmd(200, "PROT P"); err != nil {
+			return fmt.Errorf("unable to protect data connections: %w", err)
+		}
+	}
+
+	if err := c.feat(); err != nil {
+		return err
+	}
+
+	if c.hasFeature("UTF8") {
+		// best effort, some servers enable UTF-8 by default and refuse the option
+		if _, _, err := c.cmd(200, "OPTS UTF8 ON"); err != nil && !isFtpError(err) {
+			return err
+		}
+	}
+
+	if _, _, err := c.cmd(200, "TYPE I"); err != nil {
+		return fmt.Errorf("unable to switch to binary mode: %w", err)
+	}
+
+	return nil
+}
+
+func (c *ftpConn) login() error {
+	user, password := c.options.User, c.options.Password
+	if user == "" {
+		user, password = "anonymous", "anonymous@"
+	}
+
+	code, _, err := c.cmd(0, "USER %s", user)
+	if err == nil && code == 331 {
+		code, _, err = c.cmd(0, "PASS %s", password)
+	}
+
+	if err == nil && code != 230 && code != 202 {
+		err = &FtpError{Code: code, Message: "unexpected login reply"}
+	}
+
+	// only the not logged in reply rejects the credentials, the other failures can be transient
+	var ftpErr *FtpError
+	if errors.As(err, &ftpErr) && ftpErr.Code == 530 {
+		return &AuthError{User: user, Err: err}
+	}
+
+	if err != nil {
+		return fmt.Errorf("unable to log in as [%s]: %w", user, err)
+	}
+
+	return nil
+}
+
+// feat reads the server extensions (RFC 2389), unsupported FEAT commands meaning no extension.
+func (c *ftpConn) feat() error {
+	_, message, err := c.cmd(211, "FEAT")
+	if err != nil {
+		if isFtpError(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	for _, line := range strings.Split(message, "\n") {
+		name, value, _ := strings.Cut(strings.TrimSpace(line), " ")
+		if name != "" {
+			c.features[strings.ToUpper(name)] = value
+		}
+	}
+
+	return nil
+}
+
+func (c *ftpConn) hasFeature(name string) bool {
+	_, ok := c.features[name]
+
+	return ok
+}
+
+// deadline bounds the next exchange on the control connection with the connection timeout.
+func (c *ftpConn) deadline() {
+	if c.options.Timeout > 0 {
+		c.conn.SetDeadline(time.Now().Add(c.options.Timeout))
+	}
+}
+
+// cmd sends a command, and reads its reply, expecting a code or, for a single digit, a class of codes.
+// A zero expectation accepts all the positive codes.
+func (c *ftpConn) cmd(expect int, format string, args ...any) (int, string, error) {
+	c.deadline()
+
+	if _, err := c.text.Cmd(format, args...); err != nil {
+		return 0, "", err
+	}
+
+	return c.response(expect)
+}
+
+func (c *ftpConn) response(expect int) (int, string, error) {
+	c.deadline()
+
+	code, message, err := c.text.ReadResponse(0)
+	if err != nil {
+		return code, message, err
+	}
+
+	switch {
+	case code >= 400,
+		expect >= 10 && code != expect,
+		expect > 0 && expect < 10 && code/100 != expect:
+		return code, message, &FtpError{Code: code, Message: message}
+	}
+
+	return code, message, nil
+}
+
+// resync realigns the control connection after an aborted transfer, skipping its pending replies until a NOOP one.
+func (c *ftpConn) resync() error {
+	c.deadline()
+
+	if _, err := c.text.Cmd("NOOP"); err != nil {
+		return err
+	}
+
+	for i := 0; i < 3; i++ {
+		code, _, err := c.text.ReadResponse(0)
+		if err != nil {
+			return err
+		}
+
+		if code == 200 {
+			return nil
+		}
+	}
+
+	return errors.New("unable to resync ftp control connection")
+}
+
+// transfer opens a data connection for a transfer command, from an offset if greater than zero.
+// The returned [ftpTransfer] must be closed to read the transfer completion reply.
+func (c *ftpConn) transfer(ctx context.Context, offset int64, format string, args ...any) (*ftpTransfer, error) {
+	if offset > 0 {
+		if _, _, err := c.cmd(350, "REST %d", offset); err != nil {
+			return nil, fmt.Errorf("unable to restart transfer at offset %d: %w", offset, err)
+		}
+	}
+
+	var conn net.Conn
+	var err error
+	if c.options.FtpMode == ActiveFtpMode {
+		conn, err = c.activeTransfer(format, args...)
+	} else {
+		conn, err = c.passiveTransfer(ctx, format, args...)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if c.tlsConfig != nil {
+		tlsConn := tls.Client(conn, c.tlsConfig)
+		if err = tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			c.resync()
+
+			return nil, fmt.Errorf("unable to negotiate data connection tls: %w", err)
+		}
+
+		conn = tlsConn
+	}
+
+	return &ftpTransfer{Conn: conn, c: c}, nil
+}
+
+func (c *ftpConn) passiveTransfer(ctx context.Context, format string, args ...any) (net.Conn, error) {
+	addr, err := c.passiveAddress()
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{Timeout: c.options.Timeout}
+
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open data connection to [%s]: %w", addr, err)
+	}
+
+	if _, _, err = c.cmd(1, format, args...); err != nil {
+		conn.Close()
+
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// passiveAddress requests a passive data connection address, with EPSV (RFC 2428) or PASV as fallback.
+// The control connection host is always used, since servers behind NAT often advertise private addresses.
+func (c *ftpConn) passiveAddress() (string, error) {
+	host, _, err := net.SplitHostPort(c.conn.RemoteAddr().String())
+	if err != nil {
+		return "", err
+	}
+
+	if !c.noEpsv {
+		_, message, err := c.cmd(229, "EPSV")
+		if err == nil {
+			start, end := strings.Index(message, "("), strings.LastIndex(message, ")")
+			if start >= 0 && end > start+1 {
+				parts := strings.Split(message[start+1:end], message[start+1:start+2])
+				if len(parts) == 5 {
+					return net.JoinHostPort(host, parts[3]), nil
+				}
+			}
+
+			return "", fmt.Errorf("invalid epsv reply [%s]", message)
+		}
+
+		if !isFtpError(err) {
+			return "", err
+		}
+
+		c.noEpsv = true
+	}
+
+	_, message, err := c.cmd(227, "PASV")
+	if err != nil {
+		return "", err
+	}
+
+	matches := pasvRegexp.FindStringSubmatch(message)
+	if matches == nil {
+		return "", fmt.Errorf("invalid pasv reply [%s]", message)
+	}
+
+	high, _ := strconv.Atoi(matches[5])
+	low, _ := strconv.Atoi(matches[6])
+
+	return net.JoinHostPort(host, strconv.Itoa(high<<8+low)), nil
+}
+
+// activeTransfer listens for the server data connection, advertised with EPRT (RFC 2428) or PORT as fallback.
+func (c *ftpConn) activeTransfer(format string, args ...any) (net.Conn, error) {
+	ip, _, err := net.SplitHostPort(c.conn.LocalAddr().String())
+	if err != nil {
+		return nil, err
+	}
+
+	listenAddr := net.JoinHostPort(ip, "0")
+	if c.options.ActiveAddress != "" {
+		// the advertised address may be a public one, translated to this host
+		ip, listenAddr = c.options.ActiveAddress, ":0"
+	}
+
+	listener, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		return nil, fmt.Errorf("unable to listen for active data connection: %w", err)
+	}
+	defer listener.Close()
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	advertised := net.ParseIP(ip)
+	if advertised == nil {
+		return nil, fmt.Errorf("invalid active address [%s]", ip)
+	}
+
+	family := 2
+	if advertised.To4() != nil {
+		family = 1
+	}
+
+	_, _, err = c.cmd(200, "EPRT |%d|%s|%d|", family, advertised.String(), port)
+	if err != nil && isFtpError(err) && family == 1 {
+		v4 := advertised.To4()
+		_, _, err = c.cmd(200, "PORT %d,%d,%d,%d,%d,%d", v4[0], v4[1], v4[2], v4[3], port>>8, port&0xff)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, _, err = c.cmd(1, format, args...); err != nil {
+		return nil, err
+	}
+
+	if c.options.Timeout > 0 {
+		listener.(*net.TCPListener).SetDeadline(time.Now().Add(c.options.Timeout))
+	}
+
+	conn, err := listener.Accept()
+	if err != nil {
+		c.resync()
+
+		return nil, fmt.Errorf("unable to accept active data connection: %w", err)
+	}
+
+	return conn, nil
+}
+
+// close quits the session, and closes the control connection.
+func (c *ftpConn) close() error {
+	c.cmd(0, "QUIT")
+
+	return c.text.Close()
+}
+
+// ftpTransfer is an FTP data connection.
+type ftpTransfer struct {
+	net.Conn
+	c        *ftpConn
+	upload   bool
+	complete bool
+	closed   bool
+}
+
+// Read reads from the data connection, recording its completion.
+func (t *ftpTransfer) Read(p []byte) (int, error) {
+	t.deadline()
+
+	n, err := t.Conn.Read(p)
+	if err == io.EOF {
+		t.complete = true
+	}
+
+	return n, err
+}
+
+// Write writes to the data connection.
+func (t *ftpTransfer) Write(p []byte) (int, error) {
+	t.deadline()
+
+	return t.Conn.Write(p)
+}
+
+// deadline bounds the next data exchange with the connection timeout, to detect stalled transfers.
+func (t *ftpTransfer) deadline() {
+	if t.c.options.Timeout > 0 {
+		t.Conn.SetDeadline(time.Now().Add(t.c.options.Timeout))
+	}
+}
+
+// drain half closes an upload data connection, and reads it until the server closes it: closing it with unread
+// data, like TLS 1.3 session tickets, would reset it and lose the end of the upload.
+func (t *ftpTransfer) drain() {
+	if closer, ok := t.Conn.(interface{ CloseWrite() error }); ok {
+		if err := closer.CloseWrite(); err != nil {
+			return
+		}
+	}
+
+	t.deadline()
+	io.Copy(io.Discard, t.Conn)
+}
+
+// Close closes the data connection, and reads the transfer completion reply.
+// Downloads closed before their end are aborted, and the control connection resynchronized.
+func (t *ftpTransfer) Close() error {
+	if t.closed {
+		return nil
+	}
+
+	t.closed = true
+
+	if t.upload {
+		t.drain()
+	}
+
+	err := t.Conn.Close()
+
+	if !t.upload && !t.complete {
+		return t.c.resync()
+	}
+
+	if _, _, replyErr := t.c.response(2); replyErr != nil {
+		return replyErr
+	}
+
+	return err
+}
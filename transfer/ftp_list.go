@@ -0,0 +1,187 @@
+package transfer
+
+import (
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	unixListRegexp = regexp.MustCompile(`^([\-dlbcps])([rwxsStT\-]{9})\S*\s+\d+\s+(?:.+?\s+)?(\d+)\s+([A-Za-z]{3})\s+(\d{1,2})\s+(\d{1,2}:\d{2}|\d{4})\s+(.+)$`)
+	dosListRegexp  = regexp.MustCompile(`^(\d{2}-\d{2}-\d{2,4})\s+(\d{1,2}:\d{2}\s*[AaPp][Mm])\s+(<DIR>|\d+)\s+(.+)$`)
+)
+
+// parseMlsdLine parses a machine readable MLSD or MLST entry (RFC 3659), in the "fact=value;...; name" form.
+// It returns nil for the listed directory and its parent entries.
+func parseMlsdLine(line string) (*fileInfo, error) {
+	facts, name, found := strings.Cut(line, " ")
+	if !found || name == "" {
+		return nil, fmt.Errorf("invalid mlsd entry [%s]", line)
+	}
+
+	info := &fileInfo{
+		name: name,
+	}
+
+	perm := fs.FileMode(0o644)
+	for _, fact := range strings.Split(facts, ";") {
+		key, value, _ := strings.Cut(fact, "=")
+
+		switch strings.ToLower(key) {
+		case "type":
+			switch value = strings.ToLower(value); {
+			case value == "cdir", value == "pdir":
+				return nil, nil
+			case value == "dir":
+				info.mode |= fs.ModeDir
+				perm = 0o755
+			case strings.HasSuffix(value, "=symlink"), strings.HasSuffix(value, "=slink"):
+				info.mode |= fs.ModeSymlink
+			}
+		case "size", "sizd":
+			size, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid mlsd entry size [%s]: %w", line, err)
+			}
+
+			info.size = size
+		case "modify":
+			modTime, err := parseMlsdTime(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid mlsd entry modification time [%s]: %w", line, err)
+			}
+
+			info.modTime = modTime
+		case "unix.mode":
+			if mode, err := strconv.ParseUint(value, 8, 32); err == nil {
+				perm = fs.FileMode(mode).Perm()
+			}
+		}
+	}
+
+	info.mode |= perm
+
+	return info, nil
+}
+
+// parseMlsdTime parses a YYYYMMDDHHMMSS[.sss] UTC time.
+func parseMlsdTime(value string) (time.Time, error) {
+	if len(value) < 14 {
+		return time.Time{}, fmt.Errorf("invalid time [%s]", value)
+	}
+
+	return time.ParseInLocation("20060102150405", value[:14], time.UTC)
+}
+
+// parseListLine parses a LIST entry, in the unix "ls -l" or in the DOS form.
+// It returns nil for the "total" line, and for the listed directory and its parent entries.
+// As LIST times are in the server time zone, they are considered UTC, and without year they are the most recent past one.
+func parseListLine(line string, now time.Time) (*fileInfo, error) {
+	if strings.HasPrefix(line, "total ") {
+		return nil, nil
+	}
+
+	var info *fileInfo
+	var err error
+	if matches := unixListRegexp.FindStringSubmatch(line); matches != nil {
+		info, err = parseUnixListMatches(matches, now)
+	} else if matches = dosListRegexp.FindStringSubmatch(line); matches != nil {
+		info, err = parseDosListMatches(matches)
+	} else {
+		return nil, fmt.Errorf("unsupported list entry [%s]", line)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid list entry [%s]: %w", line, err)
+	}
+
+	if info.name == "." || info.name == ".." {
+		return nil, nil
+	}
+
+	return info, nil
+}
+
+func parseUnixListMatches(matches []string, now time.Time) (*fileInfo, error) {
+	info := &fileInfo{
+		name: matches[7],
+		mode: parseUnixPerm(matches[2]),
+	}
+
+	switch matches[1] {
+	case "d":
+		info.mode |= fs.ModeDir
+	case "l":
+		info.mode |= fs.ModeSymlink
+		info.name, _, _ = strings.Cut(info.name, " -> ")
+	}
+
+	size, err := strconv.ParseInt(matches[3], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	info.size = size
+
+	month, day, yearOrTime := matches[4], matches[5], matches[6]
+	if strings.Contains(yearOrTime, ":") {
+		info.modTime, err = time.ParseInLocation("Jan 2 2006 15:04", fmt.Sprintf("%s %s %d %s", month, day, now.Year(), yearOrTime), time.UTC)
+		if err == nil && info.modTime.After(now.Add(24*time.Hour)) {
+			info.modTime = info.modTime.AddDate(-1, 0, 0)
+		}
+	} else {
+		info.modTime, err = time.ParseInLocation("Jan 2 2006", fmt.Sprintf("%s %s %s", month, day, yearOrTime), time.UTC)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+func parseUnixPerm(perm string) fs.FileMode {
+	var mode fs.FileMode
+	for i, c := range perm {
+		if c != '-' && c != 'S' && c != 'T' {
+			mode |= 1 << uint(8-i)
+		}
+	}
+
+	return mode
+}
+
+func parseDosListMatches(matches []string) (*fileInfo, error) {
+	info := &fileInfo{
+		name: matches[4],
+		mode: 0o644,
+	}
+
+	if matches[3] == "<DIR>" {
+		info.mode = fs.ModeDir | 0o755
+	} else {
+		size, err := strconv.ParseInt(matches[3], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		info.size = size
+	}
+
+	layout := "01-02-06 03:04PM"
+	if len(matches[1]) == 10 {
+		layout = "01-02-2006 03:04PM"
+	}
+
+	modTime, err := time.ParseInLocation(layout, matches[1]+" "+strings.ToUpper(strings.ReplaceAll(matches[2], " ", "")), time.UTC)
+	if err != nil {
+		return nil, err
+	}
+
+	info.modTime = modTime
+
+	return info, nil
+}
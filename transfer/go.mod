@@ -0,0 +1,30 @@
+module github.com/templatedop/ftptemplate/transfer
+
+go 1.22.1
+
+require (
+	github.com/ProtonMail/go-crypto v1.1.3
+	github.com/klauspost/compress v1.17.9
+	github.com/minio/minio-go/v7 v7.0.77
+	github.com/pkg/sftp v1.13.6
+	github.com/templatedop/ftptemplate/log v0.0.1
+	golang.org/x/crypto v0.26.0
+)
+
+require (
+	github.com/cloudflare/circl v1.3.7 // indirect
+	github.com/dustin/go-humanize v1.0.1 // indirect
+	github.com/go-ini/ini v1.67.0 // indirect
+	github.com/goccy/go-json v0.10.3 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/klauspost/cpuid/v2 v2.2.8 // indirect
+	github.com/kr/fs v0.1.0 // indirect
+	github.com/mattn/go-colorable v0.1.13 // indirect
+	github.com/mattn/go-isatty v0.0.19 // indirect
+	github.com/minio/md5-simd v1.1.2 // indirect
+	github.com/rs/xid v1.6.0 // indirect
+	github.com/rs/zerolog v1.33.0 // indirect
+	golang.org/x/net v0.28.0 // indirect
+	golang.org/x/sys v0.24.0 // indirect
+	golang.org/x/text v0.17.0 // indirect
+)
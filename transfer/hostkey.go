@@ -0,0 +1,180 @@
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// HostKeyError is returned when a server host key cannot be verified.
+type HostKeyError struct {
+	Host        string
+	Mode        HostKeyMode
+	Fingerprint string
+	Expected    []string
+	Err         error
+}
+
+// Error returns the error message.
+func (e *HostKeyError) Error() string {
+	if len(e.Expected) == 0 {
+		return fmt.Sprintf("host key verification failed for %s (%s): unknown key %s", e.Host, e.Mode, e.Fingerprint)
+	}
+
+	return fmt.Sprintf(
+		"host key verification failed for %s (%s): key %s does not match expected %s",
+		e.Host,
+		e.Mode,
+		e.Fingerprint,
+		strings.Join(e.Expected, ", "),
+	)
+}
+
+// Unwrap returns the underlying error.
+func (e *HostKeyError) Unwrap() error {
+	return e.Err
+}
+
+// Mismatch returns true if the host was known with a different key, which can signify a MITM attack.
+func (e *HostKeyError) Mismatch() bool {
+	return len(e.Expected) > 0
+}
+
+// DefaultKnownHostsFile returns the current user known_hosts file path.
+func DefaultKnownHostsFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(home, ".ssh", "known_hosts")
+}
+
+// NewHostKeyCallback returns an [ssh.HostKeyCallback] for a [HostKeyMode].
+// The knownHostsFile is used by the known_hosts and tofu modes, and the fingerprints by the fingerprint mode.
+func NewHostKeyCallback(mode HostKeyMode, knownHostsFile string, fingerprints []string) (ssh.HostKeyCallback, error) {
+	if knownHostsFile == "" {
+		knownHostsFile = DefaultKnownHostsFile()
+	}
+
+	switch mode {
+	case InsecureHostKeyMode:
+		return ssh.InsecureIgnoreHostKey(), nil
+	case FingerprintHostKeyMode:
+		return fingerprintHostKeyCallback(fingerprints)
+	case TofuHostKeyMode:
+		return tofuHostKeyCallback(knownHostsFile)
+	default:
+		return knownHostsHostKeyCallback(knownHostsFile)
+	}
+}
+
+func knownHostsHostKeyCallback(file string) (ssh.HostKeyCallback, error) {
+	callback, err := knownhosts.New(file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load known hosts [%s]: %w", file, err)
+	}
+
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		return toHostKeyError(KnownHostsHostKeyMode, hostname, key, callback(hostname, remote, key))
+	}, nil
+}
+
+func fingerprintHostKeyCallback(fingerprints []string) (ssh.HostKeyCallback, error) {
+	if len(fingerprints) == 0 {
+		return nil, fmt.Errorf("missing host key fingerprints")
+	}
+
+	expected := make([]string, len(fingerprints))
+	for i, fingerprint := range fingerprints {
+		expected[i] = "SHA256:" + strings.TrimPrefix(strings.TrimSpace(fingerprint), "SHA256:")
+	}
+
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		fingerprint := ssh.FingerprintSHA256(key)
+
+		for _, e := range expected {
+			if e == fingerprint {
+				return nil
+			}
+		}
+
+		return &HostKeyError{
+			Host:        hostname,
+			Mode:        FingerprintHostKeyMode,
+			Fingerprint: fingerprint,
+			Expected:    expected,
+		}
+	}, nil
+}
+
+// tofuMutex serializes the trust on first use known hosts files updates.
+var tofuMutex sync.Mutex
+
+func tofuHostKeyCallback(file string) (ssh.HostKeyCallback, error) {
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return nil, fmt.Errorf("unable to create known hosts directory for [%s]: %w", file, err)
+	}
+
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		tofuMutex.Lock()
+		defer tofuMutex.Unlock()
+
+		f, err := os.OpenFile(file, os.O_RDWR|os.O_CREATE, 0o600)
+		if err != nil {
+			return fmt.Errorf("unable to open known hosts [%s]: %w", file, err)
+		}
+		defer f.Close()
+
+		callback, err := knownhosts.New(file)
+		if err != nil {
+			return fmt.Errorf("unable to load known hosts [%s]: %w", file, err)
+		}
+
+		err = callback(hostname, remote, key)
+
+		var keyErr *knownhosts.KeyError
+		if errors.As(err, &keyErr) && len(keyErr.Want) == 0 {
+			// first use: the host is unknown, trust and record its key
+			line := knownhosts.Line([]string{knownhosts.Normalize(hostname)}, key)
+			if _, err = f.Seek(0, io.SeekEnd); err == nil {
+				_, err = f.WriteString(line + "\n")
+			}
+			if err != nil {
+				return fmt.Errorf("unable to record host key into known hosts [%s]: %w", file, err)
+			}
+
+			return nil
+		}
+
+		return toHostKeyError(TofuHostKeyMode, hostname, key, err)
+	}, nil
+}
+
+func toHostKeyError(mode HostKeyMode, hostname string, key ssh.PublicKey, err error) error {
+	var keyErr *knownhosts.KeyError
+	if !errors.As(err, &keyErr) {
+		return err
+	}
+
+	expected := make([]string, len(keyErr.Want))
+	for i, want := range keyErr.Want {
+		expected[i] = ssh.FingerprintSHA256(want.Key)
+	}
+
+	return &HostKeyError{
+		Host:        hostname,
+		Mode:        mode,
+		Fingerprint: ssh.FingerprintSHA256(key),
+		Expected:    expected,
+		Err:         err,
+	}
+}
@@ -0,0 +1,128 @@
+package transfer
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func newTestHostKey(t *testing.T) ssh.PublicKey {
+	t.Helper()
+
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key
+}
+
+func TestTofuHostKeyCallback(t *testing.T) {
+	t.Parallel()
+
+	key, otherKey := newTestHostKey(t), newTestHostKey(t)
+
+	type connection struct {
+		host string
+		key  ssh.PublicKey
+	}
+
+	tests := []struct {
+		name         string
+		connections  []connection
+		wantMismatch bool
+		wantLines    int
+	}{
+		{
+			name:        "first use",
+			connections: []connection{{host: "sftp.example.com:22", key: key}},
+			wantLines:   1,
+		},
+		{
+			name: "known key",
+			connections: []connection{
+				{host: "sftp.example.com:22", key: key},
+				{host: "sftp.example.com:22", key: key},
+			},
+			wantLines: 1,
+		},
+		{
+			name: "other host",
+			connections: []connection{
+				{host: "sftp.example.com:22", key: key},
+				{host: "sftp.example.org:2222", key: otherKey},
+			},
+			wantLines: 2,
+		},
+		{
+			name: "mismatch",
+			connections: []connection{
+				{host: "sftp.example.com:22", key: key},
+				{host: "sftp.example.com:22", key: otherKey},
+			},
+			wantMismatch: true,
+			wantLines:    1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// the known hosts file and its directory are created on first use
+			file := filepath.Join(t.TempDir(), ".ssh", "known_hosts")
+
+			callback, err := tofuHostKeyCallback(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i, c := range tt.connections {
+				remote := &net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 22}
+				err = callback(c.host, remote, c.key)
+
+				if i < len(tt.connections)-1 || !tt.wantMismatch {
+					if err != nil {
+						t.Fatalf("connection %d: unexpected error %v", i, err)
+					}
+
+					continue
+				}
+
+				var hostKeyErr *HostKeyError
+				if !errors.As(err, &hostKeyErr) || !hostKeyErr.Mismatch() {
+					t.Fatalf("connection %d: error = %v, want a host key mismatch", i, err)
+				}
+
+				if hostKeyErr.Mode != TofuHostKeyMode || hostKeyErr.Fingerprint != ssh.FingerprintSHA256(c.key) {
+					t.Errorf("connection %d: unexpected host key error %+v", i, hostKeyErr)
+				}
+
+				if len(hostKeyErr.Expected) != 1 || hostKeyErr.Expected[0] != ssh.FingerprintSHA256(key) {
+					t.Errorf("connection %d: expected = %v, want %s", i, hostKeyErr.Expected, ssh.FingerprintSHA256(key))
+				}
+			}
+
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if lines := strings.Count(string(data), "\n"); lines != tt.wantLines {
+				t.Errorf("known hosts has %d lines, want %d:\n%s", lines, tt.wantLines, data)
+			}
+		})
+	}
+}
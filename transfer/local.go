@@ -0,0 +1,305 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"syscall"
+	"time"
+)
+
+var _ Client = (*LocalClient)(nil)
+
+// LocalClient is the local filesystem [Client] implementation, for local directories and network mounts.
+// Its paths are slash separated, and resolved in its root directory: they cannot escape it.
+type LocalClient struct {
+	root            string
+	transferOptions []TransferOption
+}
+
+// NewLocalClient returns a [LocalClient] for a root directory, implementing [Client].
+// The provided [TransferOption] are applied by default to all the transfers.
+func NewLocalClient(root string, transferOptions ...TransferOption) *LocalClient {
+	return &LocalClient{
+		root:            filepath.Clean(root),
+		transferOptions: transferOptions,
+	}
+}
+
+// Root returns the root directory the client paths are resolved in.
+func (c *LocalClient) Root() string {
+	return c.root
+}
+
+// KeepAlive checks that the root directory is still reachable, to detect unmounted network shares.
+func (c *LocalClient) KeepAlive() error {
+	info, err := os.Stat(c.root)
+	if err != nil {
+		return fmt.Errorf("keepalive failure on [%s]: %w", c.root, err)
+	}
+
+	if !info.IsDir() {
+		return fmt.Errorf("keepalive failure on [%s]: not a directory", c.root)
+	}
+
+	return nil
+}
+
+// List returns the files (directories excluded) of a directory.
+func (c *LocalClient) List(ctx context.Context, dir string) ([]fs.FileInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	entries, err := ListLocal(c.resolve(dir))
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]fs.FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		infos = append(infos, entry.Info)
+	}
+
+	return infos, nil
+}
+
+// Walk returns the files (directories excluded) of a directory tree, with their paths relative to the directory.
+func (c *LocalClient) Walk(ctx context.Context, dir string) ([]WalkEntry, error) {
+	return WalkLocal(ctx, c.resolve(dir))
+}
+
+// Upload copies a local file to a path of the root directory, creating the missing directories, and returns a [TransferResult].
+// It supports the same options as [SftpClient.Upload].
+func (c *LocalClient) Upload(ctx context.Context, localPath string, remotePath string, options ...TransferOption) (*TransferResult, error) {
+	return streamUpload(ctx, c, localPath, remotePath, c.applyTransferOptions(options...))
+}
+
+// Download copies a file of the root directory to a local path, creating the missing local directories, and returns a [TransferResult].
+// It supports the same options as [SftpClient.Download].
+func (c *LocalClient) Download(ctx context.Context, remotePath string, localPath string, options ...TransferOption) (*TransferResult, error) {
+	return streamDownload(ctx, c, remotePath, localPath, c.applyTransferOptions(options...))
+}
+
+// Move renames a file, the destination folder must exist.
+func (c *LocalClient) Move(ctx context.Context, sourcePath string, destinationPath string) error {
+	if _, err := c.Stat(ctx, sourcePath); err != nil {
+		return err
+	}
+
+	if _, err := c.Stat(ctx, path.Dir(destinationPath)); err != nil {
+		return err
+	}
+
+	return MoveLocal(c.resolve(sourcePath), c.resolve(destinationPath))
+}
+
+// Remove removes a file or empty directory.
+func (c *LocalClient) Remove(ctx context.Context, path string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := os.Remove(c.resolve(path)); err != nil {
+		return fmt.Errorf("unable to remove local path [%s]: %w", path, err)
+	}
+
+	return nil
+}
+
+// Stat returns the [fs.FileInfo] of a path.
+func (c *LocalClient) Stat(ctx context.Context, path string) (fs.FileInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(c.resolve(path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("local path [%s] does not exist: %w", path, err)
+		}
+
+		return nil, fmt.Errorf("unable to stat local path [%s]: %w", path, err)
+	}
+
+	return info, nil
+}
+
+// Mkdir creates a directory, and all its missing parents.
+func (c *LocalClient) Mkdir(ctx context.Context, path string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(c.resolve(path), 0o755); err != nil {
+		return fmt.Errorf("unable to create local directory [%s]: %w", path, err)
+	}
+
+	return nil
+}
+
+// Open opens a file for reading.
+func (c *LocalClient) Open(ctx context.Context, path string) (io.ReadCloser, error) {
+	f, err := c.read(ctx, path, 0)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open local file [%s]: %w", path, err)
+	}
+
+	return f, nil
+}
+
+// Chtimes changes the modification time of a file.
+func (c *LocalClient) Chtimes(ctx context.Context, path string, modTime time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := os.Chtimes(c.resolve(path), modTime, modTime); err != nil {
+		return fmt.Errorf("unable to change local file [%s] times: %w", path, err)
+	}
+
+	return nil
+}
+
+// Close does nothing, a [LocalClient] holding no connection.
+func (c *LocalClient) Close() error {
+	return nil
+}
+
+// resolve returns the local path of a slash separated path, in the root directory.
+func (c *LocalClient) resolve(p string) string {
+	return filepath.Join(c.root, filepath.FromSlash(path.Clean("/"+p)))
+}
+
+func (c *LocalClient) read(ctx context.Context, path string, offset int64) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(c.resolve(path))
+	if err != nil {
+		return nil, err
+	}
+
+	if err = seek(offset, f); err != nil {
+		f.Close()
+
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (c *LocalClient) write(ctx context.Context, path string, r io.Reader, _ int64, append bool) (int64, error) {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if append {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+
+	f, err := os.OpenFile(c.resolve(path), flags, 0o644)
+	if err != nil {
+		return 0, fmt.Errorf("unable to open local file: %w", err)
+	}
+	defer f.Close()
+
+	n, err := io.Copy(NewContextWriter(ctx, f), r)
+	if err != nil {
+		return n, err
+	}
+
+	// flushed before being published, network mounts may cache the writes
+	if err = f.Sync(); err != nil {
+		return n, err
+	}
+
+	return n, f.Close()
+}
+
+func (c *LocalClient) replace(_ context.Context, sourcePath string, destinationPath string) error {
+	if err := os.Rename(c.resolve(sourcePath), c.resolve(destinationPath)); err != nil {
+		return fmt.Errorf("unable to rename local file [%s] to [%s]: %w", sourcePath, destinationPath, err)
+	}
+
+	return nil
+}
+
+func (c *LocalClient) applyTransferOptions(options ...TransferOption) TransferOptions {
+	appliedOpts := DefaultTransferOptions()
+	for _, applyOpt := range slices.Concat(c.transferOptions, options) {
+		applyOpt(&appliedOpts)
+	}
+
+	return appliedOpts
+}
+
+// MoveLocal moves a local file, creating the missing destination directories.
+// Across filesystems, like between a local disk and a network mount, the file is copied then removed.
+func MoveLocal(sourcePath string, destinationPath string) error {
+	if err := os.MkdirAll(filepath.Dir(destinationPath), 0o755); err != nil {
+		return fmt.Errorf("unable to create local directory [%s]: %w", filepath.Dir(destinationPath), err)
+	}
+
+	err := os.Rename(sourcePath, destinationPath)
+	if errors.Is(err, syscall.EXDEV) {
+		err = copyLocal(sourcePath, destinationPath)
+		if err == nil {
+			err = os.Remove(sourcePath)
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("unable to move local file [%s] to [%s]: %w", sourcePath, destinationPath, err)
+	}
+
+	return nil
+}
+
+// copyLocal copies a local file with its modification time, through a temporary file renamed once complete.
+func copyLocal(sourcePath string, destinationPath string) error {
+	src, err := os.Open(sourcePath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	temporaryPath := filepath.FromSlash(TemporaryPath(filepath.ToSlash(destinationPath), DefaultTemporaryPattern))
+
+	dst, err := os.OpenFile(temporaryPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	if _, err = io.Copy(dst, src); err == nil {
+		err = dst.Sync()
+	}
+
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Chtimes(temporaryPath, info.ModTime(), info.ModTime())
+	}
+
+	if err == nil {
+		err = os.Rename(temporaryPath, destinationPath)
+	}
+
+	if err != nil {
+		os.Remove(temporaryPath)
+	}
+
+	return err
+}
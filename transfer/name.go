@@ -0,0 +1,55 @@
+package transfer
+
+import (
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultNameDateLayout is the default layout of the {date} name template placeholder.
+const DefaultNameDateLayout = "20060102"
+
+var namePlaceholder = regexp.MustCompile(`\{(\w+)(?::([^}]*))?\}`)
+
+// NameVars are the values of the file name templates placeholders.
+type NameVars struct {
+	Name        string
+	Job         string
+	ExecutionId string
+	Time        time.Time
+}
+
+// RenderName renders a file name template, replacing the placeholders by their values:
+// {name} by the file name, {base} by the file name without extension, {ext} by the file extension (with its dot),
+// {job} by the job name, {execId} by the job execution id and {date:layout} by the time in a Go layout
+// ({date} alone using [DefaultNameDateLayout]). Unknown placeholders are left untouched.
+func RenderName(template string, vars NameVars) string {
+	ext := path.Ext(vars.Name)
+
+	return namePlaceholder.ReplaceAllStringFunc(template, func(placeholder string) string {
+		match := namePlaceholder.FindStringSubmatch(placeholder)
+
+		switch match[1] {
+		case "name":
+			return vars.Name
+		case "base":
+			return strings.TrimSuffix(vars.Name, ext)
+		case "ext":
+			return ext
+		case "job":
+			return vars.Job
+		case "execId":
+			return vars.ExecutionId
+		case "date":
+			layout := match[2]
+			if layout == "" {
+				layout = DefaultNameDateLayout
+			}
+
+			return vars.Time.Format(layout)
+		default:
+			return placeholder
+		}
+	})
+}
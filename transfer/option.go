@@ -0,0 +1,377 @@
+package transfer
+
+import (
+	"crypto/tls"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	DefaultSftpPort           = 22        // default SFTP port
+	DefaultChunkSize          = 32 * 1024 // default SFTP packet size, in bytes
+	DefaultConcurrentRequests = 64        // default SFTP in-flight requests per file
+	DefaultFtpPort            = 21        // default FTP port, also used by FTP over explicit TLS
+	DefaultFtpsImplicitPort   = 990       // default FTP over implicit TLS port
+)
+
+// Options are options for the [SftpClientFactory] implementations.
+type Options struct {
+	Protocol              Protocol
+	Host                  string
+	Port                  int
+	User                  string
+	Password              string
+	KeyPath               string
+	KeyPassphrase         string
+	CertificatePath       string
+	AuthOrder             []AuthKind
+	KnownHostsFile        string
+	HostKeyMode           HostKeyMode
+	Fingerprints          []string
+	Timeout               time.Duration
+	ChunkSize             int
+	ConcurrentRequests    int
+	TransferOptions       []TransferOption
+	AuthMethods           []ssh.AuthMethod
+	HostKeyCallback       ssh.HostKeyCallback
+	FtpMode               FtpMode
+	ActiveAddress         string
+	TLSCertificatePath    string
+	TLSKeyPath            string
+	TLSCAPath             string
+	TLSServerName         string
+	TLSInsecureSkipVerify bool
+	TLSConfig             *tls.Config
+	Root                  string
+	Bucket                string
+	Region                string
+	S3Secure              bool
+	S3PathStyle           bool
+}
+
+// DefaultSftpClientOptions are the default options used in the [DefaultSftpClientFactory].
+func DefaultSftpClientOptions() Options {
+	return Options{
+		Port:               DefaultSftpPort,
+		Timeout:            30 * time.Second,
+		ChunkSize:          DefaultChunkSize,
+		ConcurrentRequests: DefaultConcurrentRequests,
+		HostKeyMode:        KnownHostsHostKeyMode,
+	}
+}
+
+// DefaultFtpClientOptions are the default options used in the [DefaultFtpClientFactory].
+// The port defaults to the [Protocol] one.
+func DefaultFtpClientOptions() Options {
+	return Options{
+		Protocol: FtpProtocol,
+		Timeout:  30 * time.Second,
+		FtpMode:  PassiveFtpMode,
+	}
+}
+
+// DefaultLocalClientOptions are the default options used in the [DefaultLocalClientFactory].
+func DefaultLocalClientOptions() Options {
+	return Options{
+		Protocol: LocalProtocol,
+	}
+}
+
+// DefaultS3ClientOptions are the default options used in the [DefaultS3ClientFactory].
+// The port defaults to the scheme one, 443 over TLS.
+func DefaultS3ClientOptions() Options {
+	return Options{
+		Protocol: S3Protocol,
+		Timeout:  30 * time.Second,
+		S3Secure: true,
+	}
+}
+
+// SftpClientOption are functional options for the [SftpClientFactory] implementations.
+type SftpClientOption func(o *Options)
+
+// ClientOption is an alias of [SftpClientOption], for the factories of all the [Protocol].
+type ClientOption = SftpClientOption
+
+// WithProtocol is used to specify the [Protocol] to connect with.
+func WithProtocol(p Protocol) SftpClientOption {
+	return func(o *Options) {
+		o.Protocol = p
+	}
+}
+
+// WithHost is used to specify the host to connect to.
+func WithHost(h string) SftpClientOption {
+	return func(o *Options) {
+		o.Host = h
+	}
+}
+
+// WithPort is used to specify the port to connect to.
+func WithPort(p int) SftpClientOption {
+	return func(o *Options) {
+		o.Port = p
+	}
+}
+
+// WithUser is used to specify the user to authenticate with.
+func WithUser(u string) SftpClientOption {
+	return func(o *Options) {
+		o.User = u
+	}
+}
+
+// WithPassword is used to specify the password to authenticate with.
+func WithPassword(p string) SftpClientOption {
+	return func(o *Options) {
+		o.Password = p
+	}
+}
+
+// WithKeyPath is used to specify the private key file to authenticate with.
+func WithKeyPath(p string) SftpClientOption {
+	return func(o *Options) {
+		o.KeyPath = p
+	}
+}
+
+// WithKeyPassphrase is used to specify the passphrase of an encrypted private key.
+func WithKeyPassphrase(p string) SftpClientOption {
+	return func(o *Options) {
+		o.KeyPassphrase = p
+	}
+}
+
+// WithCertificatePath is used to specify the SSH certificate file, signed for the private key, to authenticate with.
+func WithCertificatePath(p string) SftpClientOption {
+	return func(o *Options) {
+		o.CertificatePath = p
+	}
+}
+
+// WithAuthOrder is used to specify the order in which the [AuthKind] are tried.
+func WithAuthOrder(k ...AuthKind) SftpClientOption {
+	return func(o *Options) {
+		o.AuthOrder = k
+	}
+}
+
+// WithKnownHostsFile is used to specify the known_hosts file used by the known_hosts and tofu [HostKeyMode].
+func WithKnownHostsFile(f string) SftpClientOption {
+	return func(o *Options) {
+		o.KnownHostsFile = f
+	}
+}
+
+// WithHostKeyMode is used to specify the [HostKeyMode] verifying the server host key.
+func WithHostKeyMode(m HostKeyMode) SftpClientOption {
+	return func(o *Options) {
+		o.HostKeyMode = m
+	}
+}
+
+// WithFingerprints is used to specify the pinned SHA256 host key fingerprints, for the fingerprint [HostKeyMode].
+func WithFingerprints(f ...string) SftpClientOption {
+	return func(o *Options) {
+		o.Fingerprints = f
+	}
+}
+
+// WithEndpoint is used to apply all the settings of a configured [Endpoint].
+func WithEndpoint(e *Endpoint) SftpClientOption {
+	return func(o *Options) {
+		o.Protocol = e.Protocol
+		o.Host = e.Host
+		o.User = e.User
+		o.Password = e.Password
+		o.KeyPath = e.KeyPath
+		o.KeyPassphrase = e.KeyPassphrase
+		o.CertificatePath = e.CertificatePath
+		o.AuthOrder = e.AuthOrder
+		o.KnownHostsFile = e.KnownHostsFile
+		o.HostKeyMode = e.HostKeyMode
+		o.Fingerprints = e.Fingerprints
+		o.FtpMode = e.FtpMode
+		o.ActiveAddress = e.ActiveAddress
+		o.TLSCertificatePath = e.TLSCertificatePath
+		o.TLSKeyPath = e.TLSKeyPath
+		o.TLSCAPath = e.TLSCAPath
+		o.TLSServerName = e.TLSServerName
+		o.TLSInsecureSkipVerify = e.TLSInsecureSkipVerify
+		o.Root = e.Root
+		o.Bucket = e.Bucket
+		o.Region = e.Region
+		o.S3Secure = e.S3Secure
+		o.S3PathStyle = e.S3PathStyle
+
+		if e.Port > 0 {
+			o.Port = e.Port
+		}
+
+		if e.Timeout > 0 {
+			o.Timeout = e.Timeout
+		}
+
+		if e.ChunkSize > 0 {
+			o.ChunkSize = e.ChunkSize
+		}
+
+		if e.ConcurrentRequests > 0 {
+			o.ConcurrentRequests = e.ConcurrentRequests
+		}
+
+		if e.Resume {
+			o.TransferOptions = append(o.TransferOptions, WithResume(e.ResumeVerify))
+		}
+
+		if e.Atomic {
+			o.TransferOptions = append(o.TransferOptions, WithAtomic(e.TemporaryPattern))
+		}
+
+		o.TransferOptions = append(o.TransferOptions, WithChecksum(e.Checksum, e.ChecksumVerify))
+
+		if e.ChecksumSidecar {
+			o.TransferOptions = append(o.TransferOptions, WithSidecar())
+		}
+
+		if len(e.UploadStages) > 0 {
+			o.TransferOptions = append(o.TransferOptions, WithUploadStages(e.UploadStages...))
+		}
+
+		if len(e.DownloadStages) > 0 {
+			o.TransferOptions = append(o.TransferOptions, WithDownloadStages(e.DownloadStages...))
+		}
+	}
+}
+
+// WithTimeout is used to specify the connection timeout.
+func WithTimeout(t time.Duration) SftpClientOption {
+	return func(o *Options) {
+		o.Timeout = t
+	}
+}
+
+// WithChunkSize is used to specify the SFTP packet size, in bytes, used for reads and writes.
+func WithChunkSize(s int) SftpClientOption {
+	return func(o *Options) {
+		o.ChunkSize = s
+	}
+}
+
+// WithConcurrentRequests is used to specify the maximum SFTP in-flight requests per file.
+// A value of 1 disables the concurrent reads and writes.
+func WithConcurrentRequests(n int) SftpClientOption {
+	return func(o *Options) {
+		o.ConcurrentRequests = n
+	}
+}
+
+// WithTransferOptions is used to specify the default [TransferOption] applied to all the client transfers.
+func WithTransferOptions(t ...TransferOption) SftpClientOption {
+	return func(o *Options) {
+		o.TransferOptions = append(o.TransferOptions, t...)
+	}
+}
+
+// WithAuthMethods is used to specify additional [ssh.AuthMethod] to authenticate with, tried after the [AuthKind] ones.
+func WithAuthMethods(m ...ssh.AuthMethod) SftpClientOption {
+	return func(o *Options) {
+		o.AuthMethods = append(o.AuthMethods, m...)
+	}
+}
+
+// WithHostKeyCallback is used to specify a custom [ssh.HostKeyCallback] verifying the server host key, taking precedence over the [HostKeyMode].
+func WithHostKeyCallback(c ssh.HostKeyCallback) SftpClientOption {
+	return func(o *Options) {
+		o.HostKeyCallback = c
+	}
+}
+
+// WithFtpMode is used to specify the FTP data connection [FtpMode].
+func WithFtpMode(m FtpMode) SftpClientOption {
+	return func(o *Options) {
+		o.FtpMode = m
+	}
+}
+
+// WithActiveAddress is used to specify the local IP address the server connects back to in active FTP mode.
+// By default, the local address of the control connection is used.
+func WithActiveAddress(a string) SftpClientOption {
+	return func(o *Options) {
+		o.ActiveAddress = a
+	}
+}
+
+// WithTLSClientCertificate is used to specify the PEM client certificate and key files to authenticate with over FTPS.
+func WithTLSClientCertificate(certificatePath string, keyPath string) SftpClientOption {
+	return func(o *Options) {
+		o.TLSCertificatePath = certificatePath
+		o.TLSKeyPath = keyPath
+	}
+}
+
+// WithTLSCAPath is used to specify the PEM CA certificates file verifying the FTPS server certificate,
+// instead of the system ones.
+func WithTLSCAPath(p string) SftpClientOption {
+	return func(o *Options) {
+		o.TLSCAPath = p
+	}
+}
+
+// WithTLSServerName is used to specify the name verified in the FTPS server certificate, the host by default.
+func WithTLSServerName(n string) SftpClientOption {
+	return func(o *Options) {
+		o.TLSServerName = n
+	}
+}
+
+// WithTLSInsecureSkipVerify is used to skip the FTPS server certificate verification, for tests only.
+func WithTLSInsecureSkipVerify(v bool) SftpClientOption {
+	return func(o *Options) {
+		o.TLSInsecureSkipVerify = v
+	}
+}
+
+// WithTLSConfig is used to specify a custom [tls.Config] for FTPS, taking precedence over the other TLS options.
+func WithTLSConfig(c *tls.Config) SftpClientOption {
+	return func(o *Options) {
+		o.TLSConfig = c
+	}
+}
+
+// WithRoot is used to specify the directory (local) or key prefix (S3) the client paths are resolved in.
+func WithRoot(r string) SftpClientOption {
+	return func(o *Options) {
+		o.Root = r
+	}
+}
+
+// WithBucket is used to specify the S3 bucket to connect to.
+func WithBucket(b string) SftpClientOption {
+	return func(o *Options) {
+		o.Bucket = b
+	}
+}
+
+// WithRegion is used to specify the S3 bucket region, resolved from the bucket location by default.
+func WithRegion(r string) SftpClientOption {
+	return func(o *Options) {
+		o.Region = r
+	}
+}
+
+// WithS3Secure is used to specify if the S3 endpoint is reached over TLS, true by default.
+func WithS3Secure(s bool) SftpClientOption {
+	return func(o *Options) {
+		o.S3Secure = s
+	}
+}
+
+// WithS3PathStyle is used to address the S3 buckets in the URL path instead of the host name, as required by MinIO
+// and most S3 compatible storages.
+func WithS3PathStyle(p bool) SftpClientOption {
+	return func(o *Options) {
+		o.S3PathStyle = p
+	}
+}
@@ -0,0 +1,277 @@
+package transfer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+)
+
+const pgpArmorHeader = "-----BEGIN PGP"
+
+var (
+	// ErrPgpNotEncrypted is returned when a file expected to be decrypted is not an encrypted OpenPGP message.
+	ErrPgpNotEncrypted = errors.New("pgp message is not encrypted")
+	// ErrPgpNotSigned is returned when a file expected to be verified is not a signed OpenPGP message.
+	ErrPgpNotSigned = errors.New("pgp message is not signed")
+	// ErrPgpBadSignature is returned when a file signature is invalid, or was not made by a trusted key.
+	ErrPgpBadSignature = errors.New("pgp signature is invalid")
+)
+
+// PgpKeyring is a list of OpenPGP keys, public or private.
+type PgpKeyring = openpgp.EntityList
+
+// PgpOptions are options for the [PgpEncryptStage] and [PgpDecryptStage].
+type PgpOptions struct {
+	Signer     *openpgp.Entity
+	VerifyKeys PgpKeyring
+	Armor      bool
+}
+
+// DefaultPgpOptions are the default options used in the [PgpEncryptStage] and [PgpDecryptStage].
+func DefaultPgpOptions() PgpOptions {
+	return PgpOptions{
+		Signer:     nil,
+		VerifyKeys: nil,
+		Armor:      false,
+	}
+}
+
+// PgpOption are functional options for the [PgpEncryptStage] and [PgpDecryptStage].
+type PgpOption func(o *PgpOptions)
+
+// WithPgpSigner is used to sign the encrypted files with a private key, which must be decrypted.
+func WithPgpSigner(signer *openpgp.Entity) PgpOption {
+	return func(o *PgpOptions) {
+		o.Signer = signer
+	}
+}
+
+// WithPgpVerifyKeys is used to require the decrypted files to be signed by one of the provided public keys,
+// files without a valid signature of these keys being rejected.
+func WithPgpVerifyKeys(keys PgpKeyring) PgpOption {
+	return func(o *PgpOptions) {
+		o.VerifyKeys = keys
+	}
+}
+
+// WithPgpArmor is used to write ASCII armored messages instead of binary ones.
+// The decryption accepts both, whatever this option.
+func WithPgpArmor(armor bool) PgpOption {
+	return func(o *PgpOptions) {
+		o.Armor = armor
+	}
+}
+
+// LoadPgpKeyring returns the keys of ASCII armored or binary OpenPGP keyring files.
+func LoadPgpKeyring(paths ...string) (PgpKeyring, error) {
+	var keys PgpKeyring
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read pgp keyring [%s]: %w", path, err)
+		}
+
+		var fileKeys PgpKeyring
+		if bytes.HasPrefix(bytes.TrimSpace(data), []byte(pgpArmorHeader)) {
+			fileKeys, err = openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+		} else {
+			fileKeys, err = openpgp.ReadKeyRing(bytes.NewReader(data))
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid pgp keyring [%s]: %w", path, err)
+		}
+
+		keys = append(keys, fileKeys...)
+	}
+
+	return keys, nil
+}
+
+// UnlockPgpKeys decrypts the passphrase protected private keys of a keyring, for signing and decryption.
+func UnlockPgpKeys(keys PgpKeyring, passphrase string) error {
+	for _, key := range keys {
+		if err := key.DecryptPrivateKeys([]byte(passphrase)); err != nil {
+			return fmt.Errorf("unable to unlock pgp key %X: %w", key.PrimaryKey.Fingerprint, err)
+		}
+	}
+
+	return nil
+}
+
+// PgpEncryptStage is a [Stage] encrypting files to recipient public keys, and optionally signing them.
+// Without recipients, files are signed only.
+type PgpEncryptStage struct {
+	recipients PgpKeyring
+	options    PgpOptions
+}
+
+// NewPgpEncryptStage returns a new [PgpEncryptStage] for a list of recipients, and accepts a list of [PgpOption].
+func NewPgpEncryptStage(recipients PgpKeyring, options ...PgpOption) (*PgpEncryptStage, error) {
+	appliedOpts := DefaultPgpOptions()
+	for _, opt := range options {
+		opt(&appliedOpts)
+	}
+
+	if len(recipients) == 0 && appliedOpts.Signer == nil {
+		return nil, fmt.Errorf("missing pgp recipients or signer")
+	}
+
+	if appliedOpts.Signer != nil && (appliedOpts.Signer.PrivateKey == nil || appliedOpts.Signer.PrivateKey.Encrypted) {
+		return nil, fmt.Errorf("pgp signer %X private key is missing or locked", appliedOpts.Signer.PrimaryKey.Fingerprint)
+	}
+
+	return &PgpEncryptStage{
+		recipients: recipients,
+		options:    appliedOpts,
+	}, nil
+}
+
+// Name returns the stage name.
+func (s *PgpEncryptStage) Name() string {
+	if len(s.recipients) == 0 {
+		return "pgp-sign"
+	}
+
+	return "pgp-encrypt"
+}
+
+// Process streams the encrypted and signed message of a reader content to a writer.
+func (s *PgpEncryptStage) Process(ctx context.Context, w io.Writer, r io.Reader) error {
+	out := io.WriteCloser(nopWriteCloser{w})
+	if s.options.Armor {
+		armored, err := armor.Encode(w, "PGP MESSAGE", nil)
+		if err != nil {
+			return err
+		}
+
+		out = armored
+	}
+
+	hints := &openpgp.FileHints{IsBinary: true}
+
+	var plaintext io.WriteCloser
+	var err error
+	if len(s.recipients) > 0 {
+		plaintext, err = openpgp.Encrypt(out, s.recipients, s.options.Signer, hints, nil)
+	} else {
+		plaintext, err = openpgp.Sign(out, s.options.Signer, hints, nil)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(plaintext, r); err != nil {
+		return err
+	}
+
+	if err = plaintext.Close(); err != nil {
+		return err
+	}
+
+	return out.Close()
+}
+
+// PgpDecryptStage is a [Stage] decrypting files with private keys, and optionally verifying their signature.
+// Without private keys, signed only files are verified.
+type PgpDecryptStage struct {
+	keys    PgpKeyring
+	keyring PgpKeyring
+	options PgpOptions
+}
+
+// NewPgpDecryptStage returns a new [PgpDecryptStage] for a list of private keys, and accepts a list of [PgpOption].
+func NewPgpDecryptStage(keys PgpKeyring, options ...PgpOption) (*PgpDecryptStage, error) {
+	appliedOpts := DefaultPgpOptions()
+	for _, opt := range options {
+		opt(&appliedOpts)
+	}
+
+	if len(keys) == 0 && len(appliedOpts.VerifyKeys) == 0 {
+		return nil, fmt.Errorf("missing pgp private keys or verify keys")
+	}
+
+	keyring := make(PgpKeyring, 0, len(keys)+len(appliedOpts.VerifyKeys))
+	keyring = append(keyring, keys...)
+	keyring = append(keyring, appliedOpts.VerifyKeys...)
+
+	return &PgpDecryptStage{
+		keys:    keys,
+		keyring: keyring,
+		options: appliedOpts,
+	}, nil
+}
+
+// Name returns the stage name.
+func (s *PgpDecryptStage) Name() string {
+	if len(s.keys) == 0 {
+		return "pgp-verify"
+	}
+
+	return "pgp-decrypt"
+}
+
+// Process streams the decrypted content of an ASCII armored or binary message to a writer.
+// The signature is verified once the whole message is read: on error, the written content must be discarded.
+func (s *PgpDecryptStage) Process(ctx context.Context, w io.Writer, r io.Reader) error {
+	br := bufio.NewReader(r)
+
+	in := io.Reader(br)
+	if head, _ := br.Peek(len(pgpArmorHeader)); string(head) == pgpArmorHeader {
+		block, err := armor.Decode(br)
+		if err != nil {
+			return err
+		}
+
+		in = block.Body
+	}
+
+	md, err := openpgp.ReadMessage(in, s.keyring, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	if len(s.keys) > 0 && !md.IsEncrypted {
+		return ErrPgpNotEncrypted
+	}
+
+	// the integrity and signature are checked when reaching the end of the message
+	if _, err = io.Copy(w, md.UnverifiedBody); err != nil {
+		return err
+	}
+
+	if len(s.options.VerifyKeys) == 0 {
+		return nil
+	}
+
+	switch {
+	case !md.IsSigned:
+		return ErrPgpNotSigned
+	case md.SignedBy == nil || len(s.options.VerifyKeys.KeysById(md.SignedByKeyId)) == 0:
+		return fmt.Errorf("%w: unknown signer key %X", ErrPgpBadSignature, md.SignedByKeyId)
+	case md.SignatureError != nil:
+		return fmt.Errorf("%w: %v", ErrPgpBadSignature, md.SignatureError)
+	case md.Signature == nil:
+		return fmt.Errorf("%w: missing signature", ErrPgpBadSignature)
+	}
+
+	return nil
+}
+
+// nopWriteCloser is an [io.WriteCloser] of an [io.Writer], with a no-op Close.
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,731 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// SessionClient is the interface for [Client] able to open additional sessions over their connection.
+type SessionClient interface {
+	Client
+	NewSession() (Client, error)
+}
+
+// KeepAliveClient is the interface for [Client] able to send keepalive requests over their connection.
+type KeepAliveClient interface {
+	Client
+	KeepAlive() error
+}
+
+// PoolOptions are options for the [Pool].
+type PoolOptions struct {
+	Size        int
+	Connections int
+	KeepAlive   time.Duration
+	IdleTimeout time.Duration
+	MaxLifetime time.Duration
+	Validate    bool
+	Retrier     *Retrier
+}
+
+// DefaultPoolOptions are the default options used in the [Pool].
+func DefaultPoolOptions() PoolOptions {
+	return PoolOptions{
+		Size:        1,
+		Connections: 1,
+		KeepAlive:   0,
+		IdleTimeout: 0,
+		MaxLifetime: 0,
+		Validate:    false,
+		Retrier:     NewRetrier(),
+	}
+}
+
+// PoolOption are functional options for the [Pool].
+type PoolOption func(o *PoolOptions)
+
+// WithSize is used to specify the maximum number of sessions of the pool, bounding the concurrent transfers.
+func WithSize(n int) PoolOption {
+	return func(o *PoolOptions) {
+		if n > 0 {
+			o.Size = n
+		}
+	}
+}
+
+// WithConnections is used to specify the number of connections the pool sessions are spread over.
+func WithConnections(n int) PoolOption {
+	return func(o *PoolOptions) {
+		if n > 0 {
+			o.Connections = n
+		}
+	}
+}
+
+// WithKeepAlive is used to send keepalive requests over the pool connections at an interval, 0 to disable.
+// Connections failing to answer are closed, and redialed on next acquisition.
+func WithKeepAlive(d time.Duration) PoolOption {
+	return func(o *PoolOptions) {
+		o.KeepAlive = d
+	}
+}
+
+// WithIdleTimeout is used to close the sessions idle for longer than a duration, 0 to disable.
+func WithIdleTimeout(d time.Duration) PoolOption {
+	return func(o *PoolOptions) {
+		o.IdleTimeout = d
+	}
+}
+
+// WithMaxLifetime is used to stop reusing the connections older than a duration, 0 to disable.
+func WithMaxLifetime(d time.Duration) PoolOption {
+	return func(o *PoolOptions) {
+		o.MaxLifetime = d
+	}
+}
+
+// WithValidate is used to check the health of idle sessions before reusing them, broken ones being replaced.
+func WithValidate(v bool) PoolOption {
+	return func(o *PoolOptions) {
+		o.Validate = v
+	}
+}
+
+// WithRetrier is used to specify the [Retrier] of the connections, and of the operations run on the pool sessions.
+func WithRetrier(r *Retrier) PoolOption {
+	return func(o *PoolOptions) {
+		if r != nil {
+			o.Retrier = r
+		}
+	}
+}
+
+type pooledConnection struct {
+	client    Client
+	sessions  int
+	createdAt time.Time
+	broken    bool
+}
+
+type idleSession struct {
+	client    Client
+	idleSince time.Time
+}
+
+// Pool is a bounded pool of [Client] sessions to an endpoint, spread over one or several connections.
+// Sessions are opened lazily, at most Size sessions are acquired at once, and idle sessions are reused.
+// The pool maintains its connections: keepalive, idle timeout, max lifetime, validation before reuse and redial.
+type Pool struct {
+	factory       SftpClientFactory
+	clientOptions []SftpClientOption
+	options       PoolOptions
+	slots         chan struct{}
+	mutex         sync.Mutex
+	idle          []idleSession
+	sessions      map[Client]*pooledConnection
+	connections   []*pooledConnection
+	dialing       int
+	closed        bool
+	done          chan struct{}
+	wg            sync.WaitGroup
+}
+
+// NewPool returns a new [Pool], creating its connections with a [SftpClientFactory] and a list of [SftpClientOption].
+// If keepalive, idle timeout or max lifetime are enabled, the pool runs a maintenance routine until closed.
+func NewPool(factory SftpClientFactory, clientOptions []SftpClientOption, options ...PoolOption) *Pool {
+	appliedOpts := DefaultPoolOptions()
+	for _, opt := range options {
+		opt(&appliedOpts)
+	}
+
+	if appliedOpts.Connections > appliedOpts.Size {
+		appliedOpts.Connections = appliedOpts.Size
+	}
+
+	pool := &Pool{
+		factory:       factory,
+		clientOptions: clientOptions,
+		options:       appliedOpts,
+		slots:         make(chan struct{}, appliedOpts.Size),
+		sessions:      map[Client]*pooledConnection{},
+		done:          make(chan struct{}),
+	}
+
+	if interval := pool.maintenanceInterval(); interval > 0 {
+		pool.wg.Add(1)
+
+		go pool.maintain(interval)
+	}
+
+	return pool
+}
+
+// Size returns the maximum number of sessions of the [Pool].
+func (p *Pool) Size() int {
+	return p.options.Size
+}
+
+// Retrier returns the [Retrier] of the [Pool].
+func (p *Pool) Retrier() *Retrier {
+	return p.options.Retrier
+}
+
+// Stats returns the number of connections, of sessions, and of idle sessions of the [Pool].
+func (p *Pool) Stats() (connections int, sessions int, idle int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return len(p.connections), len(p.sessions), len(p.idle)
+}
+
+// Acquire returns an idle session of the [Pool], or opens a new one, waiting for a session to be released if the pool is full.
+// Idle sessions are validated before reuse if enabled: broken ones are closed with their connection, and replaced.
+// Failing connections are retried with the [Pool] [Retrier].
+// Acquired sessions must be given back with [Pool.Release], or [Pool.Discard] if broken.
+func (p *Pool) Acquire(ctx context.Context) (Client, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case p.slots <- struct{}{}:
+	}
+
+	for attempt := 1; ; {
+		p.mutex.Lock()
+
+		if p.closed {
+			p.mutex.Unlock()
+			<-p.slots
+
+			return nil, errors.New("transfer pool is closed")
+		}
+
+		client := p.popIdle(time.Now())
+		if client == nil {
+			p.mutex.Unlock()
+
+			client, err := p.open(ctx)
+			if err == nil {
+				return client, nil
+			}
+
+			if err = p.options.Retrier.Wait(ctx, "connection", attempt, err); err != nil {
+				<-p.slots
+
+				return nil, err
+			}
+
+			attempt++
+
+			continue
+		}
+
+		p.mutex.Unlock()
+
+		if !p.options.Validate {
+			return client, nil
+		}
+
+		err := p.validate(ctx, client)
+		if err == nil {
+			return client, nil
+		}
+
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			p.Release(client)
+
+			return nil, ctxErr
+		}
+
+		// broken session: its connection is dropped, and redialed by the next iteration
+		p.mutex.Lock()
+		p.markBroken(client)
+		p.close(client)
+		p.mutex.Unlock()
+	}
+}
+
+// Release gives back a session acquired with [Pool.Acquire].
+func (p *Pool) Release(client Client) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	defer func() { <-p.slots }()
+
+	if connection, ok := p.sessions[client]; p.closed || (ok && connection.broken) {
+		p.close(client)
+
+		return
+	}
+
+	p.idle = append(p.idle, idleSession{client: client, idleSince: time.Now()})
+}
+
+// Discard closes a broken session acquired with [Pool.Acquire], with its connection, instead of giving it back.
+func (p *Pool) Discard(client Client) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	defer func() { <-p.slots }()
+
+	p.markBroken(client)
+	p.close(client)
+}
+
+// Recycle gives back a session acquired with [Pool.Acquire] after an operation: the session is discarded
+// if the operation error denotes a broken connection, and released otherwise.
+func (p *Pool) Recycle(client Client, err error) {
+	if IsConnectionError(err) {
+		p.Discard(client)
+	} else {
+		p.Release(client)
+	}
+}
+
+// Close stops the maintenance routine, and closes the idle sessions and the connections of the [Pool].
+// Acquired sessions are closed when released, and sessions acquired afterwards fail.
+func (p *Pool) Close() error {
+	p.mutex.Lock()
+
+	if p.closed {
+		p.mutex.Unlock()
+
+		return nil
+	}
+
+	p.closed = true
+	close(p.done)
+
+	var errs []error
+	for _, session := range p.idle {
+		if err := p.close(session.client); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	p.idle = nil
+
+	p.mutex.Unlock()
+
+	p.wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// popIdle returns the most recently released idle session still usable, closing the expired ones, or nil if none.
+func (p *Pool) popIdle(now time.Time) Client {
+	for len(p.idle) > 0 {
+		session := p.idle[len(p.idle)-1]
+		p.idle = p.idle[:len(p.idle)-1]
+
+		if p.expired(session, now) {
+			p.close(session.client)
+
+			continue
+		}
+
+		return session.client
+	}
+
+	return nil
+}
+
+func (p *Pool) expired(session idleSession, now time.Time) bool {
+	connection, ok := p.sessions[session.client]
+	if !ok || connection.broken {
+		return true
+	}
+
+	if p.options.IdleTimeout > 0 && now.Sub(session.idleSince) > p.options.IdleTimeout {
+		return true
+	}
+
+	return p.options.MaxLifetime > 0 && now.Sub(connection.createdAt) > p.options.MaxLifetime
+}
+
+func (p *Pool) validate(ctx context.Context, client Client) error {
+	if keepAliveClient, ok := client.(KeepAliveClient); ok {
+		if err := keepAliveClient.KeepAlive(); err != nil {
+			return err
+		}
+	}
+
+	_, err := client.Stat(ctx, ".")
+
+	return err
+}
+
+// open opens a session on the least used connection, or on a new connection while there are less than the configured ones.
+// Connections over their max lifetime do not get new sessions. The pool lock is only held to reserve the session, the
+// connections being dialed without it.
+func (p *Pool) open(ctx context.Context) (Client, error) {
+	p.mutex.Lock()
+	connection := p.reserve(time.Now())
+	p.mutex.Unlock()
+
+	if connection == nil {
+		return p.dial(ctx)
+	}
+
+	var client Client
+	var err error
+	sessionClient, isSessionClient := connection.client.(SessionClient)
+	if isSessionClient {
+		client, err = sessionClient.NewSession()
+	} else {
+		client, err = p.create(ctx)
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if err != nil {
+		// a connection lost while opening a session is dropped, so that the next attempt dials a new one
+		if isSessionClient && IsConnectionError(err) {
+			p.markConnectionBroken(connection)
+		}
+
+		p.unreserve(connection)
+
+		return nil, fmt.Errorf("unable to open pool session: %w", err)
+	}
+
+	p.sessions[client] = connection
+
+	if p.closed {
+		p.close(client)
+
+		return nil, errors.New("transfer pool is closed")
+	}
+
+	return client, nil
+}
+
+// reserve counts a new session on the least used connection, or returns nil if a new connection must be dialed,
+// the pending dial being then counted among the connections.
+func (p *Pool) reserve(now time.Time) *pooledConnection {
+	var connection *pooledConnection
+	for _, c := range p.connections {
+		if p.options.MaxLifetime > 0 && now.Sub(c.createdAt) > p.options.MaxLifetime {
+			continue
+		}
+
+		if connection == nil || c.sessions < connection.sessions {
+			connection = c
+		}
+	}
+
+	if connection == nil || p.usableConnections(now)+p.dialing < p.options.Connections {
+		p.dialing++
+
+		return nil
+	}
+
+	connection.sessions++
+
+	return connection
+}
+
+// unreserve gives back a session reserved on a connection which could not be opened, closing the connection once it has
+// no more sessions.
+func (p *Pool) unreserve(connection *pooledConnection) {
+	connection.sessions--
+
+	if connection.sessions == 0 {
+		connection.client.Close()
+		p.removeConnection(connection)
+	}
+}
+
+// dial opens a new connection, registered with its client as first session.
+func (p *Pool) dial(ctx context.Context) (Client, error) {
+	client, err := p.create(ctx)
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.dialing--
+
+	if err != nil {
+		return nil, err
+	}
+
+	if p.closed {
+		client.Close()
+
+		return nil, errors.New("transfer pool is closed")
+	}
+
+	connection := &pooledConnection{client: client, sessions: 1, createdAt: time.Now()}
+	p.connections = append(p.connections, connection)
+	p.sessions[client] = connection
+
+	return client, nil
+}
+
+// create creates a client with the factory, giving up on it when the context is done first: the client is then closed
+// once created.
+func (p *Pool) create(ctx context.Context) (Client, error) {
+	type created struct {
+		client Client
+		err    error
+	}
+
+	results := make(chan created, 1)
+
+	go func() {
+		client, err := p.factory.Create(p.clientOptions...)
+		results <- created{client: client, err: err}
+	}()
+
+	select {
+	case result := <-results:
+		return result.client, result.err
+	case <-ctx.Done():
+		go func() {
+			if result := <-results; result.err == nil {
+				result.client.Close()
+			}
+		}()
+
+		return nil, ctx.Err()
+	}
+}
+
+func (p *Pool) usableConnections(now time.Time) int {
+	n := 0
+	for _, c := range p.connections {
+		if p.options.MaxLifetime <= 0 || now.Sub(c.createdAt) <= p.options.MaxLifetime {
+			n++
+		}
+	}
+
+	return n
+}
+
+// markBroken flags the connection of a session as broken.
+func (p *Pool) markBroken(client Client) {
+	if connection, ok := p.sessions[client]; ok {
+		p.markConnectionBroken(connection)
+	}
+}
+
+// markConnectionBroken flags a connection as broken: it gets no new sessions, and its idle sessions are closed.
+func (p *Pool) markConnectionBroken(connection *pooledConnection) {
+	if connection.broken {
+		return
+	}
+
+	connection.broken = true
+	p.removeConnection(connection)
+
+	idle := p.idle[:0]
+	for _, session := range p.idle {
+		if p.sessions[session.client] == connection {
+			p.close(session.client)
+		} else {
+			idle = append(idle, session)
+		}
+	}
+	p.idle = idle
+}
+
+// close closes a session, and its connection once it has no more sessions: the connection client is closed last.
+func (p *Pool) close(client Client) error {
+	connection, ok := p.sessions[client]
+	if !ok {
+		return client.Close()
+	}
+
+	delete(p.sessions, client)
+	connection.sessions--
+
+	var err error
+	if client != connection.client {
+		err = client.Close()
+	}
+
+	if connection.sessions == 0 {
+		if connErr := connection.client.Close(); err == nil {
+			err = connErr
+		}
+
+		p.removeConnection(connection)
+	}
+
+	return err
+}
+
+func (p *Pool) removeConnection(connection *pooledConnection) {
+	for i, c := range p.connections {
+		if c == connection {
+			p.connections = append(p.connections[:i], p.connections[i+1:]...)
+
+			return
+		}
+	}
+}
+
+func (p *Pool) maintenanceInterval() time.Duration {
+	var interval time.Duration
+	for _, d := range []time.Duration{p.options.KeepAlive, p.options.IdleTimeout / 2, p.options.MaxLifetime / 2} {
+		if d > 0 && (interval == 0 || d < interval) {
+			interval = d
+		}
+	}
+
+	return interval
+}
+
+// maintain periodically closes the expired idle sessions, and sends keepalive requests over the connections.
+func (p *Pool) maintain(interval time.Duration) {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case now := <-ticker.C:
+			p.reap(now)
+
+			if p.options.KeepAlive > 0 {
+				p.keepAlive()
+			}
+		}
+	}
+}
+
+func (p *Pool) reap(now time.Time) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	idle := p.idle[:0]
+	for _, session := range p.idle {
+		if p.expired(session, now) {
+			p.close(session.client)
+		} else {
+			idle = append(idle, session)
+		}
+	}
+	p.idle = idle
+}
+
+func (p *Pool) keepAlive() {
+	p.mutex.Lock()
+	connections := append([]*pooledConnection{}, p.connections...)
+	p.mutex.Unlock()
+
+	for _, connection := range connections {
+		keepAliveClient, ok := connection.client.(KeepAliveClient)
+		if !ok {
+			continue
+		}
+
+		if err := keepAliveClient.KeepAlive(); err != nil {
+			p.mutex.Lock()
+			p.markConnectionBroken(connection)
+			p.mutex.Unlock()
+		}
+	}
+}
+
+// PoolRegistry holds a [Pool] per [Endpoint], sized by the endpoint concurrency limits,
+// so that concurrent users of an endpoint share its sessions and limits.
+type PoolRegistry struct {
+	factory   SftpClientFactory
+	endpoints *EndpointRegistry
+	mutex     sync.Mutex
+	pools     map[string]*Pool
+}
+
+// NewPoolRegistry returns a new [PoolRegistry].
+func NewPoolRegistry(factory SftpClientFactory, endpoints *EndpointRegistry) *PoolRegistry {
+	return &PoolRegistry{
+		factory:   factory,
+		endpoints: endpoints,
+		pools:     map[string]*Pool{},
+	}
+}
+
+// Get returns the [Pool] of an [Endpoint] by name, created on first use.
+func (r *PoolRegistry) Get(name string) (*Pool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if pool, ok := r.pools[name]; ok {
+		return pool, nil
+	}
+
+	endpoint, err := r.endpoints.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := NewPool(
+		r.factory,
+		[]SftpClientOption{WithEndpoint(endpoint)},
+		WithSize(endpoint.Workers),
+		WithConnections(endpoint.Connections),
+		WithKeepAlive(endpoint.KeepAlive),
+		WithIdleTimeout(endpoint.IdleTimeout),
+		WithMaxLifetime(endpoint.MaxLifetime),
+		WithValidate(endpoint.Validate),
+		WithRetrier(NewRetrier(
+			WithMaxAttempts(endpoint.RetryMaxAttempts),
+			WithBaseDelay(endpoint.RetryBaseDelay),
+			WithMaxDelay(endpoint.RetryMaxDelay),
+			WithJitter(endpoint.RetryJitter),
+		)),
+	)
+
+	r.pools[name] = pool
+
+	return pool, nil
+}
+
+// Start creates the pools of all the endpoints, and checks the connectivity of the endpoints with warmup enabled.
+func (r *PoolRegistry) Start(ctx context.Context) error {
+	for _, name := range r.endpoints.Names() {
+		pool, err := r.Get(name)
+		if err != nil {
+			return err
+		}
+
+		endpoint, err := r.endpoints.Get(name)
+		if err != nil {
+			return err
+		}
+
+		if !endpoint.Warmup {
+			continue
+		}
+
+		client, err := pool.Acquire(ctx)
+		if err != nil {
+			return fmt.Errorf("unable to warm up pool of endpoint %s: %w", name, err)
+		}
+
+		pool.Release(client)
+	}
+
+	return nil
+}
+
+// Close closes all the pools.
+func (r *PoolRegistry) Close() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var errs []error
+	for name, pool := range r.pools {
+		if err := pool.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("unable to close pool of endpoint %s: %w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
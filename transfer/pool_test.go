@@ -0,0 +1,366 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeClient is a [Client] only implementing Stat and Close, the pool does not call the other methods.
+type fakeClient struct {
+	Client
+	id     int
+	broken atomic.Bool
+	closed atomic.Bool
+}
+
+func (c *fakeClient) Stat(ctx context.Context, path string) (fs.FileInfo, error) {
+	if c.broken.Load() {
+		return nil, errors.New("connection lost")
+	}
+
+	return &fileInfo{name: path, mode: fs.ModeDir}, nil
+}
+
+func (c *fakeClient) Close() error {
+	c.closed.Store(true)
+
+	return nil
+}
+
+// fakeSessionClient is a [SessionClient], opening sessions over its connection until it fails.
+type fakeSessionClient struct {
+	fakeClient
+	sessionErr error
+}
+
+func (c *fakeSessionClient) NewSession() (Client, error) {
+	if c.sessionErr != nil {
+		return nil, c.sessionErr
+	}
+
+	return &fakeClient{id: -1}, nil
+}
+
+type fakeFactory struct {
+	mutex    sync.Mutex
+	err      error
+	sessions bool
+	clients  []Client
+}
+
+func (f *fakeFactory) Create(options ...SftpClientOption) (Client, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	var client Client = &fakeClient{id: len(f.clients)}
+	if f.sessions {
+		client = &fakeSessionClient{fakeClient: fakeClient{id: len(f.clients)}}
+	}
+
+	f.clients = append(f.clients, client)
+
+	return client, nil
+}
+
+func (f *fakeFactory) created() int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return len(f.clients)
+}
+
+func assertPoolStats(t *testing.T, pool *Pool, connections int, sessions int, idle int) {
+	t.Helper()
+
+	if c, s, i := pool.Stats(); c != connections || s != sessions || i != idle {
+		t.Errorf("Stats() = (%d, %d, %d), want (%d, %d, %d)", c, s, i, connections, sessions, idle)
+	}
+}
+
+func acquireFake(t *testing.T, pool *Pool) *fakeClient {
+	t.Helper()
+
+	client, err := pool.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	return client.(*fakeClient)
+}
+
+func TestPoolAcquireRelease(t *testing.T) {
+	t.Parallel()
+
+	factory := &fakeFactory{}
+	pool := NewPool(factory, nil, WithSize(2))
+	defer pool.Close()
+
+	a := acquireFake(t, pool)
+	b := acquireFake(t, pool)
+
+	if a == b {
+		t.Fatal("Acquire() returned the same session twice")
+	}
+
+	assertPoolStats(t, pool, 1, 2, 0)
+
+	pool.Release(b)
+	assertPoolStats(t, pool, 1, 2, 1)
+
+	// idle sessions are reused instead of opening new ones
+	if c := acquireFake(t, pool); c != b {
+		t.Errorf("Acquire() = session %d, want the released session %d", c.id, b.id)
+	}
+
+	if n := factory.created(); n != 2 {
+		t.Errorf("factory created %d clients, want 2", n)
+	}
+
+	if a.closed.Load() || b.closed.Load() {
+		t.Error("released sessions must not be closed")
+	}
+}
+
+func TestPoolAcquireWaits(t *testing.T) {
+	t.Parallel()
+
+	pool := NewPool(&fakeFactory{}, nil, WithSize(1))
+	defer pool.Close()
+
+	a := acquireFake(t, pool)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := pool.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Acquire() on a full pool error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	acquired := make(chan Client)
+	go func() {
+		client, _ := pool.Acquire(context.Background())
+		acquired <- client
+	}()
+
+	pool.Release(a)
+
+	select {
+	case client := <-acquired:
+		if client != a {
+			t.Errorf("Acquire() = %v, want the released session", client)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Acquire() did not return once a session was released")
+	}
+}
+
+func TestPoolDiscard(t *testing.T) {
+	t.Parallel()
+
+	factory := &fakeFactory{}
+	pool := NewPool(factory, nil, WithSize(2), WithConnections(1))
+	defer pool.Close()
+
+	a := acquireFake(t, pool)
+	b := acquireFake(t, pool)
+	assertPoolStats(t, pool, 1, 2, 0)
+
+	pool.Release(b)
+
+	// the broken connection is dropped with its idle sessions, and redialed on next acquisition
+	pool.Discard(a)
+	assertPoolStats(t, pool, 0, 0, 0)
+
+	if !a.closed.Load() || !b.closed.Load() {
+		t.Errorf("sessions of a broken connection must be closed, got a=%t b=%t", a.closed.Load(), b.closed.Load())
+	}
+
+	c := acquireFake(t, pool)
+	if c == a || c == b {
+		t.Error("Acquire() returned a session of the broken connection")
+	}
+
+	assertPoolStats(t, pool, 1, 1, 0)
+}
+
+func TestPoolRecycle(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		err        error
+		wantClosed bool
+		wantIdle   int
+	}{
+		{name: "success", err: nil, wantClosed: false, wantIdle: 1},
+		{name: "operation error", err: fs.ErrNotExist, wantClosed: false, wantIdle: 1},
+		{name: "connection error", err: fmt.Errorf("unable to list: %w", io.ErrUnexpectedEOF), wantClosed: true, wantIdle: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			pool := NewPool(&fakeFactory{}, nil)
+			defer pool.Close()
+
+			client := acquireFake(t, pool)
+			pool.Recycle(client, tt.err)
+
+			if client.closed.Load() != tt.wantClosed {
+				t.Errorf("session closed = %t, want %t", client.closed.Load(), tt.wantClosed)
+			}
+
+			if _, _, idle := pool.Stats(); idle != tt.wantIdle {
+				t.Errorf("idle sessions = %d, want %d", idle, tt.wantIdle)
+			}
+		})
+	}
+}
+
+func TestPoolValidate(t *testing.T) {
+	t.Parallel()
+
+	factory := &fakeFactory{}
+	pool := NewPool(factory, nil, WithValidate(true))
+	defer pool.Close()
+
+	a := acquireFake(t, pool)
+	pool.Release(a)
+
+	a.broken.Store(true)
+
+	b := acquireFake(t, pool)
+	if b == a {
+		t.Fatal("Acquire() reused a broken session")
+	}
+
+	if !a.closed.Load() {
+		t.Error("broken session must be closed")
+	}
+
+	assertPoolStats(t, pool, 1, 1, 0)
+}
+
+func TestPoolFactoryError(t *testing.T) {
+	t.Parallel()
+
+	factory := &fakeFactory{err: errors.New("connection refused")}
+	pool := NewPool(factory, nil, WithSize(1))
+	defer pool.Close()
+
+	if _, err := pool.Acquire(context.Background()); err == nil {
+		t.Fatal("Acquire() error = nil, want the factory error")
+	}
+
+	assertPoolStats(t, pool, 0, 0, 0)
+
+	// the slot of the failed acquisition is given back
+	factory.mutex.Lock()
+	factory.err = nil
+	factory.mutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if _, err := pool.Acquire(ctx); err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+}
+
+func TestPoolClose(t *testing.T) {
+	t.Parallel()
+
+	pool := NewPool(&fakeFactory{}, nil, WithSize(2))
+
+	a := acquireFake(t, pool)
+	b := acquireFake(t, pool)
+	pool.Release(b)
+
+	if err := pool.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if !b.closed.Load() {
+		t.Error("idle session must be closed with the pool")
+	}
+
+	// acquired sessions are closed once released
+	pool.Release(a)
+
+	if !a.closed.Load() {
+		t.Error("session released after close must be closed")
+	}
+
+	if _, err := pool.Acquire(context.Background()); err == nil {
+		t.Error("Acquire() on a closed pool error = nil")
+	}
+}
+
+func TestPoolSessionError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		err         error
+		wantBroken  bool
+		wantCreated int
+	}{
+		{name: "connection lost", err: io.EOF, wantBroken: true, wantCreated: 2},
+		{name: "session refused", err: errors.New("ssh: rejected: administratively prohibited"), wantBroken: false, wantCreated: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			factory := &fakeFactory{sessions: true}
+			pool := NewPool(factory, nil, WithSize(2), WithConnections(1))
+			defer pool.Close()
+
+			client, err := pool.Acquire(context.Background())
+			if err != nil {
+				t.Fatalf("Acquire() error = %v", err)
+			}
+
+			connection := client.(*fakeSessionClient)
+			connection.sessionErr = fmt.Errorf("unable to start sftp session: %w", tt.err)
+
+			if _, err = pool.Acquire(context.Background()); err == nil {
+				t.Fatal("Acquire() error = nil, want the session error")
+			}
+
+			// a lost connection is dropped, its acquired sessions being closed once released
+			if connections, _, _ := pool.Stats(); (connections == 0) != tt.wantBroken {
+				t.Errorf("connections = %d, want broken %t", connections, tt.wantBroken)
+			}
+
+			connection.sessionErr = nil
+
+			if _, err = pool.Acquire(context.Background()); err != nil {
+				t.Fatalf("Acquire() error = %v", err)
+			}
+
+			if n := factory.created(); n != tt.wantCreated {
+				t.Errorf("factory created %d clients, want %d", n, tt.wantCreated)
+			}
+
+			pool.Release(connection)
+
+			if connection.closed.Load() != tt.wantBroken {
+				t.Errorf("released session of the connection closed = %t, want %t", connection.closed.Load(), tt.wantBroken)
+			}
+		})
+	}
+}
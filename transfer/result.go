@@ -0,0 +1,20 @@
+package transfer
+
+import "time"
+
+// TransferResult is the result of a [Client] upload or download, for logging and auditing.
+type TransferResult struct {
+	Source      string
+	Destination string
+	Bytes       int64
+	Offset      int64
+	Duration    time.Duration
+	Algorithm   ChecksumAlgorithm
+	Digest      string
+	Verified    bool
+}
+
+// Resumed returns true if the transfer continued from an existing partial file.
+func (r *TransferResult) Resumed() bool {
+	return r.Offset > 0
+}
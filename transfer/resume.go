@@ -0,0 +1,95 @@
+package transfer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+)
+
+// resumeOffset returns the offset from which a transfer can be resumed, or zero if it must restart from byte zero.
+// The partial destination prefix is compared by hash with the source prefix, or trusted by size: a destination as large
+// as the source is then transferred again, as it may be a stale file rather than a completed transfer.
+// The source and destination are opened for reading from their start.
+func resumeOffset(src func() (io.ReadCloser, error), srcSize int64, dst func() (io.ReadCloser, error), dstSize int64, verify ResumeVerifyMode) int64 {
+	if dstSize <= 0 || dstSize > srcSize {
+		return 0
+	}
+
+	if verify == SizeResumeVerifyMode {
+		if dstSize == srcSize {
+			return 0
+		}
+
+		return dstSize
+	}
+
+	srcDigest, err := prefixDigest(src, dstSize)
+	if err != nil {
+		return 0
+	}
+
+	dstDigest, err := prefixDigest(dst, dstSize)
+	if err != nil || !bytes.Equal(srcDigest, dstDigest) {
+		return 0
+	}
+
+	return dstSize
+}
+
+// prefixDigest returns the SHA-256 digest of the n first bytes of a reader.
+func prefixDigest(open func() (io.ReadCloser, error), n int64) ([]byte, error) {
+	r, err := open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	hash := sha256.New()
+
+	if _, err = io.CopyN(hash, r, n); err != nil {
+		return nil, err
+	}
+
+	return hash.Sum(nil), nil
+}
+
+// hashLocalPrefix writes the n first bytes of a local file into a hash.
+func hashLocalPrefix(h io.Writer, localPath string, n int64) error {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("unable to open local file prefix: %w", err)
+	}
+	defer f.Close()
+
+	if _, err = io.Copy(h, io.NewSectionReader(f, 0, n)); err != nil {
+		return fmt.Errorf("unable to hash local file prefix: %w", err)
+	}
+
+	return nil
+}
+
+func localReader(localPath string) func() (io.ReadCloser, error) {
+	return func() (io.ReadCloser, error) {
+		return os.Open(localPath)
+	}
+}
+
+// sectionReader opens the n first bytes of an already opened file, without closing it.
+func sectionReader(r io.ReaderAt, n int64) func() (io.ReadCloser, error) {
+	return func() (io.ReadCloser, error) {
+		return io.NopCloser(io.NewSectionReader(r, 0, n)), nil
+	}
+}
+
+// seek positions all the provided seekers at an offset.
+func seek(offset int64, seekers ...io.Seeker) error {
+	for _, seeker := range seekers {
+		if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
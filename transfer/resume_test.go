@@ -0,0 +1,133 @@
+package transfer
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func stringReader(s string) func() (io.ReadCloser, error) {
+	return func() (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(s)), nil
+	}
+}
+
+func failingReader() (io.ReadCloser, error) {
+	return nil, errors.New("open failure")
+}
+
+func TestResumeOffset(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		src     func() (io.ReadCloser, error)
+		srcSize int64
+		dst     func() (io.ReadCloser, error)
+		dstSize int64
+		verify  ResumeVerifyMode
+		want    int64
+	}{
+		{
+			name:    "matching prefix",
+			src:     stringReader("hello world"),
+			srcSize: 11,
+			dst:     stringReader("hello"),
+			dstSize: 5,
+			verify:  HashResumeVerifyMode,
+			want:    5,
+		},
+		{
+			name:    "mismatching prefix",
+			src:     stringReader("hello world"),
+			srcSize: 11,
+			dst:     stringReader("HELLO"),
+			dstSize: 5,
+			verify:  HashResumeVerifyMode,
+			want:    0,
+		},
+		{
+			name:    "empty destination",
+			src:     stringReader("hello world"),
+			srcSize: 11,
+			dst:     stringReader(""),
+			dstSize: 0,
+			verify:  HashResumeVerifyMode,
+			want:    0,
+		},
+		{
+			name:    "destination larger than source",
+			src:     stringReader("hello"),
+			srcSize: 5,
+			dst:     stringReader("hello world"),
+			dstSize: 11,
+			verify:  HashResumeVerifyMode,
+			want:    0,
+		},
+		{
+			name:    "complete destination",
+			src:     stringReader("hello world"),
+			srcSize: 11,
+			dst:     stringReader("hello world"),
+			dstSize: 11,
+			verify:  HashResumeVerifyMode,
+			want:    11,
+		},
+		{
+			name:    "unreadable source",
+			src:     failingReader,
+			srcSize: 11,
+			dst:     stringReader("hello"),
+			dstSize: 5,
+			verify:  HashResumeVerifyMode,
+			want:    0,
+		},
+		{
+			name:    "unreadable destination",
+			src:     stringReader("hello world"),
+			srcSize: 11,
+			dst:     failingReader,
+			dstSize: 5,
+			verify:  HashResumeVerifyMode,
+			want:    0,
+		},
+		{
+			name:    "destination shorter than its size",
+			src:     stringReader("hello world"),
+			srcSize: 11,
+			dst:     stringReader("hel"),
+			dstSize: 5,
+			verify:  HashResumeVerifyMode,
+			want:    0,
+		},
+		{
+			name:    "size trusted prefix",
+			src:     failingReader,
+			srcSize: 11,
+			dst:     failingReader,
+			dstSize: 5,
+			verify:  SizeResumeVerifyMode,
+			want:    5,
+		},
+		{
+			name:    "size complete destination",
+			src:     stringReader("hello world"),
+			srcSize: 11,
+			dst:     stringReader("hello world"),
+			dstSize: 11,
+			verify:  SizeResumeVerifyMode,
+			want:    0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := resumeOffset(tt.src, tt.srcSize, tt.dst, tt.dstSize, tt.verify); got != tt.want {
+				t.Errorf("resumeOffset() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,295 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/rand/v2"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/pkg/sftp"
+	"github.com/templatedop/ftptemplate/log"
+)
+
+// RetryOptions are options for the [Retrier].
+type RetryOptions struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	Jitter      float64
+	Retryable   func(err error) bool
+}
+
+// DefaultRetryOptions are the default options used in the [Retrier], a single attempt without retry.
+func DefaultRetryOptions() RetryOptions {
+	return RetryOptions{
+		MaxAttempts: 1,
+		BaseDelay:   time.Second,
+		MaxDelay:    30 * time.Second,
+		Jitter:      0.2,
+		Retryable:   IsRetryable,
+	}
+}
+
+// RetryOption are functional options for the [Retrier].
+type RetryOption func(o *RetryOptions)
+
+// WithMaxAttempts is used to specify the maximum number of attempts of an operation, including the first one.
+func WithMaxAttempts(n int) RetryOption {
+	return func(o *RetryOptions) {
+		if n > 0 {
+			o.MaxAttempts = n
+		}
+	}
+}
+
+// WithBaseDelay is used to specify the delay before the first retry, doubled at each following retry.
+func WithBaseDelay(d time.Duration) RetryOption {
+	return func(o *RetryOptions) {
+		if d > 0 {
+			o.BaseDelay = d
+		}
+	}
+}
+
+// WithMaxDelay is used to specify the maximum delay between two attempts.
+func WithMaxDelay(d time.Duration) RetryOption {
+	return func(o *RetryOptions) {
+		if d > 0 {
+			o.MaxDelay = d
+		}
+	}
+}
+
+// WithJitter is used to specify the random fraction (between 0 and 1) the delays are spread by, to avoid retry storms.
+func WithJitter(jitter float64) RetryOption {
+	return func(o *RetryOptions) {
+		if jitter >= 0 && jitter <= 1 {
+			o.Jitter = jitter
+		}
+	}
+}
+
+// WithRetryable is used to specify the classification of retryable errors, [IsRetryable] by default.
+func WithRetryable(fn func(err error) bool) RetryOption {
+	return func(o *RetryOptions) {
+		if fn != nil {
+			o.Retryable = fn
+		}
+	}
+}
+
+// PermanentError wraps an error that must not be retried, whatever its classification.
+type PermanentError struct {
+	Err error
+}
+
+// Permanent wraps an error in a [PermanentError], or returns nil if the error is nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &PermanentError{Err: err}
+}
+
+// Error returns the error message.
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Retrier retries failing operations with exponential backoff and jitter, while their errors are retryable.
+// Each retry is logged with the contextual logger, with its attempt count.
+type Retrier struct {
+	options RetryOptions
+}
+
+// NewRetrier returns a new [Retrier], and accepts a list of [RetryOption].
+func NewRetrier(options ...RetryOption) *Retrier {
+	appliedOpts := DefaultRetryOptions()
+	for _, opt := range options {
+		opt(&appliedOpts)
+	}
+
+	if appliedOpts.MaxDelay < appliedOpts.BaseDelay {
+		appliedOpts.MaxDelay = appliedOpts.BaseDelay
+	}
+
+	return &Retrier{
+		options: appliedOpts,
+	}
+}
+
+// MaxAttempts returns the maximum number of attempts of an operation.
+func (r *Retrier) MaxAttempts() int {
+	return r.options.MaxAttempts
+}
+
+// Do runs an operation until it succeeds, its error is not retryable, or the max attempts are reached.
+func (r *Retrier) Do(ctx context.Context, operation string, fn func(ctx context.Context) error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			if attempt > 1 {
+				log.CtxLogger(ctx).Info().
+					Str("operation", operation).
+					Int("attempt", attempt).
+					Msgf("%s succeeded after %d attempts", operation, attempt)
+			}
+
+			return nil
+		}
+
+		if err = r.Wait(ctx, operation, attempt, err); err != nil {
+			return err
+		}
+	}
+}
+
+// Wait waits before the next attempt of an operation that failed with an error, and returns nil to retry.
+// It returns the error instead if it is not retryable, if the max attempts are reached, or if the context is done.
+func (r *Retrier) Wait(ctx context.Context, operation string, attempt int, err error) error {
+	var permanentErr *PermanentError
+	if errors.As(err, &permanentErr) {
+		return r.exhausted(operation, attempt, permanentErr.Err)
+	}
+
+	if attempt >= r.options.MaxAttempts || ctx.Err() != nil || !r.options.Retryable(err) {
+		return r.exhausted(operation, attempt, err)
+	}
+
+	delay := r.Delay(attempt)
+
+	log.CtxLogger(ctx).Warn().
+		Err(err).
+		Str("operation", operation).
+		Int("attempt", attempt).
+		Int("max_attempts", r.options.MaxAttempts).
+		Dur("delay", delay).
+		Msgf("%s failed at attempt %d/%d, retrying in %s", operation, attempt, r.options.MaxAttempts, delay)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return r.exhausted(operation, attempt, err)
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Delay returns the delay before the attempt following a failed one: the base delay doubled at each attempt,
+// bounded by the max delay, and spread by the jitter.
+func (r *Retrier) Delay(attempt int) time.Duration {
+	delay := r.options.MaxDelay
+	if attempt < 32 {
+		if d := r.options.BaseDelay << (attempt - 1); d > 0 && d < delay {
+			delay = d
+		}
+	}
+
+	if r.options.Jitter > 0 {
+		delay = time.Duration(float64(delay) * (1 - r.options.Jitter + 2*r.options.Jitter*rand.Float64()))
+	}
+
+	return delay
+}
+
+func (r *Retrier) exhausted(operation string, attempt int, err error) error {
+	if attempt > 1 {
+		return fmt.Errorf("%s failed after %d attempts: %w", operation, attempt, err)
+	}
+
+	return err
+}
+
+// IsRetryable returns true if an error is transient: connection errors, transient FTP replies and checksum mismatches
+// are retryable, while authentication, host key, permission, missing files and stage errors are not.
+func IsRetryable(err error) bool {
+	var permanentErr *PermanentError
+	var hostKeyErr *HostKeyError
+	var checksumErr *ChecksumError
+	var ftpErr *FtpError
+	var stageErr *StageError
+
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return false
+	case errors.As(err, &permanentErr), errors.As(err, &hostKeyErr), errors.As(err, &stageErr):
+		return false
+	case IsAuthError(err):
+		return false
+	case errors.Is(err, fs.ErrPermission), errors.Is(err, fs.ErrNotExist), errors.Is(err, fs.ErrExist):
+		return false
+	case errors.As(err, &checksumErr):
+		return true
+	case errors.As(err, &ftpErr) && ftpErr.Temporary():
+		return true
+	}
+
+	return IsConnectionError(err)
+}
+
+// IsAuthError returns true if an error is an authentication failure: an [AuthError], an FTP not logged in reply (530),
+// or an S3 invalid credentials or denied access response.
+func IsAuthError(err error) bool {
+	var authErr *AuthError
+	var ftpErr *FtpError
+	var s3Err minio.ErrorResponse
+
+	switch {
+	case errors.As(err, &authErr):
+		return true
+	case errors.As(err, &ftpErr):
+		return ftpErr.Code == 530
+	case errors.As(err, &s3Err):
+		return s3Err.Code == "InvalidAccessKeyId" || s3Err.Code == "SignatureDoesNotMatch" || s3Err.Code == "AccessDenied"
+	}
+
+	return false
+}
+
+// IsConnectionError returns true if an error denotes a broken or unreachable connection: network errors,
+// connection resets, unexpected ends of stream and lost sftp connections.
+func IsConnectionError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	for _, target := range []error{
+		io.EOF,
+		io.ErrUnexpectedEOF,
+		io.ErrClosedPipe,
+		net.ErrClosed,
+		sftp.ErrSSHFxConnectionLost,
+		sftp.ErrSSHFxNoConnection,
+		syscall.ECONNRESET,
+		syscall.ECONNREFUSED,
+		syscall.ECONNABORTED,
+		syscall.EPIPE,
+		syscall.ETIMEDOUT,
+		syscall.EHOSTUNREACH,
+		syscall.ENETUNREACH,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,125 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+)
+
+func TestIsRetryable(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "unexpected eof", err: io.ErrUnexpectedEOF, want: true},
+		{name: "wrapped connection reset", err: fmt.Errorf("unable to upload: %w", syscall.ECONNRESET), want: true},
+		{name: "checksum mismatch", err: &ChecksumError{Path: "a.txt", Expected: "00", Actual: "ff"}, want: true},
+		{name: "transient ftp reply", err: &FtpError{Code: 421, Message: "service not available"}, want: true},
+		{name: "permanent ftp reply", err: &FtpError{Code: 553, Message: "file name not allowed"}, want: false},
+		{name: "missing ftp file", err: &FtpError{Code: 550, Message: "no such file"}, want: false},
+		{name: "canceled", err: context.Canceled, want: false},
+		{name: "deadline exceeded", err: fmt.Errorf("unable to dial: %w", context.DeadlineExceeded), want: false},
+		{name: "permanent", err: Permanent(io.ErrUnexpectedEOF), want: false},
+		{name: "host key", err: &HostKeyError{Host: "sftp.example.com", Err: io.EOF}, want: false},
+		{name: "stage", err: &StageError{Stage: "encrypt", Err: io.ErrUnexpectedEOF}, want: false},
+		{name: "authentication", err: &AuthError{User: "partner", Err: errors.New("ssh: unable to authenticate")}, want: false},
+		{name: "ftp not logged in", err: fmt.Errorf("unable to connect: %w", &FtpError{Code: 530, Message: "login incorrect"}), want: false},
+		{name: "ftp login transient reply", err: fmt.Errorf("unable to log in as [partner]: %w", &FtpError{Code: 421, Message: "too many connections"}), want: true},
+		{name: "ftp login reset", err: fmt.Errorf("unable to log in as [partner]: %w", syscall.ECONNRESET), want: true},
+		{name: "s3 invalid credentials", err: minio.ErrorResponse{Code: "InvalidAccessKeyId"}, want: false},
+		{name: "auth-like text", err: fmt.Errorf("unable to authenticate as [partner]: %w", io.EOF), want: true},
+		{name: "permission", err: fs.ErrPermission, want: false},
+		{name: "not exist", err: fmt.Errorf("unable to stat: %w", fs.ErrNotExist), want: false},
+		{name: "unknown", err: errors.New("unknown failure"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable(%v) = %t, want %t", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetrierDelay(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		options []RetryOption
+		attempt int
+		min     time.Duration
+		max     time.Duration
+	}{
+		{
+			name:    "first retry",
+			options: []RetryOption{WithBaseDelay(time.Second), WithJitter(0)},
+			attempt: 1,
+			min:     time.Second,
+			max:     time.Second,
+		},
+		{
+			name:    "doubled delay",
+			options: []RetryOption{WithBaseDelay(time.Second), WithJitter(0)},
+			attempt: 3,
+			min:     4 * time.Second,
+			max:     4 * time.Second,
+		},
+		{
+			name:    "max delay",
+			options: []RetryOption{WithBaseDelay(time.Second), WithMaxDelay(5 * time.Second), WithJitter(0)},
+			attempt: 4,
+			min:     5 * time.Second,
+			max:     5 * time.Second,
+		},
+		{
+			name:    "overflowing shift",
+			options: []RetryOption{WithBaseDelay(time.Second), WithMaxDelay(time.Minute), WithJitter(0)},
+			attempt: 40,
+			min:     time.Minute,
+			max:     time.Minute,
+		},
+		{
+			name:    "max delay below base delay",
+			options: []RetryOption{WithBaseDelay(10 * time.Second), WithMaxDelay(time.Second), WithJitter(0)},
+			attempt: 2,
+			min:     10 * time.Second,
+			max:     10 * time.Second,
+		},
+		{
+			name:    "jitter",
+			options: []RetryOption{WithBaseDelay(time.Second), WithJitter(0.5)},
+			attempt: 2,
+			min:     time.Second,
+			max:     3 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			retrier := NewRetrier(tt.options...)
+
+			for i := 0; i < 100; i++ {
+				if got := retrier.Delay(tt.attempt); got < tt.min || got > tt.max {
+					t.Fatalf("Delay(%d) = %s, want between %s and %s", tt.attempt, got, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
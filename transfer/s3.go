@@ -0,0 +1,381 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+)
+
+const (
+	s3PartSize    = 16 * 1024 * 1024       // multipart upload part size of the S3 objects of unknown size
+	s3MaxCopySize = 5 * 1024 * 1024 * 1024 // maximum size of the S3 objects copied in a single request
+)
+
+var _ Client = (*S3Client)(nil)
+
+// S3Client is the S3 compatible object storage [Client] implementation, for AWS S3, MinIO and the likes.
+// Its paths are the slash separated object keys, resolved in its root key prefix, and directories are key prefixes.
+type S3Client struct {
+	client          *minio.Client
+	core            *minio.Core
+	transport       *http.Transport
+	timeout         time.Duration
+	bucket          string
+	root            string
+	transferOptions []TransferOption
+}
+
+// NewS3Client returns a [S3Client] for a bucket and a root key prefix, implementing [Client].
+// The provided [TransferOption] are applied by default to all the transfers.
+func NewS3Client(client *minio.Client, bucket string, root string, transferOptions ...TransferOption) *S3Client {
+	return &S3Client{
+		client:          client,
+		core:            &minio.Core{Client: client},
+		bucket:          bucket,
+		root:            strings.Trim(path.Clean("/"+root), "/"),
+		transferOptions: transferOptions,
+	}
+}
+
+// Minio returns the underlying [minio.Client].
+func (c *S3Client) Minio() *minio.Client {
+	return c.client
+}
+
+// KeepAlive checks that the bucket is still reachable.
+func (c *S3Client) KeepAlive() error {
+	ctx := context.Background()
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	if err := c.checkBucket(ctx); err != nil {
+		return fmt.Errorf("keepalive failure on [%s]: %w", c.client.EndpointURL().Host, err)
+	}
+
+	return nil
+}
+
+// List returns the objects of a key prefix directory, the nested directories excluded.
+func (c *S3Client) List(ctx context.Context, dir string) ([]fs.FileInfo, error) {
+	prefix := c.prefix(dir)
+
+	var files []fs.FileInfo
+	for object := range c.client.ListObjects(ctx, c.bucket, minio.ListObjectsOptions{Prefix: prefix}) {
+		if object.Err != nil {
+			return nil, fmt.Errorf("unable to list remote dir [%s]: %w", dir, s3Error(object.Err))
+		}
+
+		// nested directories and directory markers
+		if strings.HasSuffix(object.Key, "/") {
+			continue
+		}
+
+		files = append(files, objectInfo(strings.TrimPrefix(object.Key, prefix), object))
+	}
+
+	return files, nil
+}
+
+// Walk returns the objects of a key prefix directory tree, with their keys relative to the directory.
+func (c *S3Client) Walk(ctx context.Context, dir string) ([]WalkEntry, error) {
+	prefix := c.prefix(dir)
+
+	var entries []WalkEntry
+	for object := range c.client.ListObjects(ctx, c.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if object.Err != nil {
+			return nil, fmt.Errorf("unable to walk remote dir [%s]: %w", dir, s3Error(object.Err))
+		}
+
+		if strings.HasSuffix(object.Key, "/") {
+			continue
+		}
+
+		rel := strings.TrimPrefix(object.Key, prefix)
+
+		entries = append(entries, WalkEntry{
+			Path: rel,
+			Info: objectInfo(path.Base(rel), object),
+		})
+	}
+
+	return entries, nil
+}
+
+// Upload copies a local file to an object, and returns a [TransferResult].
+// It supports the same options as [SftpClient.Upload], except resume and atomic uploads:
+// objects cannot be appended to, and are published atomically once completely uploaded.
+func (c *S3Client) Upload(ctx context.Context, localPath string, remotePath string, options ...TransferOption) (*TransferResult, error) {
+	o := c.applyTransferOptions(options...)
+	o.Resume = false
+	o.Atomic = false
+
+	return streamUpload(ctx, c, localPath, remotePath, o)
+}
+
+// Download copies an object to a local path, creating the missing local directories, and returns a [TransferResult].
+// It supports the same options as [SftpClient.Download], partial local files being resumed with ranged reads.
+func (c *S3Client) Download(ctx context.Context, remotePath string, localPath string, options ...TransferOption) (*TransferResult, error) {
+	return streamDownload(ctx, c, remotePath, localPath, c.applyTransferOptions(options...))
+}
+
+// Move copies an object to another key on the server side, then removes the source object.
+func (c *S3Client) Move(ctx context.Context, sourcePath string, destinationPath string) error {
+	if _, err := c.Stat(ctx, sourcePath); err != nil {
+		return err
+	}
+
+	if err := c.replace(ctx, sourcePath, destinationPath); err != nil {
+		return fmt.Errorf("unable to move remote file from [%s] to [%s]: %w", sourcePath, destinationPath, err)
+	}
+
+	return nil
+}
+
+// Remove removes an object or an empty key prefix directory.
+func (c *S3Client) Remove(ctx context.Context, p string) error {
+	info, err := c.Stat(ctx, p)
+	if err != nil {
+		return err
+	}
+
+	key := c.key(p)
+	if info.IsDir() {
+		entries, err := c.List(ctx, p)
+		if err != nil {
+			return err
+		}
+
+		if len(entries) > 0 {
+			return fmt.Errorf("unable to remove remote path [%s]: directory not empty", p)
+		}
+
+		key += "/"
+	}
+
+	if err = c.client.RemoveObject(ctx, c.bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("unable to remove remote path [%s]: %w", p, s3Error(err))
+	}
+
+	return nil
+}
+
+// Stat returns the [fs.FileInfo] of an object, or of a key prefix directory if objects exist under it.
+func (c *S3Client) Stat(ctx context.Context, p string) (fs.FileInfo, error) {
+	info, err := c.stat(ctx, p)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("remote path [%s] does not exist: %w", p, err)
+		}
+
+		return nil, fmt.Errorf("unable to stat remote path [%s]: %w", p, err)
+	}
+
+	return info, nil
+}
+
+func (c *S3Client) stat(ctx context.Context, p string) (fs.FileInfo, error) {
+	key := c.key(p)
+	if key == "" {
+		if err := c.checkBucket(ctx); err != nil {
+			return nil, err
+		}
+
+		return &fileInfo{name: "/", mode: fs.ModeDir | 0o755}, nil
+	}
+
+	object, err := c.client.StatObject(ctx, c.bucket, key, minio.StatObjectOptions{})
+	if err == nil {
+		return objectInfo(path.Base(key), object), nil
+	}
+
+	if err = s3Error(err); !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	// the listing is cancelled once its first object is received
+	listCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for object := range c.client.ListObjects(listCtx, c.bucket, minio.ListObjectsOptions{Prefix: key + "/", MaxKeys: 1}) {
+		if object.Err != nil {
+			return nil, s3Error(object.Err)
+		}
+
+		return &fileInfo{name: path.Base(key), mode: fs.ModeDir | 0o755}, nil
+	}
+
+	return nil, err
+}
+
+// Mkdir does nothing, key prefix directories existing as soon as objects are stored under them.
+func (c *S3Client) Mkdir(ctx context.Context, _ string) error {
+	return ctx.Err()
+}
+
+// Open opens an object for reading.
+func (c *S3Client) Open(ctx context.Context, path string) (io.ReadCloser, error) {
+	f, err := c.read(ctx, path, 0)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open remote file [%s]: %w", path, err)
+	}
+
+	return f, nil
+}
+
+// Chtimes returns an [errors.ErrUnsupported] error, the objects modification time being set by the server.
+func (c *S3Client) Chtimes(_ context.Context, path string, _ time.Time) error {
+	return fmt.Errorf("unable to change remote file [%s] times: %w", path, errors.ErrUnsupported)
+}
+
+func (c *S3Client) chtimesSupported() bool {
+	return false
+}
+
+// Close closes the idle HTTP connections of the client.
+func (c *S3Client) Close() error {
+	if c.transport != nil {
+		c.transport.CloseIdleConnections()
+	}
+
+	return nil
+}
+
+// key returns the object key of a slash separated path, in the root key prefix.
+func (c *S3Client) key(p string) string {
+	return strings.TrimPrefix(path.Join(c.root, path.Clean("/"+p)), "/")
+}
+
+// prefix returns the key prefix of the objects of a directory.
+func (c *S3Client) prefix(dir string) string {
+	if key := c.key(dir); key != "" {
+		return key + "/"
+	}
+
+	return ""
+}
+
+func (c *S3Client) checkBucket(ctx context.Context) error {
+	exists, err := c.client.BucketExists(ctx, c.bucket)
+	if err != nil {
+		return s3Error(err)
+	}
+
+	if !exists {
+		return fmt.Errorf("bucket [%s]: %w", c.bucket, fs.ErrNotExist)
+	}
+
+	return nil
+}
+
+func (c *S3Client) read(ctx context.Context, path string, offset int64) (io.ReadCloser, error) {
+	options := minio.GetObjectOptions{}
+	if offset > 0 {
+		if err := options.SetRange(offset, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	body, _, header, err := c.core.GetObject(ctx, c.bucket, c.key(path), options)
+	if err != nil {
+		return nil, s3Error(err)
+	}
+
+	// some S3 compatible storages ignore the range, and send the whole object
+	if offset > 0 && header.Get("Content-Range") == "" {
+		body.Close()
+
+		return nil, fmt.Errorf("ranged read of remote object [%s]: %w", path, errors.ErrUnsupported)
+	}
+
+	return body, nil
+}
+
+func (c *S3Client) write(ctx context.Context, path string, r io.Reader, size int64, append bool) (int64, error) {
+	if append {
+		return 0, fmt.Errorf("unable to append to remote object: %w", errors.ErrUnsupported)
+	}
+
+	options := minio.PutObjectOptions{
+		ContentType: "application/octet-stream",
+	}
+	if size < 0 {
+		options.PartSize = s3PartSize
+	}
+
+	info, err := c.client.PutObject(ctx, c.bucket, c.key(path), r, size, options)
+	if err != nil {
+		return 0, s3Error(err)
+	}
+
+	return info.Size, nil
+}
+
+// replace copies an object on the server side, in multiple parts above 5 GiB, then removes the source object.
+func (c *S3Client) replace(ctx context.Context, sourcePath string, destinationPath string) error {
+	destination := minio.CopyDestOptions{Bucket: c.bucket, Object: c.key(destinationPath)}
+	source := minio.CopySrcOptions{Bucket: c.bucket, Object: c.key(sourcePath)}
+
+	object, err := c.client.StatObject(ctx, c.bucket, source.Object, minio.StatObjectOptions{})
+	if err != nil {
+		return s3Error(err)
+	}
+
+	if object.Size > s3MaxCopySize {
+		_, err = c.client.ComposeObject(ctx, destination, source)
+	} else {
+		_, err = c.client.CopyObject(ctx, destination, source)
+	}
+
+	if err != nil {
+		return s3Error(err)
+	}
+
+	if err := c.client.RemoveObject(ctx, c.bucket, source.Object, minio.RemoveObjectOptions{}); err != nil {
+		return s3Error(err)
+	}
+
+	return nil
+}
+
+func (c *S3Client) applyTransferOptions(options ...TransferOption) TransferOptions {
+	appliedOpts := DefaultTransferOptions()
+	for _, applyOpt := range slices.Concat(c.transferOptions, options) {
+		applyOpt(&appliedOpts)
+	}
+
+	return appliedOpts
+}
+
+func objectInfo(name string, object minio.ObjectInfo) *fileInfo {
+	return &fileInfo{
+		name:    name,
+		size:    object.Size,
+		mode:    0o644,
+		modTime: object.LastModified,
+	}
+}
+
+// s3Error maps the S3 error responses to the [fs] errors: missing keys and buckets to [fs.ErrNotExist],
+// and denied accesses to [fs.ErrPermission]. Invalid credentials are detected by [IsAuthError].
+func s3Error(err error) error {
+	switch minio.ToErrorResponse(err).Code {
+	case "NoSuchKey", "NoSuchBucket":
+		return fmt.Errorf("%w: %w", fs.ErrNotExist, err)
+	case "AccessDenied":
+		return fmt.Errorf("%w: %w", fs.ErrPermission, err)
+	}
+
+	return err
+}
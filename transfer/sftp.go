@@ -0,0 +1,659 @@
+package transfer
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+var _ Client = (*SftpClient)(nil)
+
+// SftpClient is the SFTP [Client] implementation.
+type SftpClient struct {
+	client          *sftp.Client
+	conn            *ssh.Client
+	agent           io.Closer
+	shared          bool
+	timeout         time.Duration
+	sftpOptions     []sftp.ClientOption
+	transferOptions []TransferOption
+}
+
+// NewSftpClient returns a [SftpClient], implementing [Client].
+// The provided [TransferOption] are applied by default to all the transfers.
+func NewSftpClient(client *sftp.Client, conn *ssh.Client, transferOptions ...TransferOption) *SftpClient {
+	return &SftpClient{
+		client:          client,
+		conn:            conn,
+		transferOptions: transferOptions,
+	}
+}
+
+// NewSession returns a new [SftpClient] opening another SFTP session over the same SSH connection.
+// Closing the returned session does not close the shared connection, which is closed with the original client.
+func (c *SftpClient) NewSession() (Client, error) {
+	sc, err := sftp.NewClient(c.conn, c.sftpOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to start sftp session on [%s]: %w", c.conn.RemoteAddr(), err)
+	}
+
+	return &SftpClient{
+		client:          sc,
+		conn:            c.conn,
+		shared:          true,
+		timeout:         c.timeout,
+		sftpOptions:     c.sftpOptions,
+		transferOptions: c.transferOptions,
+	}, nil
+}
+
+// KeepAlive sends a keepalive request over the SSH connection, and waits for its reply up to the connection timeout.
+// The SSH connection is closed if the reply does not come in time, so that the pending request and the sessions fail.
+func (c *SftpClient) KeepAlive() error {
+	errs := make(chan error, 1)
+
+	go func() {
+		_, _, err := c.conn.SendRequest("keepalive@openssh.com", true, nil)
+		errs <- err
+	}()
+
+	var timeout <-chan time.Time
+	if c.timeout > 0 {
+		timer := time.NewTimer(c.timeout)
+		defer timer.Stop()
+
+		timeout = timer.C
+	}
+
+	select {
+	case err := <-errs:
+		if err != nil {
+			return fmt.Errorf("keepalive failure on [%s]: %w", c.conn.RemoteAddr(), err)
+		}
+
+		return nil
+	case <-timeout:
+		c.conn.Close()
+
+		return fmt.Errorf("keepalive failure on [%s]: no reply after %s", c.conn.RemoteAddr(), c.timeout)
+	}
+}
+
+// Sftp returns the underlying [sftp.Client].
+func (c *SftpClient) Sftp() *sftp.Client {
+	return c.client
+}
+
+// List returns the files (directories excluded) of a remote directory.
+func (c *SftpClient) List(ctx context.Context, dir string) ([]fs.FileInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	entries, err := c.client.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list remote dir [%s]: %w", dir, err)
+	}
+
+	var files []fs.FileInfo
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			files = append(files, entry)
+		}
+	}
+
+	return files, nil
+}
+
+// Walk returns the files (directories excluded) of a remote directory tree, with their paths relative to the directory.
+func (c *SftpClient) Walk(ctx context.Context, dir string) ([]WalkEntry, error) {
+	root := path.Clean(dir)
+
+	var entries []WalkEntry
+	walker := c.client.Walk(root)
+	for walker.Step() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if err := walker.Err(); err != nil {
+			return nil, fmt.Errorf("unable to walk remote dir [%s]: %w", walker.Path(), err)
+		}
+
+		if walker.Stat().IsDir() {
+			continue
+		}
+
+		entries = append(entries, WalkEntry{
+			Path: relativePath(root, walker.Path()),
+			Info: walker.Stat(),
+		})
+	}
+
+	return entries, nil
+}
+
+// Upload copies a local file to a remote path, creating the missing remote directories, and returns a [TransferResult].
+// With the resume option, an existing partial remote file is continued from its size once its prefix is verified.
+// With the atomic option, the file is written to a temporary name first, then renamed to the remote path once verified.
+// With the checksum options, the digest is computed while streaming, optionally verified by re-reading the remote file,
+// and optionally published in a sidecar file.
+// With upload stages, the local file is processed into a staged file first, which is uploaded instead.
+func (c *SftpClient) Upload(ctx context.Context, localPath string, remotePath string, options ...TransferOption) (*TransferResult, error) {
+	appliedOpts := c.applyTransferOptions(options...)
+	if len(appliedOpts.UploadStages) > 0 {
+		return stageUpload(ctx, localPath, remotePath, appliedOpts, c.uploadPath)
+	}
+
+	return c.uploadPath(ctx, localPath, remotePath, appliedOpts)
+}
+
+func (c *SftpClient) uploadPath(ctx context.Context, localPath string, remotePath string, appliedOpts TransferOptions) (*TransferResult, error) {
+	start := time.Now()
+
+	srcFile, err := os.Open(localPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open local file [%s]: %w", localPath, err)
+	}
+	defer srcFile.Close()
+
+	srcInfo, err := srcFile.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("unable to stat local file [%s]: %w", localPath, err)
+	}
+
+	if err = c.client.MkdirAll(path.Dir(remotePath)); err != nil {
+		return nil, fmt.Errorf("unable to create remote directory [%s]: %w", path.Dir(remotePath), err)
+	}
+
+	targetPath := remotePath
+	if appliedOpts.Atomic {
+		targetPath = TemporaryPath(remotePath, appliedOpts.TemporaryPattern)
+
+		if err = c.client.MkdirAll(path.Dir(targetPath)); err != nil {
+			return nil, fmt.Errorf("unable to create remote temporary directory [%s]: %w", path.Dir(targetPath), err)
+		}
+	}
+
+	result, err := c.upload(ctx, srcFile, srcInfo.Size(), targetPath, appliedOpts)
+	if err != nil {
+		// the partial file is kept only if it can be resumed
+		if !appliedOpts.Resume {
+			_ = c.client.Remove(targetPath)
+		}
+
+		return nil, fmt.Errorf("unable to upload local file [%s] to [%s]: %w", localPath, targetPath, err)
+	}
+
+	result.Source = localPath
+	result.Destination = remotePath
+
+	if appliedOpts.Verify == RereadChecksumVerifyMode && result.Digest != "" {
+		if err = c.verifyRemote(ctx, targetPath, result); err != nil {
+			// the corrupted file is removed, not to be resumed by the retries
+			_ = c.client.Remove(targetPath)
+
+			return nil, err
+		}
+	}
+
+	if appliedOpts.Atomic {
+		if err = c.publish(targetPath, remotePath, srcInfo.Size()); err != nil {
+			return nil, err
+		}
+	}
+
+	if appliedOpts.Sidecar && result.Digest != "" {
+		if err = c.writeSidecar(remotePath, result, appliedOpts); err != nil {
+			return nil, err
+		}
+	}
+
+	result.Duration = time.Since(start)
+
+	return result, nil
+}
+
+func (c *SftpClient) upload(ctx context.Context, srcFile *os.File, size int64, remotePath string, o TransferOptions) (*TransferResult, error) {
+	result := &TransferResult{
+		Algorithm: o.Checksum,
+	}
+
+	if o.Resume {
+		if dstInfo, err := c.client.Stat(remotePath); err == nil {
+			result.Offset = resumeOffset(sectionReader(srcFile, size), size, c.remoteReader(remotePath), dstInfo.Size(), o.ResumeVerify)
+		}
+	}
+
+	// the digest covers the whole file: the already transferred prefix is hashed from the source
+	hash := NewHash(o.Checksum)
+	if hash != nil && result.Offset > 0 {
+		if _, err := io.Copy(hash, io.NewSectionReader(srcFile, 0, result.Offset)); err != nil {
+			return nil, fmt.Errorf("unable to hash local file prefix: %w", err)
+		}
+	}
+
+	if result.Offset == 0 || result.Offset < size {
+		// Note: some servers (e.g. SFTP To Go) do not support O_RDWR mode
+		flags := os.O_WRONLY | os.O_CREATE
+		if result.Offset == 0 {
+			flags |= os.O_TRUNC
+		}
+
+		dstFile, err := c.client.OpenFile(remotePath, flags)
+		if err != nil {
+			return nil, fmt.Errorf("unable to open remote file: %w", err)
+		}
+		defer dstFile.Close()
+
+		if err = seek(result.Offset, srcFile, dstFile); err != nil {
+			return nil, fmt.Errorf("unable to resume at offset %d: %w", result.Offset, err)
+		}
+
+		var src io.Reader = NewContextReader(ctx, srcFile)
+		if hash != nil {
+			src = io.TeeReader(src, hash)
+		}
+
+		if o.Resume && o.ResumeVerify == SizeResumeVerifyMode {
+			// sequential writes: concurrent ones may leave holes before the size of a failed partial file, resumed from its size
+			result.Bytes, err = io.Copy(struct{ io.Writer }{dstFile}, &io.LimitedReader{R: src, N: size - result.Offset})
+		} else {
+			// the limited reader exposes the remaining size, allowing concurrent writes
+			result.Bytes, err = dstFile.ReadFrom(&io.LimitedReader{R: src, N: size - result.Offset})
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if err = dstFile.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	if hash != nil {
+		result.Digest = hex.EncodeToString(hash.Sum(nil))
+	}
+
+	return result, nil
+}
+
+// publish verifies a temporary remote file size, and renames it to its final remote path.
+// The posix-rename@openssh.com extension is used when supported, to atomically replace an existing file.
+func (c *SftpClient) publish(temporaryPath string, remotePath string, size int64) error {
+	info, err := c.client.Stat(temporaryPath)
+	if err != nil {
+		return fmt.Errorf("unable to stat remote temporary file [%s]: %w", temporaryPath, err)
+	}
+
+	if info.Size() != size {
+		return fmt.Errorf("remote temporary file [%s] size %d does not match expected size %d", temporaryPath, info.Size(), size)
+	}
+
+	return c.rename(temporaryPath, remotePath)
+}
+
+func (c *SftpClient) rename(sourcePath string, destinationPath string) error {
+	var err error
+	if _, ok := c.client.HasExtension("posix-rename@openssh.com"); ok {
+		err = c.client.PosixRename(sourcePath, destinationPath)
+	} else {
+		// without the extension, most servers refuse to rename over an existing file
+		if _, statErr := c.client.Stat(destinationPath); statErr == nil {
+			if err = c.client.Remove(destinationPath); err != nil {
+				return fmt.Errorf("unable to replace remote file [%s]: %w", destinationPath, err)
+			}
+		}
+
+		err = c.client.Rename(sourcePath, destinationPath)
+	}
+
+	if err != nil {
+		return fmt.Errorf("unable to rename remote file [%s] to [%s]: %w", sourcePath, destinationPath, err)
+	}
+
+	return nil
+}
+
+// verifyRemote re-reads a remote file, and compares its digest with the transfer result one.
+func (c *SftpClient) verifyRemote(ctx context.Context, remotePath string, result *TransferResult) error {
+	f, err := c.client.Open(remotePath)
+	if err != nil {
+		return fmt.Errorf("unable to open remote file [%s] for verification: %w", remotePath, err)
+	}
+	defer f.Close()
+
+	h := NewHash(result.Algorithm)
+	if _, err = f.WriteTo(NewContextWriter(ctx, h)); err != nil {
+		return fmt.Errorf("unable to read remote file [%s] for verification: %w", remotePath, err)
+	}
+
+	if actual := hex.EncodeToString(h.Sum(nil)); actual != result.Digest {
+		return &ChecksumError{
+			Path:      remotePath,
+			Algorithm: result.Algorithm,
+			Expected:  result.Digest,
+			Actual:    actual,
+		}
+	}
+
+	result.Verified = true
+
+	return nil
+}
+
+// writeSidecar publishes the checksum sidecar file of a remote file.
+func (c *SftpClient) writeSidecar(remotePath string, result *TransferResult, o TransferOptions) error {
+	sidecarPath := SidecarPath(remotePath, result.Algorithm)
+
+	targetPath := sidecarPath
+	if o.Atomic {
+		targetPath = TemporaryPath(sidecarPath, o.TemporaryPattern)
+	}
+
+	f, err := c.client.OpenFile(targetPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return fmt.Errorf("unable to open remote checksum sidecar [%s]: %w", targetPath, err)
+	}
+
+	_, err = f.Write(SidecarContent(result.Digest, path.Base(remotePath)))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("unable to write remote checksum sidecar [%s]: %w", targetPath, err)
+	}
+
+	if o.Atomic {
+		return c.rename(targetPath, sidecarPath)
+	}
+
+	return nil
+}
+
+// Download copies a remote file to a local path, creating the missing local directories, and returns a [TransferResult].
+// With the resume option, an existing partial local file is continued from its size once its prefix is verified.
+// With the checksum options, the digest is computed while streaming, optionally verified by re-reading the remote file,
+// and optionally compared with the remote sidecar file one.
+// With download stages, the remote file is downloaded into a staged file first, then processed into the local path.
+func (c *SftpClient) Download(ctx context.Context, remotePath string, localPath string, options ...TransferOption) (*TransferResult, error) {
+	appliedOpts := c.applyTransferOptions(options...)
+	if len(appliedOpts.DownloadStages) > 0 {
+		return stageDownload(ctx, remotePath, localPath, appliedOpts, c.downloadPath)
+	}
+
+	return c.downloadPath(ctx, remotePath, localPath, appliedOpts)
+}
+
+func (c *SftpClient) downloadPath(ctx context.Context, remotePath string, localPath string, appliedOpts TransferOptions) (*TransferResult, error) {
+	start := time.Now()
+
+	if err := os.MkdirAll(filepath.Dir(localPath), 0o755); err != nil {
+		return nil, fmt.Errorf("unable to create local directory [%s]: %w", filepath.Dir(localPath), err)
+	}
+
+	result, err := c.download(ctx, remotePath, localPath, appliedOpts)
+	if err != nil {
+		return nil, fmt.Errorf("unable to download remote file [%s] to [%s]: %w", remotePath, localPath, err)
+	}
+
+	result.Source = remotePath
+	result.Destination = localPath
+
+	if appliedOpts.Verify == RereadChecksumVerifyMode && result.Digest != "" {
+		if err = c.verifyRemote(ctx, remotePath, result); err != nil {
+			// the corrupted file is removed, not to be resumed by the retries
+			_ = os.Remove(localPath)
+
+			return nil, err
+		}
+	}
+
+	if appliedOpts.Sidecar && result.Digest != "" {
+		if err = c.verifySidecar(remotePath, result); err != nil {
+			_ = os.Remove(localPath)
+
+			return nil, err
+		}
+	}
+
+	result.Duration = time.Since(start)
+
+	return result, nil
+}
+
+func (c *SftpClient) download(ctx context.Context, remotePath string, localPath string, o TransferOptions) (*TransferResult, error) {
+	result := &TransferResult{
+		Algorithm: o.Checksum,
+	}
+
+	srcFile, err := c.client.OpenFile(remotePath, os.O_RDONLY)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open remote file: %w", err)
+	}
+	defer srcFile.Close()
+
+	srcInfo, err := srcFile.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("unable to stat remote file: %w", err)
+	}
+
+	if o.Resume {
+		if dstInfo, err := os.Stat(localPath); err == nil {
+			result.Offset = resumeOffset(sectionReader(srcFile, srcInfo.Size()), srcInfo.Size(), localReader(localPath), dstInfo.Size(), o.ResumeVerify)
+		}
+	}
+
+	// the digest covers the whole file: the already transferred prefix is hashed from the local partial file
+	hash := NewHash(o.Checksum)
+	if hash != nil && result.Offset > 0 {
+		if err = hashLocalPrefix(hash, localPath, result.Offset); err != nil {
+			return nil, err
+		}
+	}
+
+	if result.Offset == 0 || result.Offset < srcInfo.Size() {
+		flags := os.O_WRONLY | os.O_CREATE
+		if result.Offset == 0 {
+			flags |= os.O_TRUNC
+		}
+
+		dstFile, err := os.OpenFile(localPath, flags, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("unable to open local file: %w", err)
+		}
+		defer dstFile.Close()
+
+		if err = seek(result.Offset, srcFile, dstFile); err != nil {
+			return nil, fmt.Errorf("unable to resume at offset %d: %w", result.Offset, err)
+		}
+
+		var dst io.Writer = dstFile
+		if hash != nil {
+			dst = io.MultiWriter(dstFile, hash)
+		}
+
+		if result.Bytes, err = srcFile.WriteTo(NewContextWriter(ctx, dst)); err != nil {
+			return nil, err
+		}
+
+		if err = dstFile.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	if hash != nil {
+		result.Digest = hex.EncodeToString(hash.Sum(nil))
+	}
+
+	return result, nil
+}
+
+// verifySidecar compares a transfer result digest with the one of the remote file checksum sidecar.
+func (c *SftpClient) verifySidecar(remotePath string, result *TransferResult) error {
+	sidecarPath := SidecarPath(remotePath, result.Algorithm)
+
+	f, err := c.client.Open(sidecarPath)
+	if err != nil {
+		return fmt.Errorf("unable to open remote checksum sidecar [%s]: %w", sidecarPath, err)
+	}
+	defer f.Close()
+
+	content, err := io.ReadAll(io.LimitReader(f, 4096))
+	if err != nil {
+		return fmt.Errorf("unable to read remote checksum sidecar [%s]: %w", sidecarPath, err)
+	}
+
+	expected, err := ParseSidecarContent(content)
+	if err != nil {
+		return fmt.Errorf("invalid remote checksum sidecar [%s]: %w", sidecarPath, err)
+	}
+
+	if expected != result.Digest {
+		return &ChecksumError{
+			Path:      remotePath,
+			Algorithm: result.Algorithm,
+			Expected:  expected,
+			Actual:    result.Digest,
+		}
+	}
+
+	result.Verified = true
+
+	return nil
+}
+
+// Move renames a remote file, the destination folder must exist.
+func (c *SftpClient) Move(ctx context.Context, sourcePath string, destinationPath string) error {
+	if _, err := c.Stat(ctx, sourcePath); err != nil {
+		return err
+	}
+
+	if _, err := c.Stat(ctx, path.Dir(destinationPath)); err != nil {
+		return err
+	}
+
+	if err := c.client.Rename(sourcePath, destinationPath); err != nil {
+		return fmt.Errorf("unable to move remote file from [%s] to [%s]: %w", sourcePath, destinationPath, err)
+	}
+
+	return nil
+}
+
+// Remove removes a remote file or empty directory.
+func (c *SftpClient) Remove(ctx context.Context, path string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := c.client.Remove(path); err != nil {
+		return fmt.Errorf("unable to remove remote path [%s]: %w", path, err)
+	}
+
+	return nil
+}
+
+// Stat returns the [fs.FileInfo] of a remote path.
+func (c *SftpClient) Stat(ctx context.Context, path string) (fs.FileInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	info, err := c.client.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("remote path [%s] does not exist: %w", path, err)
+		}
+
+		return nil, fmt.Errorf("unable to stat remote path [%s]: %w", path, err)
+	}
+
+	return info, nil
+}
+
+// Mkdir creates a remote directory, and all its missing parents.
+func (c *SftpClient) Mkdir(ctx context.Context, path string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := c.client.MkdirAll(path); err != nil {
+		return fmt.Errorf("unable to create remote directory [%s]: %w", path, err)
+	}
+
+	return nil
+}
+
+// Open opens a remote file for reading.
+func (c *SftpClient) Open(ctx context.Context, path string) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f, err := c.client.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open remote file [%s]: %w", path, err)
+	}
+
+	return f, nil
+}
+
+// Chtimes changes the modification time of a remote file.
+func (c *SftpClient) Chtimes(ctx context.Context, path string, modTime time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := c.client.Chtimes(path, modTime, modTime); err != nil {
+		return fmt.Errorf("unable to change remote file [%s] times: %w", path, err)
+	}
+
+	return nil
+}
+
+// Close closes the SFTP session and, unless shared with the session it was created from, its underlying SSH connection.
+func (c *SftpClient) Close() error {
+	err := c.client.Close()
+
+	if c.shared {
+		return err
+	}
+
+	if connErr := c.conn.Close(); err == nil {
+		err = connErr
+	}
+
+	if c.agent != nil {
+		if agentErr := c.agent.Close(); err == nil {
+			err = agentErr
+		}
+	}
+
+	return err
+}
+
+func (c *SftpClient) applyTransferOptions(options ...TransferOption) TransferOptions {
+	appliedOpts := DefaultTransferOptions()
+	for _, applyOpt := range slices.Concat(c.transferOptions, options) {
+		applyOpt(&appliedOpts)
+	}
+
+	return appliedOpts
+}
+
+func (c *SftpClient) remoteReader(remotePath string) func() (io.ReadCloser, error) {
+	return func() (io.ReadCloser, error) {
+		return c.client.Open(remotePath)
+	}
+}
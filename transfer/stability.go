@@ -0,0 +1,61 @@
+package transfer
+
+import (
+	"sync"
+	"time"
+)
+
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// StabilityTracker selects the files whose size and modification time did not change between two polls of a directory,
+// to never pick up files still being written by a producer.
+type StabilityTracker struct {
+	mutex sync.Mutex
+	polls map[string]map[string]fileState
+}
+
+// NewStabilityTracker returns a new [StabilityTracker].
+func NewStabilityTracker() *StabilityTracker {
+	return &StabilityTracker{
+		polls: map[string]map[string]fileState{},
+	}
+}
+
+// Stable records a poll of a directory, and returns the entries unchanged since its previous poll.
+// Entries seen for the first time are never stable: they are returned by the next poll if unchanged.
+func (t *StabilityTracker) Stable(dir string, entries []WalkEntry) []WalkEntry {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	previous := t.polls[dir]
+	current := make(map[string]fileState, len(entries))
+
+	var stable []WalkEntry
+	for _, entry := range entries {
+		state := fileState{
+			size:    entry.Info.Size(),
+			modTime: entry.Info.ModTime(),
+		}
+
+		if prev, ok := previous[entry.Path]; ok && prev.size == state.size && prev.modTime.Equal(state.modTime) {
+			stable = append(stable, entry)
+		}
+
+		current[entry.Path] = state
+	}
+
+	t.polls[dir] = current
+
+	return stable
+}
+
+// Forget removes the recorded polls of a directory.
+func (t *StabilityTracker) Forget(dir string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	delete(t.polls, dir)
+}
@@ -0,0 +1,124 @@
+package transfer
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStabilityTrackerStable(t *testing.T) {
+	t.Parallel()
+
+	modTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	entry := func(path string, size int64, modTime time.Time) WalkEntry {
+		return WalkEntry{
+			Path: path,
+			Info: &fileInfo{name: path, size: size, modTime: modTime},
+		}
+	}
+
+	tests := []struct {
+		name  string
+		polls [][]WalkEntry
+		want  []string
+	}{
+		{
+			name:  "first poll",
+			polls: [][]WalkEntry{{entry("a.txt", 10, modTime)}},
+			want:  nil,
+		},
+		{
+			name: "unchanged",
+			polls: [][]WalkEntry{
+				{entry("a.txt", 10, modTime)},
+				{entry("a.txt", 10, modTime)},
+			},
+			want: []string{"a.txt"},
+		},
+		{
+			name: "growing",
+			polls: [][]WalkEntry{
+				{entry("a.txt", 10, modTime)},
+				{entry("a.txt", 20, modTime)},
+			},
+			want: nil,
+		},
+		{
+			name: "touched",
+			polls: [][]WalkEntry{
+				{entry("a.txt", 10, modTime)},
+				{entry("a.txt", 10, modTime.Add(time.Second))},
+			},
+			want: nil,
+		},
+		{
+			name: "stable after change",
+			polls: [][]WalkEntry{
+				{entry("a.txt", 10, modTime)},
+				{entry("a.txt", 20, modTime)},
+				{entry("a.txt", 20, modTime)},
+			},
+			want: []string{"a.txt"},
+		},
+		{
+			name: "new file",
+			polls: [][]WalkEntry{
+				{entry("a.txt", 10, modTime)},
+				{entry("a.txt", 10, modTime), entry("b.txt", 10, modTime)},
+			},
+			want: []string{"a.txt"},
+		},
+		{
+			name: "reappearing file",
+			polls: [][]WalkEntry{
+				{entry("a.txt", 10, modTime)},
+				{},
+				{entry("a.txt", 10, modTime)},
+			},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tracker := NewStabilityTracker()
+
+			var stable []WalkEntry
+			for _, poll := range tt.polls {
+				stable = tracker.Stable("in", poll)
+			}
+
+			var got []string
+			for _, e := range stable {
+				got = append(got, e.Path)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Stable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStabilityTrackerDirectories(t *testing.T) {
+	t.Parallel()
+
+	modTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	entries := []WalkEntry{{Path: "a.txt", Info: &fileInfo{name: "a.txt", size: 10, modTime: modTime}}}
+
+	tracker := NewStabilityTracker()
+	tracker.Stable("in", entries)
+
+	if got := tracker.Stable("other", entries); len(got) != 0 {
+		t.Errorf("Stable() on another directory = %v, want none", got)
+	}
+
+	tracker.Forget("in")
+
+	if got := tracker.Stable("in", entries); len(got) != 0 {
+		t.Errorf("Stable() after Forget() = %v, want none", got)
+	}
+}
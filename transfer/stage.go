@@ -0,0 +1,190 @@
+package transfer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// Stage is a streaming transformation of the transferred files content, like an encryption or a compression,
+// applied to the local file before uploads, or to the downloaded file after downloads.
+type Stage interface {
+	// Name returns the stage name, reported in the [StageError].
+	Name() string
+	// Process streams the transformed content of a reader to a writer.
+	Process(ctx context.Context, w io.Writer, r io.Reader) error
+}
+
+// StageError is returned when a [Stage] fails to process a file, like on a decryption failure or an invalid signature.
+// The processed content is rejected, and the transfer is not retried.
+type StageError struct {
+	Stage string
+	Err   error
+}
+
+// Error returns the error message.
+func (e *StageError) Error() string {
+	return fmt.Sprintf("stage %s failed: %v", e.Stage, e.Err)
+}
+
+// Unwrap returns the wrapped error.
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+// ProcessStages streams the content of a reader through a list of [Stage] to a writer, each stage running
+// concurrently on the output of the previous one, so that files are never buffered in memory.
+func ProcessStages(ctx context.Context, stages []Stage, w io.Writer, r io.Reader) error {
+	if len(stages) == 0 {
+		_, err := io.Copy(w, NewContextReader(ctx, r))
+
+		return err
+	}
+
+	if len(stages) == 1 {
+		return processStage(ctx, stages[0], w, r)
+	}
+
+	pr, pw := io.Pipe()
+
+	errc := make(chan error, 1)
+	go func() {
+		err := processStage(ctx, stages[0], pw, r)
+		pw.CloseWithError(err)
+		errc <- err
+	}()
+
+	err := ProcessStages(ctx, stages[1:], w, pr)
+	pr.Close()
+
+	// the first failing stage is reported, the ones around it fail on their closed pipe
+	if firstErr := <-errc; firstErr != nil && !errors.Is(firstErr, io.ErrClosedPipe) {
+		return firstErr
+	}
+
+	return err
+}
+
+func processStage(ctx context.Context, stage Stage, w io.Writer, r io.Reader) error {
+	if err := stage.Process(ctx, w, NewContextReader(ctx, r)); err != nil {
+		var stageErr *StageError
+		if errors.As(err, &stageErr) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return err
+		}
+
+		return &StageError{Stage: stage.Name(), Err: err}
+	}
+
+	return nil
+}
+
+// ProcessFile streams a local file through a list of [Stage] to another local file, written to a temporary name
+// then renamed once all the stages succeeded, so that rejected contents are never left at the destination path.
+func ProcessFile(ctx context.Context, stages []Stage, sourcePath string, destinationPath string) error {
+	srcFile, err := os.Open(sourcePath)
+	if err != nil {
+		return fmt.Errorf("unable to open local file [%s]: %w", sourcePath, err)
+	}
+	defer srcFile.Close()
+
+	if err = os.MkdirAll(filepath.Dir(destinationPath), 0o755); err != nil {
+		return fmt.Errorf("unable to create local directory [%s]: %w", filepath.Dir(destinationPath), err)
+	}
+
+	dstFile, err := os.CreateTemp(filepath.Dir(destinationPath), fmt.Sprintf(".%s.*.part", filepath.Base(destinationPath)))
+	if err != nil {
+		return fmt.Errorf("unable to create local file [%s]: %w", destinationPath, err)
+	}
+
+	err = ProcessStages(ctx, stages, dstFile, srcFile)
+	if closeErr := dstFile.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(dstFile.Name(), destinationPath)
+	}
+
+	if err != nil {
+		os.Remove(dstFile.Name())
+
+		return fmt.Errorf("unable to process local file [%s] to [%s]: %w", sourcePath, destinationPath, err)
+	}
+
+	return nil
+}
+
+// transferFunc is a [Client] upload or download, on already applied [TransferOptions].
+type transferFunc func(ctx context.Context, sourcePath string, destinationPath string, o TransferOptions) (*TransferResult, error)
+
+// stageUpload processes a local file through the upload stages into a staged file, then uploads the staged file,
+// so that the resume, atomic, checksum and sidecar options apply to the uploaded content.
+// Resume is disabled, since staged contents may differ across attempts, like with encryption session keys.
+func stageUpload(ctx context.Context, localPath string, remotePath string, o TransferOptions, upload transferFunc) (*TransferResult, error) {
+	stages := o.UploadStages
+	stagedPath := stagingPath(o.StagingDir, localPath, remotePath, path.Base(remotePath))
+	defer os.Remove(stagedPath)
+
+	if err := ProcessFile(ctx, stages, localPath, stagedPath); err != nil {
+		return nil, fmt.Errorf("unable to upload local file [%s] to [%s]: %w", localPath, remotePath, err)
+	}
+
+	o.UploadStages = nil
+	o.Resume = false
+
+	result, err := upload(ctx, stagedPath, remotePath, o)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Source = localPath
+
+	return result, nil
+}
+
+// stageDownload downloads a remote file into a staged file, then processes it through the download stages into
+// the local path. With resume, the staged file of a failed download is kept, and resumed by the next attempt.
+func stageDownload(ctx context.Context, remotePath string, localPath string, o TransferOptions, download transferFunc) (*TransferResult, error) {
+	stages := o.DownloadStages
+	stagedPath := stagingPath(o.StagingDir, remotePath, localPath, path.Base(remotePath))
+
+	o.DownloadStages = nil
+
+	result, err := download(ctx, remotePath, stagedPath, o)
+	if err != nil {
+		if !o.Resume {
+			os.Remove(stagedPath)
+		}
+
+		return nil, err
+	}
+
+	// once downloaded, the staged file is processed from scratch: rejected contents are downloaded again
+	defer os.Remove(stagedPath)
+
+	if err = ProcessFile(ctx, stages, stagedPath, localPath); err != nil {
+		return nil, fmt.Errorf("unable to download remote file [%s] to [%s]: %w", remotePath, localPath, err)
+	}
+
+	result.Destination = localPath
+
+	return result, nil
+}
+
+// stagingPath returns the path of a file staged in a local directory, the system temporary directory by default.
+// The path is stable across attempts for the same source and destination, for the staged transfers to be resumed.
+func stagingPath(dir string, sourcePath string, destinationPath string, name string) string {
+	if dir == "" {
+		dir = os.TempDir()
+	}
+
+	key := sha256.Sum256([]byte(sourcePath + "\x00" + destinationPath))
+
+	return filepath.Join(dir, fmt.Sprintf("%s-%s", hex.EncodeToString(key[:8]), name))
+}
@@ -0,0 +1,370 @@
+package transfer
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// streamStore is the set of remote operations the streamed transfers rely on, for the [Client] implementations
+// without random access to the remote files.
+type streamStore interface {
+	Stat(ctx context.Context, path string) (fs.FileInfo, error)
+	Mkdir(ctx context.Context, path string) error
+	Remove(ctx context.Context, path string) error
+	// read opens a remote file for reading from an offset.
+	read(ctx context.Context, path string, offset int64) (io.ReadCloser, error)
+	// write writes a remote file from a reader of a known size (or -1), appending to the existing file if requested.
+	write(ctx context.Context, path string, r io.Reader, size int64, append bool) (int64, error)
+	// replace renames a remote file, replacing the destination if it exists.
+	replace(ctx context.Context, sourcePath string, destinationPath string) error
+}
+
+// streamUpload copies a local file to a remote path of a [streamStore], with the same semantics as [SftpClient.Upload].
+func streamUpload(ctx context.Context, s streamStore, localPath string, remotePath string, o TransferOptions) (*TransferResult, error) {
+	if len(o.UploadStages) > 0 {
+		return stageUpload(ctx, localPath, remotePath, o, func(ctx context.Context, localPath string, remotePath string, o TransferOptions) (*TransferResult, error) {
+			return streamUpload(ctx, s, localPath, remotePath, o)
+		})
+	}
+
+	start := time.Now()
+
+	srcFile, err := os.Open(localPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open local file [%s]: %w", localPath, err)
+	}
+	defer srcFile.Close()
+
+	srcInfo, err := srcFile.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("unable to stat local file [%s]: %w", localPath, err)
+	}
+
+	if err = s.Mkdir(ctx, path.Dir(remotePath)); err != nil {
+		return nil, err
+	}
+
+	targetPath := remotePath
+	if o.Atomic {
+		targetPath = TemporaryPath(remotePath, o.TemporaryPattern)
+
+		if err = s.Mkdir(ctx, path.Dir(targetPath)); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := streamUploadFile(ctx, s, srcFile, srcInfo.Size(), targetPath, o)
+	if err != nil {
+		return nil, fmt.Errorf("unable to upload local file [%s] to [%s]: %w", localPath, targetPath, err)
+	}
+
+	result.Source = localPath
+	result.Destination = remotePath
+
+	if o.Verify == RereadChecksumVerifyMode && result.Digest != "" {
+		if err = streamVerifyRemote(ctx, s, targetPath, result); err != nil {
+			// the corrupted file is removed, not to be resumed by the retries
+			_ = s.Remove(ctx, targetPath)
+
+			return nil, err
+		}
+	}
+
+	if o.Atomic {
+		info, err := s.Stat(ctx, targetPath)
+		if err != nil {
+			return nil, err
+		}
+
+		if info.Size() != srcInfo.Size() {
+			return nil, fmt.Errorf("remote temporary file [%s] size %d does not match expected size %d", targetPath, info.Size(), srcInfo.Size())
+		}
+
+		if err = s.replace(ctx, targetPath, remotePath); err != nil {
+			return nil, err
+		}
+	}
+
+	if o.Sidecar && result.Digest != "" {
+		if err = streamWriteSidecar(ctx, s, remotePath, result, o); err != nil {
+			return nil, err
+		}
+	}
+
+	result.Duration = time.Since(start)
+
+	return result, nil
+}
+
+func streamUploadFile(ctx context.Context, s streamStore, srcFile *os.File, size int64, remotePath string, o TransferOptions) (*TransferResult, error) {
+	result := &TransferResult{
+		Algorithm: o.Checksum,
+	}
+
+	if o.Resume {
+		if dstInfo, err := s.Stat(ctx, remotePath); err == nil {
+			result.Offset = resumeOffset(sectionReader(srcFile, size), size, func() (io.ReadCloser, error) {
+				return s.read(ctx, remotePath, 0)
+			}, dstInfo.Size(), o.ResumeVerify)
+		}
+	}
+
+	// the digest covers the whole file: the already transferred prefix is hashed from the source
+	hash := NewHash(o.Checksum)
+	if hash != nil && result.Offset > 0 {
+		if _, err := io.Copy(hash, io.NewSectionReader(srcFile, 0, result.Offset)); err != nil {
+			return nil, fmt.Errorf("unable to hash local file prefix: %w", err)
+		}
+	}
+
+	if result.Offset == 0 || result.Offset < size {
+		if err := seek(result.Offset, srcFile); err != nil {
+			return nil, fmt.Errorf("unable to resume at offset %d: %w", result.Offset, err)
+		}
+
+		var src io.Reader = NewContextReader(ctx, srcFile)
+		if hash != nil {
+			src = io.TeeReader(src, hash)
+		}
+
+		var err error
+		result.Bytes, err = s.write(ctx, remotePath, io.LimitReader(src, size-result.Offset), size-result.Offset, result.Offset > 0)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if hash != nil {
+		result.Digest = hex.EncodeToString(hash.Sum(nil))
+	}
+
+	return result, nil
+}
+
+// streamDownload copies a remote file of a [streamStore] to a local path, with the same semantics as [SftpClient.Download].
+// If the remote store cannot read from an offset, the transfer restarts from byte zero.
+func streamDownload(ctx context.Context, s streamStore, remotePath string, localPath string, o TransferOptions) (*TransferResult, error) {
+	if len(o.DownloadStages) > 0 {
+		return stageDownload(ctx, remotePath, localPath, o, func(ctx context.Context, remotePath string, localPath string, o TransferOptions) (*TransferResult, error) {
+			return streamDownload(ctx, s, remotePath, localPath, o)
+		})
+	}
+
+	start := time.Now()
+
+	if err := os.MkdirAll(filepath.Dir(localPath), 0o755); err != nil {
+		return nil, fmt.Errorf("unable to create local directory [%s]: %w", filepath.Dir(localPath), err)
+	}
+
+	result, err := streamDownloadFile(ctx, s, remotePath, localPath, o)
+	if err != nil {
+		return nil, fmt.Errorf("unable to download remote file [%s] to [%s]: %w", remotePath, localPath, err)
+	}
+
+	result.Source = remotePath
+	result.Destination = localPath
+
+	if o.Verify == RereadChecksumVerifyMode && result.Digest != "" {
+		if err = streamVerifyRemote(ctx, s, remotePath, result); err != nil {
+			// the corrupted file is removed, not to be resumed by the retries
+			_ = os.Remove(localPath)
+
+			return nil, err
+		}
+	}
+
+	if o.Sidecar && result.Digest != "" {
+		if err = streamVerifySidecar(ctx, s, remotePath, result); err != nil {
+			_ = os.Remove(localPath)
+
+			return nil, err
+		}
+	}
+
+	result.Duration = time.Since(start)
+
+	return result, nil
+}
+
+func streamDownloadFile(ctx context.Context, s streamStore, remotePath string, localPath string, o TransferOptions) (*TransferResult, error) {
+	result := &TransferResult{
+		Algorithm: o.Checksum,
+	}
+
+	srcInfo, err := s.Stat(ctx, remotePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.Resume {
+		if dstInfo, err := os.Stat(localPath); err == nil {
+			result.Offset = resumeOffset(func() (io.ReadCloser, error) {
+				return s.read(ctx, remotePath, 0)
+			}, srcInfo.Size(), localReader(localPath), dstInfo.Size(), o.ResumeVerify)
+		}
+	}
+
+	if result.Offset > 0 && result.Offset == srcInfo.Size() {
+		hash := NewHash(o.Checksum)
+		if hash != nil {
+			if err = hashLocalPrefix(hash, localPath, result.Offset); err != nil {
+				return nil, err
+			}
+
+			result.Digest = hex.EncodeToString(hash.Sum(nil))
+		}
+
+		return result, nil
+	}
+
+	srcFile, err := s.read(ctx, remotePath, result.Offset)
+	if err != nil && result.Offset > 0 {
+		result.Offset = 0
+		srcFile, err = s.read(ctx, remotePath, 0)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to open remote file: %w", err)
+	}
+	defer srcFile.Close()
+
+	// the digest covers the whole file: the already transferred prefix is hashed from the local partial file
+	hash := NewHash(o.Checksum)
+	if hash != nil && result.Offset > 0 {
+		if err = hashLocalPrefix(hash, localPath, result.Offset); err != nil {
+			return nil, err
+		}
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE
+	if result.Offset == 0 {
+		flags |= os.O_TRUNC
+	}
+
+	dstFile, err := os.OpenFile(localPath, flags, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open local file: %w", err)
+	}
+	defer dstFile.Close()
+
+	if err = seek(result.Offset, dstFile); err != nil {
+		return nil, fmt.Errorf("unable to resume at offset %d: %w", result.Offset, err)
+	}
+
+	var dst io.Writer = dstFile
+	if hash != nil {
+		dst = io.MultiWriter(dstFile, hash)
+	}
+
+	if result.Bytes, err = io.Copy(NewContextWriter(ctx, dst), srcFile); err != nil {
+		return nil, err
+	}
+
+	if err = srcFile.Close(); err != nil {
+		return nil, err
+	}
+
+	if err = dstFile.Close(); err != nil {
+		return nil, err
+	}
+
+	if hash != nil {
+		result.Digest = hex.EncodeToString(hash.Sum(nil))
+	}
+
+	return result, nil
+}
+
+// streamVerifyRemote re-reads a remote file, and compares its digest with the transfer result one.
+func streamVerifyRemote(ctx context.Context, s streamStore, remotePath string, result *TransferResult) error {
+	f, err := s.read(ctx, remotePath, 0)
+	if err != nil {
+		return fmt.Errorf("unable to open remote file [%s] for verification: %w", remotePath, err)
+	}
+	defer f.Close()
+
+	h := NewHash(result.Algorithm)
+	if _, err = io.Copy(NewContextWriter(ctx, h), f); err != nil {
+		return fmt.Errorf("unable to read remote file [%s] for verification: %w", remotePath, err)
+	}
+
+	if err = f.Close(); err != nil {
+		return fmt.Errorf("unable to read remote file [%s] for verification: %w", remotePath, err)
+	}
+
+	if actual := hex.EncodeToString(h.Sum(nil)); actual != result.Digest {
+		return &ChecksumError{
+			Path:      remotePath,
+			Algorithm: result.Algorithm,
+			Expected:  result.Digest,
+			Actual:    actual,
+		}
+	}
+
+	result.Verified = true
+
+	return nil
+}
+
+// streamWriteSidecar publishes the checksum sidecar file of a remote file.
+func streamWriteSidecar(ctx context.Context, s streamStore, remotePath string, result *TransferResult, o TransferOptions) error {
+	sidecarPath := SidecarPath(remotePath, result.Algorithm)
+
+	targetPath := sidecarPath
+	if o.Atomic {
+		targetPath = TemporaryPath(sidecarPath, o.TemporaryPattern)
+	}
+
+	content := SidecarContent(result.Digest, path.Base(remotePath))
+	if _, err := s.write(ctx, targetPath, bytes.NewReader(content), int64(len(content)), false); err != nil {
+		return fmt.Errorf("unable to write remote checksum sidecar [%s]: %w", targetPath, err)
+	}
+
+	if o.Atomic {
+		return s.replace(ctx, targetPath, sidecarPath)
+	}
+
+	return nil
+}
+
+// streamVerifySidecar compares a transfer result digest with the one of the remote file checksum sidecar.
+func streamVerifySidecar(ctx context.Context, s streamStore, remotePath string, result *TransferResult) error {
+	sidecarPath := SidecarPath(remotePath, result.Algorithm)
+
+	f, err := s.read(ctx, sidecarPath, 0)
+	if err != nil {
+		return fmt.Errorf("unable to open remote checksum sidecar [%s]: %w", sidecarPath, err)
+	}
+	defer f.Close()
+
+	content, err := io.ReadAll(io.LimitReader(f, 4096))
+	if err != nil {
+		return fmt.Errorf("unable to read remote checksum sidecar [%s]: %w", sidecarPath, err)
+	}
+
+	expected, err := ParseSidecarContent(content)
+	if err != nil {
+		return fmt.Errorf("invalid remote checksum sidecar [%s]: %w", sidecarPath, err)
+	}
+
+	if expected != result.Digest {
+		return &ChecksumError{
+			Path:      remotePath,
+			Algorithm: result.Algorithm,
+			Expected:  expected,
+			Actual:    result.Digest,
+		}
+	}
+
+	result.Verified = true
+
+	return nil
+}
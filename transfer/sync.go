@@ -0,0 +1,385 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SyncOptions are options for [Sync].
+type SyncOptions struct {
+	Compare         CompareMode
+	Algorithm       ChecksumAlgorithm
+	Delete          bool
+	DryRun          bool
+	Filter          *Filter
+	TransferOptions []TransferOption
+}
+
+// DefaultSyncOptions are the default options used in [Sync].
+func DefaultSyncOptions() SyncOptions {
+	return SyncOptions{
+		Compare:   ModTimeCompareMode,
+		Algorithm: Sha256ChecksumAlgorithm,
+		Delete:    false,
+		DryRun:    false,
+	}
+}
+
+// SyncOption are functional options for [Sync].
+type SyncOption func(o *SyncOptions)
+
+// WithCompare is used to specify the [CompareMode] used to detect changed files.
+func WithCompare(m CompareMode) SyncOption {
+	return func(o *SyncOptions) {
+		o.Compare = m
+	}
+}
+
+// WithCompareAlgorithm is used to specify the [ChecksumAlgorithm] used by the [ChecksumCompareMode].
+func WithCompareAlgorithm(a ChecksumAlgorithm) SyncOption {
+	return func(o *SyncOptions) {
+		o.Algorithm = a
+	}
+}
+
+// WithDelete is used to delete the destination files missing from the source.
+func WithDelete() SyncOption {
+	return func(o *SyncOptions) {
+		o.Delete = true
+	}
+}
+
+// WithDryRun is used to only plan the [SyncAction], without executing them.
+func WithDryRun() SyncOption {
+	return func(o *SyncOptions) {
+		o.DryRun = true
+	}
+}
+
+// WithSyncFilter is used to restrict the transfers to the source files selected by a [Filter], and the deletions to the
+// destination files selected by its name patterns.
+func WithSyncFilter(f *Filter) SyncOption {
+	return func(o *SyncOptions) {
+		o.Filter = f
+	}
+}
+
+// WithSyncTransferOptions is used to specify the [TransferOption] of the synchronization transfers.
+func WithSyncTransferOptions(options ...TransferOption) SyncOption {
+	return func(o *SyncOptions) {
+		o.TransferOptions = append(o.TransferOptions, options...)
+	}
+}
+
+// SyncAction is an action planned by [Sync], on a path relative to the synchronized directories.
+type SyncAction struct {
+	Kind   SyncActionKind
+	Path   string
+	Reason string
+}
+
+// SyncResult is the result of a [Sync].
+type SyncResult struct {
+	Direction   Direction
+	Source      string
+	Destination string
+	DryRun      bool
+	Actions     []SyncAction
+	Transfers   []*TransferResult
+	Unchanged   int
+}
+
+// Sync mirrors a source directory tree to a destination directory tree, one way: local to remote for the [UploadDirection],
+// remote to local for the [DownloadDirection].
+// Missing or changed files (according to the [CompareMode]) are transferred, and their modification time preserved,
+// extraneous destination files are deleted with the delete option, and nothing is changed with the dry run option.
+// Uploads to servers unable to set modification times are compared by size instead of modification time, and the
+// checksum sidecars of the source files and the temporary files of the atomic uploads are never deleted.
+// Source files left out by the filter age or size bounds are not transferred, and their destination files not deleted.
+// On failures, the [SyncResult] of the executed actions is returned with the joined errors.
+func Sync(ctx context.Context, client Client, direction Direction, source string, destination string, options ...SyncOption) (*SyncResult, error) {
+	appliedOpts := DefaultSyncOptions()
+	for _, opt := range options {
+		opt(&appliedOpts)
+	}
+
+	s := &syncer{
+		client:      client,
+		direction:   direction,
+		source:      source,
+		destination: destination,
+		options:     appliedOpts,
+	}
+
+	result := &SyncResult{
+		Direction:   direction,
+		Source:      source,
+		Destination: destination,
+		DryRun:      appliedOpts.DryRun,
+	}
+
+	sourceEntries, err := s.walk(ctx, source, direction == UploadDirection)
+	if err != nil {
+		return nil, err
+	}
+
+	destinationEntries, err := s.walk(ctx, destination, direction == DownloadDirection)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	destinationIndex := make(map[string]WalkEntry, len(destinationEntries))
+	for _, entry := range destinationEntries {
+		destinationIndex[entry.Path] = entry
+	}
+
+	// all the source files are indexed, the destination files of the filtered out ones are not extraneous
+	sourceIndex := make(map[string]bool, len(sourceEntries))
+	for _, entry := range sourceEntries {
+		sourceIndex[entry.Path] = true
+	}
+
+	if appliedOpts.Filter != nil {
+		sourceEntries = appliedOpts.Filter.Apply(sourceEntries)
+	}
+
+	for _, entry := range sourceEntries {
+		destinationEntry, ok := destinationIndex[entry.Path]
+		if !ok {
+			result.Actions = append(result.Actions, SyncAction{Kind: TransferSyncAction, Path: entry.Path, Reason: "missing"})
+
+			continue
+		}
+
+		reason, err := s.compare(ctx, entry, destinationEntry)
+		if err != nil {
+			return nil, err
+		}
+
+		if reason == "" {
+			result.Unchanged++
+		} else {
+			result.Actions = append(result.Actions, SyncAction{Kind: TransferSyncAction, Path: entry.Path, Reason: reason})
+		}
+	}
+
+	if appliedOpts.Delete {
+		for _, entry := range destinationEntries {
+			if !sourceIndex[entry.Path] && !s.companion(entry.Path, sourceIndex) && s.deletable(entry) {
+				result.Actions = append(result.Actions, SyncAction{Kind: DeleteSyncAction, Path: entry.Path, Reason: "extraneous"})
+			}
+		}
+	}
+
+	if appliedOpts.DryRun {
+		return result, nil
+	}
+
+	var errs []error
+	for _, action := range result.Actions {
+		if err = ctx.Err(); err != nil {
+			errs = append(errs, err)
+
+			break
+		}
+
+		switch action.Kind {
+		case TransferSyncAction:
+			transferResult, err := s.transfer(ctx, action.Path)
+			if err != nil {
+				errs = append(errs, err)
+			} else {
+				result.Transfers = append(result.Transfers, transferResult)
+			}
+		case DeleteSyncAction:
+			if err = s.delete(ctx, action.Path); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return result, errors.Join(errs...)
+}
+
+type syncer struct {
+	client      Client
+	direction   Direction
+	source      string
+	destination string
+	options     SyncOptions
+}
+
+func (s *syncer) walk(ctx context.Context, dir string, local bool) ([]WalkEntry, error) {
+	if local {
+		return WalkLocal(ctx, dir)
+	}
+
+	return s.client.Walk(ctx, dir)
+}
+
+// deletable returns true if an extraneous destination file is within the filter name patterns, its age and size
+// being irrelevant to the synchronization scope.
+func (s *syncer) deletable(entry WalkEntry) bool {
+	return s.options.Filter == nil || s.options.Filter.MatchName(entry.Info)
+}
+
+// companion returns true if a destination file is the checksum sidecar of a source file, or an atomic upload temporary file.
+func (s *syncer) companion(rel string, sourceIndex map[string]bool) bool {
+	for _, algorithm := range []ChecksumAlgorithm{Sha256ChecksumAlgorithm, Md5ChecksumAlgorithm} {
+		if base, ok := strings.CutSuffix(rel, "."+algorithm.String()); ok && sourceIndex[base] {
+			return true
+		}
+	}
+
+	transferOptions := DefaultTransferOptions()
+	for _, opt := range s.options.TransferOptions {
+		opt(&transferOptions)
+	}
+
+	for _, pattern := range []string{transferOptions.TemporaryPattern, DefaultTemporaryPattern} {
+		if matched, _ := path.Match(TemporaryPath(path.Join(path.Dir(rel), "*"), pattern), rel); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// compare returns the reason why a destination file differs from its source file, or an empty string if it does not.
+func (s *syncer) compare(ctx context.Context, source WalkEntry, destination WalkEntry) (string, error) {
+	if source.Info.Size() != destination.Info.Size() {
+		return "size", nil
+	}
+
+	switch s.options.Compare {
+	case ModTimeCompareMode:
+		// servers unable to set the uploads modification times are compared by size only
+		if s.direction == UploadDirection && !chtimesSupported(s.client) {
+			break
+		}
+
+		// SFTP servers only expose seconds
+		if !source.Info.ModTime().Truncate(time.Second).Equal(destination.Info.ModTime().Truncate(time.Second)) {
+			return "mtime", nil
+		}
+	case ChecksumCompareMode:
+		sourceDigest, err := s.digest(ctx, s.source, source.Path, s.direction == UploadDirection)
+		if err != nil {
+			return "", err
+		}
+
+		destinationDigest, err := s.digest(ctx, s.destination, destination.Path, s.direction == DownloadDirection)
+		if err != nil {
+			return "", err
+		}
+
+		if sourceDigest != destinationDigest {
+			return "checksum", nil
+		}
+	}
+
+	return "", nil
+}
+
+func (s *syncer) digest(ctx context.Context, root string, rel string, local bool) (string, error) {
+	var p string
+	var f io.ReadCloser
+	var err error
+
+	if local {
+		p = localJoin(root, rel)
+		f, err = os.Open(p)
+	} else {
+		p = path.Join(root, rel)
+		f, err = s.client.Open(ctx, p)
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("unable to open file [%s] for comparison: %w", p, err)
+	}
+	defer f.Close()
+
+	digest, err := Digest(NewContextReader(ctx, f), s.options.Algorithm)
+	if err != nil {
+		return "", fmt.Errorf("unable to hash file [%s] for comparison: %w", p, err)
+	}
+
+	return digest, nil
+}
+
+func (s *syncer) transfer(ctx context.Context, rel string) (*TransferResult, error) {
+	if s.direction == DownloadDirection {
+		remotePath, localPath := path.Join(s.source, rel), localJoin(s.destination, rel)
+
+		result, err := s.client.Download(ctx, remotePath, localPath, s.options.TransferOptions...)
+		if err != nil {
+			return nil, err
+		}
+
+		info, err := s.client.Stat(ctx, remotePath)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = os.Chtimes(localPath, info.ModTime(), info.ModTime()); err != nil {
+			return nil, fmt.Errorf("unable to change local file [%s] times: %w", localPath, err)
+		}
+
+		return result, nil
+	}
+
+	localPath, remotePath := localJoin(s.source, rel), path.Join(s.destination, rel)
+
+	result, err := s.client.Upload(ctx, localPath, remotePath, s.options.TransferOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to stat local file [%s]: %w", localPath, err)
+	}
+
+	// servers unable to set modification times are compared by size or checksum instead
+	if err = s.client.Chtimes(ctx, remotePath, info.ModTime()); err != nil && !errors.Is(err, errors.ErrUnsupported) {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *syncer) delete(ctx context.Context, rel string) error {
+	if s.direction == DownloadDirection {
+		localPath := localJoin(s.destination, rel)
+		if err := os.Remove(localPath); err != nil {
+			return fmt.Errorf("unable to remove local file [%s]: %w", localPath, err)
+		}
+
+		return nil
+	}
+
+	return s.client.Remove(ctx, path.Join(s.destination, rel))
+}
+
+// chtimesChecker is implemented by the [Client] which may not support [Client.Chtimes], to check it without changing any file.
+type chtimesChecker interface {
+	chtimesSupported() bool
+}
+
+func chtimesSupported(client Client) bool {
+	checker, ok := client.(chtimesChecker)
+
+	return !ok || checker.chtimesSupported()
+}
+
+func localJoin(root string, rel string) string {
+	return filepath.Join(root, filepath.FromSlash(rel))
+}
@@ -0,0 +1,108 @@
+package transfer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func writeTestFile(t *testing.T, path string, content string, modTime time.Time) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSyncFilter(t *testing.T) {
+	t.Parallel()
+
+	old := time.Now().Add(-24 * time.Hour).Truncate(time.Second)
+
+	tests := []struct {
+		name   string
+		filter []FilterOption
+		want   []SyncAction
+	}{
+		{
+			name:   "no filter",
+			filter: nil,
+			want: []SyncAction{
+				{Kind: TransferSyncAction, Path: "big.csv", Reason: "size"},
+				{Kind: TransferSyncAction, Path: "new.csv", Reason: "mtime"},
+				{Kind: TransferSyncAction, Path: "old.csv", Reason: "missing"},
+				{Kind: DeleteSyncAction, Path: "gone.csv", Reason: "extraneous"},
+				{Kind: DeleteSyncAction, Path: "keep.log", Reason: "extraneous"},
+			},
+		},
+		{
+			name:   "name patterns",
+			filter: []FilterOption{WithInclude("*.csv")},
+			want: []SyncAction{
+				{Kind: TransferSyncAction, Path: "big.csv", Reason: "size"},
+				{Kind: TransferSyncAction, Path: "new.csv", Reason: "mtime"},
+				{Kind: TransferSyncAction, Path: "old.csv", Reason: "missing"},
+				{Kind: DeleteSyncAction, Path: "gone.csv", Reason: "extraneous"},
+			},
+		},
+		{
+			name:   "age and size bounds",
+			filter: []FilterOption{WithInclude("*.csv"), WithMinAge(time.Hour), WithSizeBounds(0, 10)},
+			want: []SyncAction{
+				{Kind: TransferSyncAction, Path: "old.csv", Reason: "missing"},
+				{Kind: DeleteSyncAction, Path: "gone.csv", Reason: "extraneous"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			root := t.TempDir()
+			source := filepath.Join(root, "source")
+			remote := filepath.Join(root, "remote")
+
+			// source files: a new one, a grown one over the max size, and an old one missing from the destination
+			writeTestFile(t, filepath.Join(source, "new.csv"), "new", time.Now())
+			writeTestFile(t, filepath.Join(source, "big.csv"), "0123456789abcdef", old)
+			writeTestFile(t, filepath.Join(source, "old.csv"), "old", old)
+
+			// destination files: the previous copies of the source files, and extraneous ones
+			writeTestFile(t, filepath.Join(remote, "out", "new.csv"), "new", old)
+			writeTestFile(t, filepath.Join(remote, "out", "big.csv"), "01234", old)
+			writeTestFile(t, filepath.Join(remote, "out", "gone.csv"), "gone", old)
+			writeTestFile(t, filepath.Join(remote, "out", "keep.log"), "keep", old)
+
+			options := []SyncOption{WithDelete(), WithDryRun()}
+			if tt.filter != nil {
+				filter, err := NewFilter(tt.filter...)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				options = append(options, WithSyncFilter(filter))
+			}
+
+			result, err := Sync(context.Background(), NewLocalClient(remote), UploadDirection, source, "out", options...)
+			if err != nil {
+				t.Fatalf("Sync() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(result.Actions, tt.want) {
+				t.Errorf("Sync() actions = %+v, want %+v", result.Actions, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package transfer
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// TemporaryPath returns the temporary path of a file path, for a temporary name pattern.
+func TemporaryPath(filePath string, pattern string) string {
+	if pattern == "" {
+		pattern = DefaultTemporaryPattern
+	}
+
+	return path.Join(path.Dir(filePath), strings.ReplaceAll(pattern, "{name}", path.Base(filePath)))
+}
+
+// CleanupTemporaryFiles removes the stale temporary files left by interrupted atomic uploads into a directory,
+// which were not modified since the provided duration. It returns the list of removed paths.
+func CleanupTemporaryFiles(ctx context.Context, client Client, dir string, pattern string, staleAfter time.Duration) ([]string, error) {
+	temporaryGlob := TemporaryPath(path.Join(dir, "*"), pattern)
+	temporaryDir := path.Dir(temporaryGlob)
+
+	files, err := client.List(ctx, temporaryDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	for _, file := range files {
+		filePath := path.Join(temporaryDir, file.Name())
+
+		if matched, _ := path.Match(temporaryGlob, filePath); !matched {
+			continue
+		}
+
+		if time.Since(file.ModTime()) < staleAfter {
+			continue
+		}
+
+		if err = client.Remove(ctx, filePath); err != nil {
+			return removed, fmt.Errorf("unable to cleanup stale temporary file: %w", err)
+		}
+
+		removed = append(removed, filePath)
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,57 @@
+package transfer
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// NewTLSConfig returns the [tls.Config] of FTPS connections for provided [Options].
+// The TLS sessions are cached, since most servers require the data connections to resume the control connection session.
+func NewTLSConfig(o Options) (*tls.Config, error) {
+	if o.TLSConfig != nil {
+		config := o.TLSConfig.Clone()
+		if config.ClientSessionCache == nil {
+			config.ClientSessionCache = tls.NewLRUClientSessionCache(0)
+		}
+
+		return config, nil
+	}
+
+	config := &tls.Config{
+		ServerName:         o.TLSServerName,
+		InsecureSkipVerify: o.TLSInsecureSkipVerify,
+		MinVersion:         tls.VersionTLS12,
+		ClientSessionCache: tls.NewLRUClientSessionCache(0),
+	}
+
+	if config.ServerName == "" {
+		config.ServerName = o.Host
+	}
+
+	if o.TLSCAPath != "" {
+		pem, err := os.ReadFile(o.TLSCAPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read tls ca file [%s]: %w", o.TLSCAPath, err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in tls ca file [%s]", o.TLSCAPath)
+		}
+
+		config.RootCAs = pool
+	}
+
+	if o.TLSCertificatePath != "" || o.TLSKeyPath != "" {
+		certificate, err := tls.LoadX509KeyPair(o.TLSCertificatePath, o.TLSKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load tls client certificate [%s]: %w", o.TLSCertificatePath, err)
+		}
+
+		config.Certificates = []tls.Certificate{certificate}
+	}
+
+	return config, nil
+}
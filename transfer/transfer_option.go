@@ -0,0 +1,114 @@
+package transfer
+
+// DefaultTemporaryPattern is the default temporary file name pattern, used by atomic uploads.
+const DefaultTemporaryPattern = ".{name}.part"
+
+// TransferOptions are options for the [Client] upload and download operations.
+type TransferOptions struct {
+	Resume           bool
+	ResumeVerify     ResumeVerifyMode
+	Atomic           bool
+	TemporaryPattern string
+	Checksum         ChecksumAlgorithm
+	Verify           ChecksumVerifyMode
+	Sidecar          bool
+	StagingDir       string
+	UploadStages     []Stage
+	DownloadStages   []Stage
+}
+
+// DefaultTransferOptions are the default options used for the [Client] upload and download operations.
+func DefaultTransferOptions() TransferOptions {
+	return TransferOptions{
+		Resume:           false,
+		ResumeVerify:     HashResumeVerifyMode,
+		Atomic:           false,
+		TemporaryPattern: DefaultTemporaryPattern,
+		Checksum:         Sha256ChecksumAlgorithm,
+		Verify:           NoChecksumVerifyMode,
+		Sidecar:          false,
+	}
+}
+
+// TransferOption are functional options for the [Client] upload and download operations.
+type TransferOption func(o *TransferOptions)
+
+// WithResume is used to continue a transfer from an existing partial destination file,
+// once its prefix is verified with the provided [ResumeVerifyMode].
+// If the verification fails, the transfer restarts from byte zero.
+func WithResume(v ResumeVerifyMode) TransferOption {
+	return func(o *TransferOptions) {
+		o.Resume = true
+		o.ResumeVerify = v
+	}
+}
+
+// WithoutResume is used to always transfer from byte zero, truncating any existing destination file.
+func WithoutResume() TransferOption {
+	return func(o *TransferOptions) {
+		o.Resume = false
+	}
+}
+
+// WithAtomic is used to upload to a temporary name built from a pattern, then rename to the final name once verified,
+// so remote consumers never pick up half-written files.
+// The pattern {name} placeholder is replaced by the file name, and the pattern is resolved relatively to the
+// destination directory: ".{name}.part" writes next to the destination, "../staging/{name}" into a staging directory.
+// An empty pattern falls back to [DefaultTemporaryPattern].
+func WithAtomic(pattern string) TransferOption {
+	return func(o *TransferOptions) {
+		o.Atomic = true
+
+		if pattern != "" {
+			o.TemporaryPattern = pattern
+		}
+	}
+}
+
+// WithoutAtomic is used to upload directly to the final name.
+func WithoutAtomic() TransferOption {
+	return func(o *TransferOptions) {
+		o.Atomic = false
+	}
+}
+
+// WithChecksum is used to specify the [ChecksumAlgorithm] of the digest computed while streaming,
+// and the [ChecksumVerifyMode] used to verify it against the server.
+func WithChecksum(a ChecksumAlgorithm, v ChecksumVerifyMode) TransferOption {
+	return func(o *TransferOptions) {
+		o.Checksum = a
+		o.Verify = v
+	}
+}
+
+// WithSidecar is used to upload a checksum sidecar file (e.g. file.csv.sha256) alongside uploaded files,
+// and to require and compare it for downloaded files.
+func WithSidecar() TransferOption {
+	return func(o *TransferOptions) {
+		o.Sidecar = true
+	}
+}
+
+// WithStagingDir is used to specify the local directory the files copied between two [Client] are staged in,
+// the system temporary directory by default.
+func WithStagingDir(dir string) TransferOption {
+	return func(o *TransferOptions) {
+		o.StagingDir = dir
+	}
+}
+
+// WithUploadStages is used to process the local files through a list of [Stage] before uploading them,
+// like to encrypt and sign them.
+func WithUploadStages(stages ...Stage) TransferOption {
+	return func(o *TransferOptions) {
+		o.UploadStages = append(o.UploadStages, stages...)
+	}
+}
+
+// WithDownloadStages is used to process the downloaded files through a list of [Stage],
+// like to decrypt them and verify their signature.
+func WithDownloadStages(stages ...Stage) TransferOption {
+	return func(o *TransferOptions) {
+		o.DownloadStages = append(o.DownloadStages, stages...)
+	}
+}
@@ -0,0 +1,57 @@
+package transfer
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestApplyTransferOptionsConcurrently(t *testing.T) {
+	t.Parallel()
+
+	// client options with spare capacity, shared by all the sessions of a connection
+	shared := make([]TransferOption, 0, 4)
+	shared = append(shared, WithResume(HashResumeVerifyMode), WithAtomic(DefaultTemporaryPattern))
+
+	tests := []struct {
+		name  string
+		apply func(options ...TransferOption) TransferOptions
+	}{
+		{name: "sftp", apply: (&SftpClient{transferOptions: shared}).applyTransferOptions},
+		{name: "ftp", apply: (&FtpClient{transferOptions: shared}).applyTransferOptions},
+		{name: "local", apply: (&LocalClient{transferOptions: shared}).applyTransferOptions},
+		{name: "s3", apply: (&S3Client{transferOptions: shared}).applyTransferOptions},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var wg sync.WaitGroup
+			for i := 0; i < 50; i++ {
+				wg.Add(2)
+
+				go func() {
+					defer wg.Done()
+
+					if o := tt.apply(WithoutResume()); o.Resume || o.Sidecar || !o.Atomic {
+						t.Errorf("options without resume = %+v, overwritten by another call", o)
+					}
+				}()
+
+				go func() {
+					defer wg.Done()
+
+					if o := tt.apply(WithSidecar()); !o.Resume || !o.Sidecar || !o.Atomic {
+						t.Errorf("options with sidecar = %+v, overwritten by another call", o)
+					}
+				}()
+			}
+
+			wg.Wait()
+
+			if len(shared) != 2 {
+				t.Errorf("client options length = %d, want 2", len(shared))
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package transfer
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WalkLocal returns the files (directories excluded) of a local directory tree, with their paths relative to the directory.
+func WalkLocal(ctx context.Context, dir string) ([]WalkEntry, error) {
+	var entries []WalkEntry
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+
+		entries = append(entries, WalkEntry{
+			Path: filepath.ToSlash(rel),
+			Info: info,
+		})
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to walk local dir [%s]: %w", dir, err)
+	}
+
+	return entries, nil
+}
+
+// ListLocal returns the files (directories excluded) of a local directory, as [WalkEntry].
+func ListLocal(dir string) ([]WalkEntry, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list local dir [%s]: %w", dir, err)
+	}
+
+	var entries []WalkEntry
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() {
+			continue
+		}
+
+		info, err := dirEntry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("unable to stat local file [%s]: %w", dirEntry.Name(), err)
+		}
+
+		entries = append(entries, WalkEntry{
+			Path: info.Name(),
+			Info: info,
+		})
+	}
+
+	return entries, nil
+}
+
+// Entries converts a list of [fs.FileInfo] of a directory to [WalkEntry].
+func Entries(infos []fs.FileInfo) []WalkEntry {
+	entries := make([]WalkEntry, 0, len(infos))
+	for _, info := range infos {
+		entries = append(entries, WalkEntry{
+			Path: info.Name(),
+			Info: info,
+		})
+	}
+
+	return entries
+}
+
+func relativePath(root string, p string) string {
+	if root == "/" {
+		return strings.TrimPrefix(p, "/")
+	}
+
+	return strings.TrimPrefix(strings.TrimPrefix(p, root), "/")
+}